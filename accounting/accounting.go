@@ -0,0 +1,268 @@
+// Package accounting records the bytes this node serves to other
+// peers, per content hash and per peer, over fixed rolling periods.
+// Each completed period is signed with the node's private key and
+// persisted in the repo datastore, so bandwidth claims made for
+// network rewards can be audited and survive restarts.
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	ds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore"
+	dsquery "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore/query"
+	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	ic "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-crypto"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+)
+
+var log = logging.Logger("accounting")
+
+const (
+	// periodLength is the length of one accounting period.
+	periodLength = time.Hour
+
+	// maxPeriods is how many sealed periods are retained, one week at
+	// the default period length.
+	maxPeriods = 168
+
+	// periodPrefix is the datastore namespace holding sealed periods.
+	periodPrefix = "/accounting/period"
+)
+
+// Period aggregates the bytes served during one accounting window.
+type Period struct {
+	Peer  string // the serving node
+	Start time.Time
+	End   time.Time
+
+	BytesServed uint64
+	BlocksSent  uint64
+
+	// PeerBytes and CidBytes break BytesServed down by receiving peer
+	// and by served content hash.
+	PeerBytes map[string]uint64
+	CidBytes  map[string]uint64
+}
+
+// SignedPeriod is a sealed period together with the node's signature
+// over its JSON encoding.
+type SignedPeriod struct {
+	Period    Period
+	Signature []byte
+}
+
+// Proof is the exportable accounting record, emitted by
+// 'ipfs stats serve-proof'.
+type Proof struct {
+	Peer      string
+	PublicKey []byte // protobuf-encoded libp2p public key
+	Periods   []SignedPeriod
+}
+
+// Service accumulates serve records and seals them into signed
+// periods.
+type Service struct {
+	self peer.ID
+	key  ic.PrivKey
+	ds   ds.Datastore
+
+	mu      sync.Mutex
+	current *Period
+}
+
+// New creates an accounting service signing with the given node key.
+func New(self peer.ID, key ic.PrivKey, dstore ds.Datastore) *Service {
+	return &Service{
+		self: self,
+		key:  key,
+		ds:   dstore,
+	}
+}
+
+// RecordServe notes that size bytes of block c were sent to peer p.
+func (s *Service) RecordServe(p peer.ID, c cid.Cid, size int) {
+	if size <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rollLocked(time.Now())
+	s.current.BytesServed += uint64(size)
+	s.current.BlocksSent++
+	s.current.PeerBytes[p.Pretty()] += uint64(size)
+	s.current.CidBytes[c.String()] += uint64(size)
+}
+
+// Run seals idle periods until the context is cancelled. Without it,
+// periods would only roll over when traffic is recorded.
+func (s *Service) Run(ctx context.Context) {
+	tick := time.NewTicker(periodLength / 4)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			s.mu.Lock()
+			s.rollLocked(time.Now())
+			s.mu.Unlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// rollLocked seals the current period if it has elapsed and starts a
+// new one. The caller must hold s.mu.
+func (s *Service) rollLocked(now time.Time) {
+	if s.current != nil && now.Before(s.current.End) {
+		return
+	}
+
+	if s.current != nil && s.current.BytesServed > 0 {
+		if err := s.seal(s.current); err != nil {
+			log.Errorf("sealing accounting period: %s", err)
+		}
+	}
+
+	start := now.Truncate(periodLength)
+	s.current = &Period{
+		Peer:      s.self.Pretty(),
+		Start:     start,
+		End:       start.Add(periodLength),
+		PeerBytes: make(map[string]uint64),
+		CidBytes:  make(map[string]uint64),
+	}
+}
+
+// seal signs a completed period and persists it.
+func (s *Service) seal(p *Period) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	sig, err := s.key.Sign(data)
+	if err != nil {
+		return err
+	}
+
+	rec, err := json.Marshal(&SignedPeriod{Period: *p, Signature: sig})
+	if err != nil {
+		return err
+	}
+	if err := s.ds.Put(periodKey(p.Start), rec); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// prune removes the oldest sealed periods beyond the retention limit.
+func (s *Service) prune() error {
+	res, err := s.ds.Query(dsquery.Query{Prefix: periodPrefix, KeysOnly: true})
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	var keys []string
+	for e := range res.Next() {
+		if e.Error != nil {
+			return e.Error
+		}
+		keys = append(keys, e.Key)
+	}
+	if len(keys) <= maxPeriods {
+		return nil
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys[:len(keys)-maxPeriods] {
+		if err := s.ds.Delete(ds.NewKey(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Proof exports the retained signed periods, oldest first. The
+// current, unsealed period is not included.
+func (s *Service) Proof() (*Proof, error) {
+	pk, err := ic.MarshalPublicKey(s.key.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+	proof := &Proof{
+		Peer:      s.self.Pretty(),
+		PublicKey: pk,
+	}
+
+	res, err := s.ds.Query(dsquery.Query{Prefix: periodPrefix})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	type rawRec struct {
+		key   string
+		value []byte
+	}
+	var recs []rawRec
+	for e := range res.Next() {
+		if e.Error != nil {
+			return nil, e.Error
+		}
+		recs = append(recs, rawRec{e.Key, e.Value})
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].key < recs[j].key })
+
+	for _, r := range recs {
+		var sp SignedPeriod
+		if err := json.Unmarshal(r.value, &sp); err != nil {
+			return nil, fmt.Errorf("corrupt accounting record %s: %s", r.key, err)
+		}
+		proof.Periods = append(proof.Periods, sp)
+	}
+	return proof, nil
+}
+
+// Verify checks every period signature in a proof against its public
+// key and confirms the key matches the claimed peer identity.
+func Verify(p *Proof) error {
+	pk, err := ic.UnmarshalPublicKey(p.PublicKey)
+	if err != nil {
+		return err
+	}
+	pid, err := peer.IDFromPublicKey(pk)
+	if err != nil {
+		return err
+	}
+	if pid.Pretty() != p.Peer {
+		return fmt.Errorf("public key does not match peer %s", p.Peer)
+	}
+
+	for i, sp := range p.Periods {
+		data, err := json.Marshal(&sp.Period)
+		if err != nil {
+			return err
+		}
+		ok, err := pk.Verify(data, sp.Signature)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("invalid signature on period %d (%s)", i, sp.Period.Start.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+func periodKey(start time.Time) ds.Key {
+	return ds.NewKey(fmt.Sprintf("%s/%016x", periodPrefix, start.Unix()))
+}
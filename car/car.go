@@ -0,0 +1,295 @@
+// Package car reads and writes CAR (Content Addressable aRchive)
+// files. A CARv1 stream is a CBOR header naming the root cids,
+// followed by varint-delimited sections each holding a cid and its
+// block data. A CARv2 file wraps a CARv1 payload with a fixed-size
+// header and an index mapping block digests to payload offsets, so
+// single blocks can be read without scanning the whole file. The
+// package is used to import prepared dags pushed to the API or the
+// writable gateway in one request instead of block by block, and to
+// export whole dags for offline transfer.
+package car
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	blocks "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-block-format"
+	bserv "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-blockservice"
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	cbornode "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-cbor"
+	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
+)
+
+func init() {
+	cbornode.RegisterCborType(Header{})
+}
+
+// maxSectionSize bounds a single section (cid plus block data); it
+// leaves generous headroom over the bitswap block size limit.
+const maxSectionSize = 2 << 20
+
+// importBatchSize is how many decoded blocks are buffered before
+// they are handed to the blockservice in one batch.
+const importBatchSize = 128
+
+// Header is the first (CBOR encoded) section of a car stream.
+type Header struct {
+	Roots   []cid.Cid `refmt:"roots"`
+	Version uint64    `refmt:"version"`
+}
+
+// Reader decodes a car stream section by section.
+type Reader struct {
+	br     *bufio.Reader
+	Header *Header
+}
+
+// NewReader reads and validates the car header, leaving the reader
+// positioned at the first block section. A carv2 pragma is unwrapped
+// transparently down to the inner carv1 payload.
+func NewReader(r io.Reader) (*Reader, error) {
+	br, h, _, err := readHeader(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{br: br, Header: h}, nil
+}
+
+// readHeader reads the car header, unwrapping a carv2 pragma and its
+// fixed header down to the inner carv1 payload. It returns the reader
+// to continue with, the carv1 header, and the offset of the first
+// block section relative to the start of the payload.
+func readHeader(br *bufio.Reader) (*bufio.Reader, *Header, uint64, error) {
+	data, err := readSection(br)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("reading car header: %s", err)
+	}
+
+	var h Header
+	if err := cbornode.DecodeInto(data, &h); err != nil {
+		return nil, nil, 0, fmt.Errorf("invalid car header: %s", err)
+	}
+
+	switch h.Version {
+	case 1:
+		if len(h.Roots) == 0 {
+			return nil, nil, 0, fmt.Errorf("car header lists no roots")
+		}
+		return br, &h, uvarintLen(uint64(len(data))) + uint64(len(data)), nil
+
+	case 2:
+		inner, err := readHeaderV2(br, uvarintLen(uint64(len(data)))+uint64(len(data)))
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		return readHeader(inner)
+
+	default:
+		return nil, nil, 0, fmt.Errorf("unsupported car version: %d", h.Version)
+	}
+}
+
+// Next returns the next block in the stream, verifying that the data
+// matches the cid it is filed under. It returns io.EOF once the
+// stream ends.
+func (cr *Reader) Next() (blocks.Block, error) {
+	data, err := readSection(cr.br)
+	if err != nil {
+		return nil, err
+	}
+
+	n, c, err := readCid(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cid in car section: %s", err)
+	}
+
+	// the stream is untrusted input: check the data actually hashes
+	// to the claimed cid before anything is stored
+	hashed, err := c.Prefix().Sum(data[n:])
+	if err != nil {
+		return nil, err
+	}
+	if !hashed.Equals(c) {
+		return nil, fmt.Errorf("car section data does not hash to its cid %s", c)
+	}
+
+	return blocks.NewBlockWithCid(data[n:], c)
+}
+
+// Import reads a complete car stream and adds every block to the
+// blockservice, returning the roots named by the header. Blocks are
+// added in batches; a malformed section aborts the import (blocks of
+// earlier batches stay in the blockstore, where gc can reclaim them
+// if the roots never get pinned).
+func Import(bs bserv.BlockService, r io.Reader) ([]cid.Cid, error) {
+	cr, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := make([]blocks.Block, 0, importBatchSize)
+	for {
+		blk, err := cr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		batch = append(batch, blk)
+		if len(batch) >= importBatchSize {
+			if err := bs.AddBlocks(batch); err != nil {
+				return nil, err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		if err := bs.AddBlocks(batch); err != nil {
+			return nil, err
+		}
+	}
+
+	return cr.Header.Roots, nil
+}
+
+// Export writes the complete dag rooted at the given cids to w as a
+// car stream. Every reachable block is written exactly once, in depth
+// first order starting from the first root.
+func Export(ctx context.Context, ng ipld.NodeGetter, roots []cid.Cid, w io.Writer) error {
+	return export(ctx, ng, roots, w, nil)
+}
+
+// export is Export with an optional callback reporting the payload
+// offset of every block section as it is written; it feeds the carv2
+// index.
+func export(ctx context.Context, ng ipld.NodeGetter, roots []cid.Cid, w io.Writer, record func(cid.Cid, uint64) error) error {
+	if len(roots) == 0 {
+		return fmt.Errorf("car export needs at least one root")
+	}
+
+	hdr, err := cbornode.DumpObject(&Header{Roots: roots, Version: 1})
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := writeSection(bw, hdr); err != nil {
+		return err
+	}
+	off := uvarintLen(uint64(len(hdr))) + uint64(len(hdr))
+
+	seen := cid.NewSet()
+	var walk func(c cid.Cid) error
+	walk = func(c cid.Cid) error {
+		if !seen.Visit(c) {
+			return nil
+		}
+
+		nd, err := ng.Get(ctx, c)
+		if err != nil {
+			return fmt.Errorf("getting block %s: %s", c, err)
+		}
+		if record != nil {
+			if err := record(c, off); err != nil {
+				return err
+			}
+		}
+		sec := append(c.Bytes(), nd.RawData()...)
+		if err := writeSection(bw, sec); err != nil {
+			return err
+		}
+		off += uvarintLen(uint64(len(sec))) + uint64(len(sec))
+
+		for _, l := range nd.Links() {
+			if err := walk(l.Cid); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, r := range roots {
+		if err := walk(r); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// readSection reads one varint-delimited section of the stream.
+func readSection(br *bufio.Reader) ([]byte, error) {
+	l, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	if l == 0 {
+		return nil, fmt.Errorf("malformed car: zero-length section")
+	}
+	if l > maxSectionSize {
+		return nil, fmt.Errorf("malformed car: section of %d bytes exceeds limit", l)
+	}
+
+	buf := make([]byte, l)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// uvarintLen returns the encoded size of n as an unsigned varint.
+func uvarintLen(n uint64) uint64 {
+	var buf [binary.MaxVarintLen64]byte
+	return uint64(binary.PutUvarint(buf[:], n))
+}
+
+// writeSection writes one varint-delimited section of the stream.
+func writeSection(w io.Writer, data []byte) error {
+	var lbuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lbuf[:], uint64(len(data)))
+	if _, err := w.Write(lbuf[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// readCid splits the cid off the front of a block section, returning
+// its length in bytes along with the parsed cid.
+func readCid(data []byte) (int, cid.Cid, error) {
+	// cidv0 is a bare sha2-256 multihash
+	if len(data) >= 34 && data[0] == 0x12 && data[1] == 0x20 {
+		c, err := cid.Cast(data[:34])
+		return 34, c, err
+	}
+
+	// cidv1: version, codec, then a multihash of <code><length><digest>
+	br := bytes.NewReader(data)
+	for i := 0; i < 3; i++ { // version, codec, hash code
+		if _, err := binary.ReadUvarint(br); err != nil {
+			return 0, cid.Undef, err
+		}
+	}
+	hashLen, err := binary.ReadUvarint(br)
+	if err != nil {
+		return 0, cid.Undef, err
+	}
+
+	end := len(data) - br.Len() + int(hashLen)
+	if end > len(data) {
+		return 0, cid.Undef, fmt.Errorf("cid length exceeds section")
+	}
+
+	c, err := cid.Cast(data[:end])
+	return end, c, err
+}
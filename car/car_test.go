@@ -0,0 +1,267 @@
+package car
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+
+	blocks "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-block-format"
+	bserv "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-blockservice"
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	ds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore"
+	dssync "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore/sync"
+	blockstore "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-blockstore"
+	offline "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-exchange-offline"
+	cbornode "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-cbor"
+	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
+	merkledag "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-merkledag"
+)
+
+func testCar(t *testing.T, blks []blocks.Block, roots []cid.Cid) *bytes.Buffer {
+	t.Helper()
+	hdr, err := cbornode.DumpObject(&Header{Roots: roots, Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	writeSection(buf, hdr)
+	for _, blk := range blks {
+		var section bytes.Buffer
+		section.Write(blk.Cid().Bytes())
+		section.Write(blk.RawData())
+		writeSection(buf, section.Bytes())
+	}
+	return buf
+}
+
+func testBlockService() bserv.BlockService {
+	bs := blockstore.NewBlockstore(dssync.MutexWrap(ds.NewMapDatastore()))
+	return bserv.New(bs, offline.Exchange(bs))
+}
+
+func TestImport(t *testing.T) {
+	blks := []blocks.Block{
+		blocks.NewBlock([]byte("block a")),
+		blocks.NewBlock([]byte("block b")),
+	}
+	buf := testCar(t, blks, []cid.Cid{blks[0].Cid()})
+
+	bs := testBlockService()
+	roots, err := Import(bs, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 1 || !roots[0].Equals(blks[0].Cid()) {
+		t.Fatalf("unexpected roots: %v", roots)
+	}
+
+	for _, blk := range blks {
+		got, err := bs.GetBlock(context.Background(), blk.Cid())
+		if err != nil {
+			t.Fatalf("block %s not imported: %s", blk.Cid(), err)
+		}
+		if !bytes.Equal(got.RawData(), blk.RawData()) {
+			t.Fatalf("block %s corrupted on import", blk.Cid())
+		}
+	}
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	src := testBlockService()
+	srcDag := merkledag.NewDAGService(src)
+
+	leafA := merkledag.NewRawNode([]byte("leaf a"))
+	leafB := merkledag.NewRawNode([]byte("leaf b"))
+	root := merkledag.NodeWithData([]byte("root"))
+	if err := root.AddNodeLink("a", leafA); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.AddNodeLink("b", leafB); err != nil {
+		t.Fatal(err)
+	}
+	for _, nd := range []ipld.Node{leafA, leafB, root} {
+		if err := srcDag.Add(ctx, nd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := Export(ctx, srcDag, []cid.Cid{root.Cid()}, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := testBlockService()
+	roots, err := Import(dst, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 1 || !roots[0].Equals(root.Cid()) {
+		t.Fatalf("unexpected roots: %v", roots)
+	}
+
+	for _, nd := range []ipld.Node{leafA, leafB, root} {
+		got, err := dst.GetBlock(ctx, nd.Cid())
+		if err != nil {
+			t.Fatalf("block %s not exported: %s", nd.Cid(), err)
+		}
+		if !bytes.Equal(got.RawData(), nd.RawData()) {
+			t.Fatalf("block %s corrupted in transit", nd.Cid())
+		}
+	}
+}
+
+func TestExportRejectsMissingBlock(t *testing.T) {
+	blk := blocks.NewBlock([]byte("never stored"))
+	srcDag := merkledag.NewDAGService(testBlockService())
+
+	err := Export(context.Background(), srcDag, []cid.Cid{blk.Cid()}, new(bytes.Buffer))
+	if err == nil {
+		t.Fatal("expected export of a missing block to fail")
+	}
+}
+
+func TestImportRejectsBadHash(t *testing.T) {
+	blk := blocks.NewBlock([]byte("good data"))
+
+	hdr, err := cbornode.DumpObject(&Header{Roots: []cid.Cid{blk.Cid()}, Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	writeSection(buf, hdr)
+	var section bytes.Buffer
+	section.Write(blk.Cid().Bytes())
+	section.Write([]byte("tampered data"))
+	writeSection(buf, section.Bytes())
+
+	if _, err := Import(testBlockService(), buf); err == nil {
+		t.Fatal("expected import of tampered block to fail")
+	}
+}
+
+func TestExportV2RoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	src := testBlockService()
+	srcDag := merkledag.NewDAGService(src)
+
+	leafA := merkledag.NewRawNode([]byte("leaf a"))
+	leafB := merkledag.NewRawNode([]byte("leaf b"))
+	root := merkledag.NodeWithData([]byte("root"))
+	if err := root.AddNodeLink("a", leafA); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.AddNodeLink("b", leafB); err != nil {
+		t.Fatal(err)
+	}
+	for _, nd := range []ipld.Node{leafA, leafB, root} {
+		if err := srcDag.Add(ctx, nd); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := ExportV2(ctx, srcDag, []cid.Cid{root.Cid()}, buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	dst := testBlockService()
+	roots, err := Import(dst, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 1 || !roots[0].Equals(root.Cid()) {
+		t.Fatalf("unexpected roots: %v", roots)
+	}
+	for _, nd := range []ipld.Node{leafA, leafB, root} {
+		if _, err := dst.GetBlock(ctx, nd.Cid()); err != nil {
+			t.Fatalf("block %s not exported: %s", nd.Cid(), err)
+		}
+	}
+
+	// the embedded index must locate every block within the payload
+	if !bytes.HasPrefix(data, pragmaV2) {
+		t.Fatal("carv2 output does not start with the pragma")
+	}
+	dataOff := binary.LittleEndian.Uint64(data[27:35])
+	dataSize := binary.LittleEndian.Uint64(data[35:43])
+	idxOff := binary.LittleEndian.Uint64(data[43:51])
+
+	ix, err := ReadIndex(bytes.NewReader(data[idxOff:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := data[dataOff : dataOff+dataSize]
+	for _, nd := range []ipld.Node{leafA, leafB, root} {
+		off, ok := ix.Lookup(nd.Cid())
+		if !ok {
+			t.Fatalf("block %s missing from the index", nd.Cid())
+		}
+		sec, err := readSection(bufio.NewReader(bytes.NewReader(payload[off:])))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sec, append(nd.Cid().Bytes(), nd.RawData()...)) {
+			t.Fatalf("index points %s at the wrong section", nd.Cid())
+		}
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+	blks := []blocks.Block{
+		blocks.NewBlock([]byte("block a")),
+		blocks.NewBlock([]byte("block b")),
+	}
+	buf := testCar(t, blks, []cid.Cid{blks[0].Cid()})
+	data := buf.Bytes()
+
+	idx, err := BuildIndex(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ix, err := ReadIndex(bytes.NewReader(idx))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, blk := range blks {
+		off, ok := ix.Lookup(blk.Cid())
+		if !ok {
+			t.Fatalf("block %s missing from the index", blk.Cid())
+		}
+		sec, err := readSection(bufio.NewReader(bytes.NewReader(data[off:])))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sec, append(blk.Cid().Bytes(), blk.RawData()...)) {
+			t.Fatalf("index points %s at the wrong section", blk.Cid())
+		}
+	}
+
+	if _, ok := ix.Lookup(blocks.NewBlock([]byte("absent")).Cid()); ok {
+		t.Fatal("lookup of an absent block succeeded")
+	}
+}
+
+func TestImportRejectsBadHeader(t *testing.T) {
+	for name, hdr := range map[string]*Header{
+		"no roots":    {Version: 1},
+		"bad version": {Roots: []cid.Cid{blocks.NewBlock([]byte("x")).Cid()}, Version: 3},
+	} {
+		data, err := cbornode.DumpObject(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := new(bytes.Buffer)
+		writeSection(buf, data)
+		if _, err := Import(testBlockService(), buf); err == nil {
+			t.Fatalf("expected import with %s header to fail", name)
+		}
+	}
+}
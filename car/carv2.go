@@ -0,0 +1,262 @@
+package car
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
+	mh "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multihash"
+)
+
+// pragmaV2 is the fixed first section of every carv2 file: a CBOR
+// header reading {"version": 2}, with the usual varint length prefix.
+var pragmaV2 = []byte{0x0a, 0xa1, 0x67, 'v', 'e', 'r', 's', 'i', 'o', 'n', 0x02}
+
+// headerV2Size is the size of the fixed carv2 header that follows the
+// pragma: a 128 bit characteristics bitfield and three 64 bit offsets.
+const headerV2Size = 40
+
+// codecIndexSorted is the multicodec code of the sorted index format
+// written after the payload and by BuildIndex.
+const codecIndexSorted = 0x0400
+
+// HeaderV2 is the fixed-size second section of a carv2 file, locating
+// the inner carv1 payload and the index within the file.
+type HeaderV2 struct {
+	Characteristics [16]byte
+	DataOffset      uint64
+	DataSize        uint64
+	IndexOffset     uint64
+}
+
+// readHeaderV2 reads the fixed carv2 header and positions br at the
+// start of the inner carv1 payload, which is returned bounded to the
+// payload size. consumed is how many bytes of the file were read
+// before the fixed header (i.e. the pragma).
+func readHeaderV2(br *bufio.Reader, consumed uint64) (*bufio.Reader, error) {
+	buf := make([]byte, headerV2Size)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("reading carv2 header: %s", err)
+	}
+
+	var h HeaderV2
+	copy(h.Characteristics[:], buf[:16])
+	h.DataOffset = binary.LittleEndian.Uint64(buf[16:24])
+	h.DataSize = binary.LittleEndian.Uint64(buf[24:32])
+	h.IndexOffset = binary.LittleEndian.Uint64(buf[32:40])
+
+	consumed += headerV2Size
+	if h.DataOffset < consumed {
+		return nil, fmt.Errorf("malformed carv2: data offset %d overlaps the header", h.DataOffset)
+	}
+	if h.DataSize == 0 {
+		return nil, fmt.Errorf("malformed carv2: zero data size")
+	}
+
+	if _, err := io.CopyN(ioutil.Discard, br, int64(h.DataOffset-consumed)); err != nil {
+		return nil, fmt.Errorf("seeking carv2 payload: %s", err)
+	}
+
+	return bufio.NewReader(io.LimitReader(br, int64(h.DataSize))), nil
+}
+
+// marshal serializes the fixed carv2 header.
+func (h *HeaderV2) marshal() []byte {
+	buf := make([]byte, headerV2Size)
+	copy(buf[:16], h.Characteristics[:])
+	binary.LittleEndian.PutUint64(buf[16:24], h.DataOffset)
+	binary.LittleEndian.PutUint64(buf[24:32], h.DataSize)
+	binary.LittleEndian.PutUint64(buf[32:40], h.IndexOffset)
+	return buf
+}
+
+// ExportV2 writes the complete dag rooted at the given cids to w as a
+// carv2 file with an embedded index. The fixed header names the
+// payload and index sizes up front, so the carv1 payload is staged in
+// memory before anything is written.
+func ExportV2(ctx context.Context, ng ipld.NodeGetter, roots []cid.Cid, w io.Writer) error {
+	var payload bytes.Buffer
+	var entries []indexEntry
+	record := func(c cid.Cid, off uint64) error {
+		dec, err := mh.Decode(c.Hash())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, indexEntry{digest: dec.Digest, offset: off})
+		return nil
+	}
+	if err := export(ctx, ng, roots, &payload, record); err != nil {
+		return err
+	}
+
+	h := HeaderV2{
+		DataOffset:  uint64(len(pragmaV2)) + headerV2Size,
+		DataSize:    uint64(payload.Len()),
+		IndexOffset: uint64(len(pragmaV2)) + headerV2Size + uint64(payload.Len()),
+	}
+
+	if _, err := w.Write(pragmaV2); err != nil {
+		return err
+	}
+	if _, err := w.Write(h.marshal()); err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, &payload); err != nil {
+		return err
+	}
+	_, err := w.Write(marshalIndex(entries))
+	return err
+}
+
+// indexEntry locates one block section: the digest of its cid's
+// multihash and the section offset relative to the payload start.
+type indexEntry struct {
+	digest []byte
+	offset uint64
+}
+
+// marshalIndex serializes entries in the sorted index format: the
+// codec as a varint, then one bucket per digest width holding a
+// uint32 width (digest plus offset), a uint64 byte length, and the
+// entries sorted by digest, each the digest followed by the offset as
+// a uint64, all little endian.
+func marshalIndex(entries []indexEntry) []byte {
+	buckets := make(map[int][]indexEntry)
+	for _, e := range entries {
+		buckets[len(e.digest)] = append(buckets[len(e.digest)], e)
+	}
+
+	widths := make([]int, 0, len(buckets))
+	for w := range buckets {
+		widths = append(widths, w)
+	}
+	sort.Ints(widths)
+
+	var out bytes.Buffer
+	var lbuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lbuf[:], codecIndexSorted)
+	out.Write(lbuf[:n])
+
+	var word [8]byte
+	for _, wdt := range widths {
+		es := buckets[wdt]
+		sort.Slice(es, func(i, j int) bool {
+			return bytes.Compare(es[i].digest, es[j].digest) < 0
+		})
+
+		binary.LittleEndian.PutUint32(word[:4], uint32(wdt+8))
+		out.Write(word[:4])
+		binary.LittleEndian.PutUint64(word[:], uint64(len(es)*(wdt+8)))
+		out.Write(word[:])
+		for _, e := range es {
+			out.Write(e.digest)
+			binary.LittleEndian.PutUint64(word[:], e.offset)
+			out.Write(word[:])
+		}
+	}
+	return out.Bytes()
+}
+
+// BuildIndex scans a car stream (either version) and returns a
+// serialized index of it, mapping every block to the offset of its
+// section within the carv1 payload. The detached index allows random
+// access into a large car file without scanning it.
+func BuildIndex(r io.Reader) ([]byte, error) {
+	br, _, off, err := readHeader(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []indexEntry
+	for {
+		data, err := readSection(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		_, c, err := readCid(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cid in car section: %s", err)
+		}
+		dec, err := mh.Decode(c.Hash())
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, indexEntry{digest: dec.Digest, offset: off})
+		off += uvarintLen(uint64(len(data))) + uint64(len(data))
+	}
+
+	return marshalIndex(entries), nil
+}
+
+// Index maps block multihash digests to section offsets within a
+// carv1 payload, letting single blocks be read without a scan.
+type Index struct {
+	offsets map[string]uint64
+}
+
+// Lookup returns the payload offset of the section holding c's block.
+func (ix *Index) Lookup(c cid.Cid) (uint64, bool) {
+	dec, err := mh.Decode(c.Hash())
+	if err != nil {
+		return 0, false
+	}
+	off, ok := ix.offsets[string(dec.Digest)]
+	return off, ok
+}
+
+// ReadIndex parses a serialized index as produced by BuildIndex or
+// embedded in a carv2 file.
+func ReadIndex(r io.Reader) (*Index, error) {
+	br := bufio.NewReader(r)
+	codec, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("reading index codec: %s", err)
+	}
+	if codec != codecIndexSorted {
+		return nil, fmt.Errorf("unsupported index format: %#x", codec)
+	}
+
+	ix := &Index{offsets: make(map[string]uint64)}
+	for {
+		var head [12]byte
+		if _, err := io.ReadFull(br, head[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("reading index bucket: %s", err)
+		}
+
+		width := binary.LittleEndian.Uint32(head[:4])
+		length := binary.LittleEndian.Uint64(head[4:])
+		if width <= 8 || length%uint64(width) != 0 {
+			return nil, fmt.Errorf("malformed index bucket: width %d, length %d", width, length)
+		}
+
+		buf := make([]byte, width)
+		for read := uint64(0); read < length; read += uint64(width) {
+			if _, err := io.ReadFull(br, buf); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return nil, fmt.Errorf("reading index entry: %s", err)
+			}
+			ix.offsets[string(buf[:width-8])] = binary.LittleEndian.Uint64(buf[width-8:])
+		}
+	}
+
+	return ix, nil
+}
@@ -1,33 +1,48 @@
 package main
 
 import (
+	"crypto/tls"
 	"errors"
+	"io"
 	_ "expvar"
 	"fmt"
 	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"runtime"
 	"sort"
 	"sync"
+	"syscall"
+	"time"
 
 	version "github.com/ipsn/go-ipfs"
+	accounting "github.com/ipsn/go-ipfs/accounting"
 	utilmain "github.com/ipsn/go-ipfs/cmd/ipfs/util"
 	oldcmds "github.com/ipsn/go-ipfs/commands"
 	"github.com/ipsn/go-ipfs/core"
 	commands "github.com/ipsn/go-ipfs/core/commands"
 	corehttp "github.com/ipsn/go-ipfs/core/corehttp"
 	corerepo "github.com/ipsn/go-ipfs/core/corerepo"
+	ethofs "github.com/ipsn/go-ipfs/ethofs"
 	nodeMount "github.com/ipsn/go-ipfs/fuse/node"
+	bitswap "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-bitswap"
+	blocks "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-block-format"
 	fsrepo "github.com/ipsn/go-ipfs/repo/fsrepo"
 	migrate "github.com/ipsn/go-ipfs/repo/fsrepo/migrations"
+	"github.com/ipsn/go-ipfs/plugin/events"
+	rotatelog "github.com/ipsn/go-ipfs/thirdparty/rotatelog"
+	tracing "github.com/ipsn/go-ipfs/tracing"
 
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr-net"
 	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
 	"github.com/prometheus/client_golang/prometheus"
 	mprome "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-metrics-prometheus"
 	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
 )
 
@@ -277,11 +292,42 @@ func daemonFunc(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment
 		break
 	}
 
+	// merge IPFS_CONFIG_* environment overrides over the config file
+	// for this daemon run; nothing is written back to disk
+	rcfg, err := repo.Config()
+	if err != nil {
+		return err
+	}
+	overrides, err := fsrepo.ApplyEnvConfigOverrides(rcfg)
+	if err != nil {
+		return err
+	}
+	for _, path := range sortedOverridePaths(overrides) {
+		fmt.Printf("Applying config override from environment: %s\n", path)
+	}
+	// the command context loads its config copy straight from disk, so
+	// have it apply the same overrides
+	cctx.LoadConfig = func(path string) (*config.Config, error) {
+		cfg, err := fsrepo.ConfigAt(path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fsrepo.ApplyEnvConfigOverrides(cfg); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+
 	cfg, err := cctx.GetConfig()
 	if err != nil {
 		return err
 	}
 
+	// apply persisted logging configuration
+	if err := setupLogging(cfg.Logging); err != nil {
+		return err
+	}
+
 	offline, _ := req.Options[offlineKwd].(bool)
 	ipnsps, _ := req.Options[enableIPNSPubSubKwd].(bool)
 	pubsub, _ := req.Options[enablePubSubKwd].(bool)
@@ -326,6 +372,11 @@ func daemonFunc(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment
 		return fmt.Errorf("unrecognized routing option: %s", routingOption)
 	}
 
+	// set up trace export before any spans are created
+	if err := tracing.Setup(cfg.Tracing); err != nil {
+		return err
+	}
+
 	node, err := core.NewNode(req.Context, ncfg)
 	if err != nil {
 		log.Error("error from node construction: ", err)
@@ -340,6 +391,51 @@ func daemonFunc(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment
 
 	printSwarmAddrs(node)
 
+	// re-establish persisted p2p forwards and listeners
+	if cfg.Experimental.Libp2pStreamMounting && node.OnlineMode() {
+		node.P2P.SetupPersisted(node.Context(), &cfg.P2P)
+	}
+
+	// start hosting contract pin synchronization
+	if cfg.EthoFS.Enabled && node.OnlineMode() {
+		svc, err := ethofs.New(cfg.EthoFS, node.Pinning, node.DAG, node.Repo.Datastore())
+		if err != nil {
+			return err
+		}
+		node.EthoFS = svc
+		go svc.Run(node.Context())
+	}
+
+	// record served bytes in signed accounting periods
+	if bs, ok := node.Exchange.(*bitswap.Bitswap); ok && node.OnlineMode() {
+		acct := accounting.New(node.Identity, node.PrivateKey, node.Repo.Datastore())
+		bs.SetBlockSentListener(func(p peer.ID, blk blocks.Block) {
+			acct.RecordServe(p, blk.Cid(), len(blk.RawData()))
+		})
+		node.Accounting = acct
+		go acct.Run(node.Context())
+	}
+
+	// hand the running node to daemon plugins and mount any commands
+	// they provide before the API starts serving
+	if cctx.Plugins != nil {
+		if err := cctx.Plugins.Start(node); err != nil {
+			return err
+		}
+		defer cctx.Plugins.Close()
+
+		pluginCmds, err := cctx.Plugins.Commands()
+		if err != nil {
+			return err
+		}
+		for name, cmd := range pluginCmds {
+			if _, ok := commands.Root.Subcommands[name]; ok {
+				return fmt.Errorf("plugin command %q conflicts with a built-in command", name)
+			}
+			commands.Root.Subcommands[name] = cmd
+		}
+	}
+
 	defer func() {
 		// We wait for the node to close first, as the node has children
 		// that it will wait for before closing, such as the API server.
@@ -356,6 +452,27 @@ func daemonFunc(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment
 		return node, nil
 	}
 
+	// reload the hot-reloadable parts of the config on SIGHUP, the
+	// same path 'ipfs config reload' takes
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+	go func() {
+		for range sighup {
+			out, err := commands.ReloadConfig(node, cctx.ConfigRoot)
+			if err != nil {
+				log.Errorf("config reload failed: %s", err)
+				continue
+			}
+			for _, p := range out.Applied {
+				fmt.Printf("Config reloaded: %s\n", p)
+			}
+			for _, p := range out.RestartNeeded {
+				fmt.Printf("Config change requires restart: %s\n", p)
+			}
+		}
+	}()
+
 	// construct api endpoint - every time
 	apiErrc, err := serveHTTPApi(req, cctx)
 	if err != nil {
@@ -392,6 +509,10 @@ func daemonFunc(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment
 	// initialize metrics collector
 	prometheus.MustRegister(&corehttp.IpfsNodeCollector{Node: node})
 
+	events.Emit("node/start", map[string]interface{}{"id": node.Identity.Pretty()})
+	defer events.Emit("node/shutdown", nil)
+	go emitBitswapStats(node)
+
 	fmt.Printf("Daemon is ready\n")
 	// collect long-running errors and block for shutdown
 	// TODO(cryptix): our fuse currently doesnt follow this pattern for graceful shutdown
@@ -438,6 +559,40 @@ func serveHTTPApi(req *cmds.Request, cctx *oldcmds.Context) (<-chan error, error
 		listeners = append(listeners, apiLis)
 	}
 
+	// extra listeners restricted to a scope and/or requiring auth, so a
+	// trusted local admin socket can coexist with a wider-reachable
+	// read-only one.
+	type scopedListener struct {
+		cfg config.APIListener
+		lis manet.Listener
+	}
+
+	scoped := make([]scopedListener, 0, len(cfg.API.Listeners))
+	for _, lcfg := range cfg.API.Listeners {
+		switch lcfg.Scope {
+		case "", config.APIScopeReadOnly, config.APIScopePinOnly, config.APIScopeAdmin:
+		default:
+			return nil, fmt.Errorf("serveHTTPApi: unknown scope %q for API listener %s", lcfg.Scope, lcfg.Address)
+		}
+
+		apiMaddr, err := ma.NewMultiaddr(lcfg.Address)
+		if err != nil {
+			return nil, fmt.Errorf("serveHTTPApi: invalid API listener address: %q (err: %s)", lcfg.Address, err)
+		}
+
+		apiLis, err := manet.Listen(apiMaddr)
+		if err != nil {
+			return nil, fmt.Errorf("serveHTTPApi: manet.Listen(%s) failed: %s", apiMaddr, err)
+		}
+
+		scope := lcfg.Scope
+		if scope == "" {
+			scope = config.APIScopeAdmin
+		}
+		fmt.Printf("API server (scope: %s) listening on %s\n", scope, apiLis.Multiaddr())
+		scoped = append(scoped, scopedListener{cfg: lcfg, lis: apiLis})
+	}
+
 	// by default, we don't let you load arbitrary ipfs objects through the api,
 	// because this would open up the api to scripting vulnerabilities.
 	// only the webui objects are allowed.
@@ -459,6 +614,7 @@ func serveHTTPApi(req *cmds.Request, cctx *oldcmds.Context) (<-chan error, error
 		defaultMux("/debug/pprof/"),
 		corehttp.MutexFractionOption("/debug/pprof-mutex/"),
 		corehttp.MetricsScrapingOption("/debug/metrics/prometheus"),
+		corehttp.HealthOption(),
 		corehttp.LogOption(),
 	}
 
@@ -471,7 +627,25 @@ func serveHTTPApi(req *cmds.Request, cctx *oldcmds.Context) (<-chan error, error
 		return nil, fmt.Errorf("serveHTTPApi: ConstructNode() failed: %s", err)
 	}
 
-	if err := node.Repo.SetAPIAddr(listeners[0].Multiaddr()); err != nil {
+	var tlsCfg *tls.Config
+	apiFileAddr := listeners[0].Multiaddr()
+	if cfg.API.TLS.Enabled {
+		cert, err := corehttp.LoadAPICert(cctx.ConfigRoot, cfg.API.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("serveHTTPApi: loading TLS certificate failed: %s", err)
+		}
+		node.APICertFingerprint = corehttp.CertFingerprint(cert)
+		tlsCfg = &tls.Config{Certificates: []tls.Certificate{cert}}
+
+		// let clients know they have to speak TLS
+		httpsProto, err := ma.NewMultiaddr("/https")
+		if err != nil {
+			return nil, err
+		}
+		apiFileAddr = apiFileAddr.Encapsulate(httpsProto)
+	}
+
+	if err := node.Repo.SetAPIAddr(apiFileAddr); err != nil {
 		return nil, fmt.Errorf("serveHTTPApi: SetAPIAddr() failed: %s", err)
 	}
 
@@ -481,10 +655,36 @@ func serveHTTPApi(req *cmds.Request, cctx *oldcmds.Context) (<-chan error, error
 		wg.Add(1)
 		go func(lis manet.Listener) {
 			defer wg.Done()
-			errc <- corehttp.Serve(node, manet.NetListener(lis), opts...)
+			netLis := net.Listener(manet.NetListener(lis))
+			if tlsCfg != nil {
+				netLis = tls.NewListener(netLis, tlsCfg)
+			}
+			errc <- corehttp.Serve(node, netLis, opts...)
 		}(apiLis)
 	}
 
+	for _, sl := range scoped {
+		// scoped listeners get a reduced option set: commands only, no
+		// webui, gateway or debug endpoints.
+		slOpts := []corehttp.ServeOption{
+			corehttp.MetricsCollectionOption("api"),
+			corehttp.CheckVersionOption(),
+			corehttp.CommandsListenerOption(*cctx, sl.cfg),
+			corehttp.VersionOption(),
+			corehttp.LogOption(),
+		}
+
+		wg.Add(1)
+		go func(lis manet.Listener, slOpts []corehttp.ServeOption) {
+			defer wg.Done()
+			netLis := net.Listener(manet.NetListener(lis))
+			if tlsCfg != nil {
+				netLis = tls.NewListener(netLis, tlsCfg)
+			}
+			errc <- corehttp.Serve(node, netLis, slOpts...)
+		}(sl.lis, slOpts)
+	}
+
 	go func() {
 		wg.Wait()
 		close(errc)
@@ -566,7 +766,7 @@ func serveHTTPGateway(req *cmds.Request, cctx *oldcmds.Context) (<-chan error, e
 		corehttp.GatewayOption(writable, "/ipfs", "/ipns"),
 		corehttp.VersionOption(),
 		corehttp.CheckVersionOption(),
-		corehttp.CommandsROOption(*cctx),
+		corehttp.CommandsGatewayOption(*cctx, cfg.Gateway.APICommands),
 	}
 
 	if cfg.Experimental.P2pHttpProxy {
@@ -700,3 +900,101 @@ func printVersion() {
 	fmt.Printf("System version: %s\n", runtime.GOARCH+"/"+runtime.GOOS)
 	fmt.Printf("Golang version: %s\n", runtime.Version())
 }
+
+// setupLogging applies the persisted logging configuration: output
+// format and destination, rotation, and per-subsystem levels. A zero
+// config leaves the environment-driven defaults untouched.
+func setupLogging(cfg config.Logging) error {
+	var w io.Writer = os.Stderr
+	if cfg.File != "" {
+		if cfg.MaxSizeMB > 0 {
+			rw, err := rotatelog.New(cfg.File, cfg.MaxSizeMB, cfg.MaxBackups)
+			if err != nil {
+				return err
+			}
+			w = rw
+		} else {
+			f, err := os.OpenFile(cfg.File, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+			if err != nil {
+				return err
+			}
+			w = f
+		}
+	}
+
+	switch cfg.Format {
+	case "json":
+		logging.SetupJSONLogging(w)
+	case "color", "nocolor":
+		logging.SetupTextLogging(w, cfg.Format)
+	case "":
+		// format not configured; only redirect output if a file is set
+		if cfg.File != "" {
+			logging.SetupTextLogging(w, "nocolor")
+		}
+	default:
+		return fmt.Errorf("unknown log format: %s", cfg.Format)
+	}
+
+	if cfg.Level != "" {
+		if err := logging.SetLogLevel("*", cfg.Level); err != nil {
+			return err
+		}
+	}
+
+	for subsystem, level := range cfg.Levels {
+		if err := logging.SetLogLevel(subsystem, level); err != nil {
+			log.Errorf("failed to set log level for %s: %s", subsystem, err)
+		}
+	}
+
+	return nil
+}
+
+// sortedOverridePaths returns the config paths of the applied
+// environment overrides in a stable order for printing.
+func sortedOverridePaths(overrides map[string]interface{}) []string {
+	paths := make([]string, 0, len(overrides))
+	for path := range overrides {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// bitswapStatsInterval is how often bitswap counters are delivered to
+// event plugins.
+const bitswapStatsInterval = 30 * time.Second
+
+// emitBitswapStats periodically forwards bitswap counters to event
+// plugins while the node is running.
+func emitBitswapStats(node *core.IpfsNode) {
+	bs, ok := node.Exchange.(*bitswap.Bitswap)
+	if !ok {
+		return
+	}
+
+	t := time.NewTicker(bitswapStatsInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if !events.Active() {
+				continue
+			}
+			st, err := bs.Stat()
+			if err != nil {
+				continue
+			}
+			events.Emit("bitswap/stats", map[string]interface{}{
+				"blocks-received": st.BlocksReceived,
+				"blocks-sent":     st.BlocksSent,
+				"data-received":   st.DataReceived,
+				"data-sent":       st.DataSent,
+				"dup-blocks":      st.DupBlksReceived,
+			})
+		case <-node.Process().Closing():
+			return
+		}
+	}
+}
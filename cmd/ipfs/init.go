@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
 	"strings"
@@ -20,10 +22,19 @@ import (
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	ci "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-crypto"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
 )
 
 const (
 	nBitsForKeypairDefault = 2048
+
+	algorithmDefault        = "rsa"
+	algorithmOptionName     = "algorithm"
+	bitsOptionName          = "bits"
+	emptyRepoOptionName     = "empty-repo"
+	profileOptionName       = "profile"
+	datastoreSpecOptionName = "datastore-spec"
 )
 
 var initCmd = &cmds.Command{
@@ -48,9 +59,11 @@ environment variable:
 		cmdkit.FileArg("default-config", false, false, "Initialize with the given configuration.").EnableStdin(),
 	},
 	Options: []cmdkit.Option{
-		cmdkit.IntOption("bits", "b", "Number of bits to use in the generated RSA private key.").WithDefault(nBitsForKeypairDefault),
-		cmdkit.BoolOption("empty-repo", "e", "Don't add and pin help files to the local storage."),
-		cmdkit.StringOption("profile", "p", "Apply profile settings to config. Multiple profiles can be separated by ','"),
+		cmdkit.StringOption(algorithmOptionName, "a", "Cryptographic algorithm to use for the generated keypair: 'rsa' or 'ed25519'.").WithDefault(algorithmDefault),
+		cmdkit.IntOption(bitsOptionName, "b", "Number of bits to use in the generated RSA private key.").WithDefault(nBitsForKeypairDefault),
+		cmdkit.BoolOption(emptyRepoOptionName, "e", "Don't add and pin help files to the local storage."),
+		cmdkit.StringOption(profileOptionName, "p", "Apply profile settings to config. Multiple profiles can be separated by ','"),
+		cmdkit.StringOption(datastoreSpecOptionName, "Initialize the repo with the datastore spec read from the given JSON file."),
 
 		// TODO need to decide whether to expose the override as a file or a
 		// directory. That is: should we allow the user to also specify the
@@ -79,8 +92,9 @@ environment variable:
 			return cmdkit.Error{Message: "init must be run offline only"}
 		}
 
-		empty, _ := req.Options["empty-repo"].(bool)
-		nBitsForKeypair, _ := req.Options["bits"].(int)
+		empty, _ := req.Options[emptyRepoOptionName].(bool)
+		algorithm, _ := req.Options[algorithmOptionName].(string)
+		nBitsForKeypair, _ := req.Options[bitsOptionName].(int)
 
 		var conf *config.Config
 
@@ -104,14 +118,27 @@ environment variable:
 			}
 		}
 
-		profile, _ := req.Options["profile"].(string)
+		var dsSpec map[string]interface{}
+		if dsSpecPath, ok := req.Options[datastoreSpecOptionName].(string); ok && dsSpecPath != "" {
+			if conf != nil && conf.Datastore.Spec != nil {
+				return fmt.Errorf("cannot use --%s with a config file that already specifies a datastore", datastoreSpecOptionName)
+			}
+
+			var err error
+			dsSpec, err = readDatastoreSpec(dsSpecPath)
+			if err != nil {
+				return err
+			}
+		}
+
+		profile, _ := req.Options[profileOptionName].(string)
 
 		var profiles []string
 		if profile != "" {
 			profiles = strings.Split(profile, ",")
 		}
 
-		return doInit(os.Stdout, cctx.ConfigRoot, empty, nBitsForKeypair, profiles, conf)
+		return doInit(os.Stdout, cctx.ConfigRoot, empty, algorithm, nBitsForKeypair, profiles, conf, dsSpec)
 	},
 }
 
@@ -125,10 +152,10 @@ func initWithDefaults(out io.Writer, repoRoot string, profile string) error {
 		profiles = strings.Split(profile, ",")
 	}
 
-	return doInit(out, repoRoot, false, nBitsForKeypairDefault, profiles, nil)
+	return doInit(out, repoRoot, false, algorithmDefault, nBitsForKeypairDefault, profiles, nil, nil)
 }
 
-func doInit(out io.Writer, repoRoot string, empty bool, nBitsForKeypair int, confProfiles []string, conf *config.Config) error {
+func doInit(out io.Writer, repoRoot string, empty bool, algorithm string, nBitsForKeypair int, confProfiles []string, conf *config.Config, dsSpec map[string]interface{}) error {
 	if _, err := fmt.Fprintf(out, "initializing IPFS node at %s\n", repoRoot); err != nil {
 		return err
 	}
@@ -142,13 +169,21 @@ func doInit(out io.Writer, repoRoot string, empty bool, nBitsForKeypair int, con
 	}
 
 	if conf == nil {
-		var err error
-		conf, err = config.Init(out, nBitsForKeypair)
+		identity, err := identityConfig(out, algorithm, nBitsForKeypair)
+		if err != nil {
+			return err
+		}
+
+		conf, err = config.InitWithIdentity(identity)
 		if err != nil {
 			return err
 		}
 	}
 
+	if dsSpec != nil {
+		conf.Datastore.Spec = dsSpec
+	}
+
 	for _, profile := range confProfiles {
 		transformer, ok := config.Profiles[profile]
 		if !ok {
@@ -173,6 +208,69 @@ func doInit(out io.Writer, repoRoot string, empty bool, nBitsForKeypair int, con
 	return initializeIpnsKeyspace(repoRoot)
 }
 
+// identityConfig generates the node's keypair with the given algorithm.
+// The bits argument is only meaningful for RSA keys.
+func identityConfig(out io.Writer, algorithm string, nbits int) (config.Identity, error) {
+	ident := config.Identity{}
+
+	var sk ci.PrivKey
+	var pk ci.PubKey
+	var err error
+	switch algorithm {
+	case "rsa":
+		if nbits < 1024 {
+			return ident, errors.New("bitsize less than 1024 is considered unsafe")
+		}
+
+		fmt.Fprintf(out, "generating %v-bit RSA keypair...", nbits)
+		sk, pk, err = ci.GenerateKeyPair(ci.RSA, nbits)
+	case "ed25519":
+		fmt.Fprintf(out, "generating ed25519 keypair...")
+		sk, pk, err = ci.GenerateKeyPair(ci.Ed25519, -1)
+	default:
+		return ident, fmt.Errorf("unrecognized key algorithm: %s", algorithm)
+	}
+	if err != nil {
+		return ident, err
+	}
+	fmt.Fprintf(out, "done\n")
+
+	// currently storing key unencrypted. in the future we need to encrypt it.
+	// TODO(security)
+	skbytes, err := sk.Bytes()
+	if err != nil {
+		return ident, err
+	}
+	ident.PrivKey = base64.StdEncoding.EncodeToString(skbytes)
+
+	id, err := peer.IDFromPublicKey(pk)
+	if err != nil {
+		return ident, err
+	}
+	ident.PeerID = id.Pretty()
+	fmt.Fprintf(out, "peer identity: %s\n", ident.PeerID)
+	return ident, nil
+}
+
+// readDatastoreSpec loads and validates a datastore spec from a JSON file.
+func readDatastoreSpec(path string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading datastore spec: %s", err)
+	}
+
+	var spec map[string]interface{}
+	if err := json.Unmarshal(b, &spec); err != nil {
+		return nil, fmt.Errorf("parsing datastore spec: %s", err)
+	}
+
+	if _, err := fsrepo.AnyDatastoreConfig(spec); err != nil {
+		return nil, fmt.Errorf("invalid datastore spec: %s", err)
+	}
+
+	return spec, nil
+}
+
 func checkWritable(dir string) error {
 	_, err := os.Stat(dir)
 	if err == nil {
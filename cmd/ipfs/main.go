@@ -3,9 +3,13 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
+	gohttp "net/http"
 	"os"
 	"path/filepath"
 	"runtime/pprof"
@@ -60,7 +64,15 @@ func loadPlugins(repoPath string) (*loader.PluginLoader, error) {
 	if !ok {
 		pluginpath = ""
 	}
-	plugins, err = loader.NewPluginLoader(pluginpath)
+
+	var pcfg config.Plugins
+	if pluginpath != "" {
+		if cfg, err := fsrepo.ConfigAt(repoPath); err == nil {
+			pcfg = cfg.Plugins
+		}
+	}
+
+	plugins, err = loader.NewPluginLoader(pluginpath, pcfg)
 	if err != nil {
 		log.Error("error loading plugins: ", err)
 	}
@@ -409,10 +421,21 @@ func getAPIClient(ctx context.Context, repoPath, apiAddrStr string) (http.Client
 	if len(addr.Protocols()) == 0 {
 		return nil, fmt.Errorf(apiErrorFmt, repoPath, "multiaddr doesn't provide any protocols")
 	}
-	return apiClientForAddr(ctx, addr)
+	return apiClientForAddr(ctx, repoPath, addr)
 }
 
-func apiClientForAddr(ctx context.Context, addr ma.Multiaddr) (http.Client, error) {
+func apiClientForAddr(ctx context.Context, repoPath string, addr ma.Multiaddr) (http.Client, error) {
+	// a trailing /https means the daemon serves the API over TLS
+	secure := false
+	if _, err := addr.ValueForProtocol(ma.P_HTTPS); err == nil {
+		httpsProto, err := ma.NewMultiaddr("/https")
+		if err != nil {
+			return nil, err
+		}
+		secure = true
+		addr = addr.Decapsulate(httpsProto)
+	}
+
 	addr, err := resolveAddr(ctx, addr)
 	if err != nil {
 		return nil, err
@@ -423,9 +446,38 @@ func apiClientForAddr(ctx context.Context, addr ma.Multiaddr) (http.Client, erro
 		return nil, err
 	}
 
+	if secure {
+		httpClient, err := apiTLSClient(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		return http.NewClient("https://"+host,
+			http.ClientWithAPIPrefix(corehttp.APIPath),
+			http.ClientWithHTTPClient(httpClient)), nil
+	}
+
 	return http.NewClient(host, http.ClientWithAPIPrefix(corehttp.APIPath)), nil
 }
 
+// apiTLSClient builds an http client that, in addition to the system
+// roots, trusts the repo-generated self-signed API certificate.
+func apiTLSClient(repoPath string) (*gohttp.Client, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if pem, err := ioutil.ReadFile(corehttp.APICertPath(repoPath)); err == nil {
+		pool.AppendCertsFromPEM(pem)
+	}
+
+	return &gohttp.Client{
+		Transport: &gohttp.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}, nil
+}
+
 func resolveAddr(ctx context.Context, addr ma.Multiaddr) (ma.Multiaddr, error) {
 	ctx, cancelFunc := context.WithTimeout(ctx, 10*time.Second)
 	defer cancelFunc()
@@ -225,6 +225,22 @@ func toPeerInfos(bpeers []config.BootstrapPeer) []pstore.PeerInfo {
 	return peers
 }
 
+// mergePeerInfos appends peers from extra that are not already
+// present in base. Entries in base win on duplicate IDs.
+func mergePeerInfos(base, extra []pstore.PeerInfo) []pstore.PeerInfo {
+	seen := make(map[peer.ID]bool, len(base))
+	for _, pi := range base {
+		seen[pi.ID] = true
+	}
+	for _, pi := range extra {
+		if !seen[pi.ID] {
+			base = append(base, pi)
+			seen[pi.ID] = true
+		}
+	}
+	return base
+}
+
 func randomSubsetOfPeers(in []pstore.PeerInfo, max int) []pstore.PeerInfo {
 	n := math2.IntMin(max, len(in))
 	var out []pstore.PeerInfo
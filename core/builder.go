@@ -195,16 +195,22 @@ func setupNode(ctx context.Context, n *IpfsNode, cfg *BuildCfg) error {
 		TempErrFunc: isTooManyFDError,
 	}
 
-	// hash security
-	bs := bstore.NewBlockstore(rds)
-	bs = &verifbs.VerifBS{Blockstore: bs}
-
-	opts := bstore.DefaultCacheOpts()
 	conf, err := n.Repo.Config()
 	if err != nil {
 		return err
 	}
 
+	// hash security
+	var bs bstore.Blockstore
+	if conf.Datastore.WriteThrough {
+		bs = bstore.NewWriteThrough(rds)
+	} else {
+		bs = bstore.NewBlockstore(rds)
+	}
+	bs = &verifbs.VerifBS{Blockstore: bs}
+
+	opts := bstore.DefaultCacheOpts()
+
 	// TEMP: setting global sharding switch here
 	uio.UseHAMTSharding = conf.Experimental.ShardingEnabled
 
@@ -256,7 +262,8 @@ func setupNode(ctx context.Context, n *IpfsNode, cfg *BuildCfg) error {
 
 	if cfg.Online {
 		do := setupDiscoveryOption(rcfg.Discovery)
-		if err := n.startOnlineServices(ctx, cfg.Routing, hostOption, do, cfg.getOpt("pubsub"), cfg.getOpt("ipnsps"), cfg.getOpt("mplex")); err != nil {
+		ipnsps := cfg.getOpt("ipnsps") || rcfg.Experimental.IpnsPubsub
+		if err := n.startOnlineServices(ctx, cfg.Routing, hostOption, do, cfg.getOpt("pubsub"), ipnsps, cfg.getOpt("mplex")); err != nil {
 			return err
 		}
 	} else {
@@ -265,7 +272,15 @@ func setupNode(ctx context.Context, n *IpfsNode, cfg *BuildCfg) error {
 		n.Namesys = namesys.NewNameSystem(n.Routing, n.Repo.Datastore(), 0)
 	}
 
-	n.Blocks = bserv.New(n.Blockstore, n.Exchange)
+	servBS := bstore.Blockstore(n.Blockstore)
+	if n.FetchCache != nil {
+		servBS = n.FetchCache
+	}
+	if rcfg.Datastore.WriteThrough {
+		n.Blocks = bserv.NewWriteThrough(servBS, n.Exchange)
+	} else {
+		n.Blocks = bserv.New(servBS, n.Exchange)
+	}
 	n.DAG = dag.NewDAGService(n.Blocks)
 
 	internalDag := dag.NewDAGService(bserv.New(n.Blockstore, offline.Exchange(n.Blockstore)))
@@ -278,6 +293,7 @@ func setupNode(ctx context.Context, n *IpfsNode, cfg *BuildCfg) error {
 		n.Pinning = pin.NewPinner(n.Repo.Datastore(), n.DAG, internalDag)
 	}
 	n.Resolver = resolver.NewBasicResolver(n.DAG)
+	n.PinQueue = pin.NewQueue(ctx, n.Pinning, n.DAG, n.Blockstore)
 
 	if cfg.Online {
 		if err := n.startLateOnlineServices(ctx); err != nil {
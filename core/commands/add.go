@@ -1,11 +1,14 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	gopath "path"
 	"strings"
 
+	core "github.com/ipsn/go-ipfs/core"
 	"github.com/ipsn/go-ipfs/core/commands/cmdenv"
 	coreiface "github.com/ipsn/go-ipfs/core/coreapi/interface"
 	"github.com/ipsn/go-ipfs/core/coreapi/interface/options"
@@ -13,6 +16,7 @@ import (
 	pb "github.com/cheggaaa/pb"
 	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
 	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	mfs "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-mfs"
 	mh "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multihash"
 )
 
@@ -38,6 +42,9 @@ const (
 	hashOptionName        = "hash"
 	inlineOptionName      = "inline"
 	inlineLimitOptionName = "inline-limit"
+	toFilesOptionName     = "to-files"
+	detectMimeOptionName  = "detect-mime"
+	provideOptionName     = "provide"
 )
 
 const adderOutChanSize = 8
@@ -118,6 +125,10 @@ You can now check what blocks have been created by:
 		cmdkit.StringOption(hashOptionName, "Hash function to use. Implies CIDv1 if not sha2-256. (experimental)").WithDefault("sha2-256"),
 		cmdkit.BoolOption(inlineOptionName, "Inline small blocks into CIDs. (experimental)"),
 		cmdkit.IntOption(inlineLimitOptionName, "Maximum block size to inline. (experimental)").WithDefault(32),
+		cmdkit.StringOption(toFilesOptionName, "Add reference to Files API (MFS) at the provided path."),
+		cmdkit.BoolOption(detectMimeOptionName, "Detect each file's content type and record it in a metadata node. (experimental)"),
+		cmdkit.StringOption(fromURLOptionName, "Download this URL and add its content instead of reading a path argument."),
+		cmdkit.BoolOption(provideOptionName, "Announce the added content to the network.").WithDefault(true),
 	},
 	PreRun: func(req *cmds.Request, env cmds.Environment) error {
 		quiet, _ := req.Options[quietOptionName].(bool)
@@ -126,14 +137,24 @@ You can now check what blocks have been created by:
 
 		silent, _ := req.Options[silentOptionName].(bool)
 
-		if quiet || silent {
-			return nil
+		if !quiet && !silent {
+			// ipfs cli progress bar defaults to true unless quiet or silent is used
+			_, found := req.Options[progressOptionName].(bool)
+			if !found {
+				req.Options[progressOptionName] = true
+			}
 		}
 
-		// ipfs cli progress bar defaults to true unless quiet or silent is used
-		_, found := req.Options[progressOptionName].(bool)
-		if !found {
-			req.Options[progressOptionName] = true
+		// with --from-url the content comes from the network, not from
+		// the path arguments; download it (resuming any earlier partial
+		// download) before the add pipeline runs
+		if src, ok := req.Options[fromURLOptionName].(string); ok && src != "" {
+			progress, _ := req.Options[progressOptionName].(bool)
+			f, err := fetchURLFile(req.Context, src, progress)
+			if err != nil {
+				return err
+			}
+			req.Files = f
 		}
 
 		return nil
@@ -160,12 +181,35 @@ You can now check what blocks have been created by:
 		inline, _ := req.Options[inlineOptionName].(bool)
 		inlineLimit, _ := req.Options[inlineLimitOptionName].(int)
 		pathName, _ := req.Options[stdinPathName].(string)
+		toFilesStr, _ := req.Options[toFilesOptionName].(string)
+		detectMime, _ := req.Options[detectMimeOptionName].(bool)
+		provide, _ := req.Options[provideOptionName].(bool)
+
+		if toFilesStr != "" && hash {
+			return fmt.Errorf("cannot use --%s with --%s", toFilesOptionName, onlyHashOptionName)
+		}
 
 		hashFunCode, ok := mh.Names[strings.ToLower(hashFunStr)]
 		if !ok {
 			return fmt.Errorf("unrecognized hash function: %s", strings.ToLower(hashFunStr))
 		}
 
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		// admission control against the hosting contract quota
+		var uploadSize uint64
+		if size, err := req.Files.Size(); err == nil && size > 0 {
+			uploadSize = uint64(size)
+		}
+		if !hash {
+			if err := nd.EthoFS.CheckQuota(req.Context, uploadSize); err != nil {
+				return err
+			}
+		}
+
 		events := make(chan interface{}, adderOutChanSize)
 
 		opts := []options.UnixfsAddOption{
@@ -184,6 +228,8 @@ You can now check what blocks have been created by:
 			options.Unixfs.Wrap(wrap),
 			options.Unixfs.Hidden(hidden),
 			options.Unixfs.StdinName(pathName),
+			options.Unixfs.DetectMime(detectMime),
+			options.Unixfs.Provide(provide),
 
 			options.Unixfs.Progress(progress),
 			options.Unixfs.Silent(silent),
@@ -202,12 +248,13 @@ You can now check what blocks have been created by:
 			opts = append(opts, options.Unixfs.Layout(options.TrickleLayout))
 		}
 
+		var addedPath coreiface.ResolvedPath
 		errCh := make(chan error)
 		go func() {
 			var err error
 			defer func() { errCh <- err }()
 			defer close(events)
-			_, err = api.Unixfs().Add(req.Context, req.Files, opts...)
+			addedPath, err = api.Unixfs().Add(req.Context, req.Files, opts...)
 		}()
 
 		err = res.Emit(events)
@@ -215,7 +262,21 @@ You can now check what blocks have been created by:
 			return err
 		}
 
-		return <-errCh
+		if err := <-errCh; err != nil {
+			return err
+		}
+		if !hash {
+			nd.EthoFS.ChargeQuota(uploadSize)
+		}
+
+		// link the result into mfs in the same operation, so the add
+		// cannot race a gc run before the reference exists.
+		if toFilesStr != "" {
+			if err := addToFiles(req.Context, nd, api, addedPath, toFilesStr); err != nil {
+				return err
+			}
+		}
+		return nil
 	},
 	PostRun: cmds.PostRunMap{
 		cmds.CLI: func(res cmds.Response, re cmds.ResponseEmitter) error {
@@ -359,3 +420,32 @@ You can now check what blocks have been created by:
 	},
 	Type: coreiface.AddEvent{},
 }
+
+// addToFiles links an added path into mfs at dst, creating parent
+// directories as needed. A dst ending in '/' is treated as a directory
+// to link the result under, keeping its added name.
+func addToFiles(ctx context.Context, nd *core.IpfsNode, api coreiface.CoreAPI, added coreiface.ResolvedPath, dst string) error {
+	dst, err := checkPath(dst)
+	if err != nil {
+		return err
+	}
+
+	if dst[len(dst)-1] == '/' {
+		dst += gopath.Base(added.String())
+	}
+
+	node, err := api.ResolveNode(ctx, added)
+	if err != nil {
+		return fmt.Errorf("to-files: cannot get node from path %s: %s", added, err)
+	}
+
+	if err := mfs.Mkdir(nd.FilesRoot, gopath.Dir(dst), mfs.MkdirOpts{Mkparents: true}); err != nil {
+		return fmt.Errorf("to-files: cannot create parent directories for %s: %s", dst, err)
+	}
+
+	if err := mfs.PutNode(nd.FilesRoot, dst, node); err != nil {
+		return fmt.Errorf("to-files: cannot put node in path %s: %s", dst, err)
+	}
+
+	return mfs.FlushPath(nd.FilesRoot, dst)
+}
@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	core "github.com/ipsn/go-ipfs/core"
+	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
+
+	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+)
+
+const authScopeOptionName = "scope"
+
+// AuthTokenOutput is the result of 'ipfs auth token create'. Token is
+// only ever set at creation time.
+type AuthTokenOutput struct {
+	Name  string
+	Scope string
+	Token string `json:",omitempty"`
+}
+
+// AuthTokenListOutput is the result of 'ipfs auth token list'.
+type AuthTokenListOutput struct {
+	Tokens []AuthTokenOutput
+}
+
+var AuthCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Manage API access credentials.",
+		ShortDescription: `
+'ipfs auth' manages the bearer tokens accepted by the commands API.
+Tokens carry a scope restricting which commands they may call:
+
+  read-only  - commands that only read node or DAG state
+  pin-only   - the read-only set plus pin management
+  admin      - every command
+
+Enforcement is controlled by the APIAuth.Enabled config flag.
+`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"token": authTokenCmd,
+	},
+}
+
+var authTokenCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Manage API auth tokens.",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"create": authTokenCreateCmd,
+		"list":   authTokenListCmd,
+		"revoke": authTokenRevokeCmd,
+	},
+}
+
+var authTokenCreateCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Create a new API auth token.",
+		ShortDescription: `
+Generates a new bearer token, stores its hash in the config and prints
+the secret. The secret cannot be recovered later; only its hash is
+kept.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("name", true, false, "Unique label for the token."),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption(authScopeOptionName, "Scope granted to the token (read-only, pin-only or admin).").WithDefault(config.APIScopeReadOnly),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		name := req.Arguments[0]
+		scope, _ := req.Options[authScopeOptionName].(string)
+		switch scope {
+		case config.APIScopeReadOnly, config.APIScopePinOnly, config.APIScopeAdmin:
+		default:
+			return fmt.Errorf("unknown scope: %s", scope)
+		}
+
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		token := base64.RawURLEncoding.EncodeToString(secret)
+		sum := sha256.Sum256([]byte(token))
+
+		err = updateAPIAuthConfig(n, func(auth *config.APIAuth) error {
+			for _, t := range auth.Tokens {
+				if t.Name == name {
+					return fmt.Errorf("token %q already exists", name)
+				}
+			}
+			auth.Tokens = append(auth.Tokens, config.APIToken{
+				Name:      name,
+				TokenHash: hex.EncodeToString(sum[:]),
+				Scope:     scope,
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &AuthTokenOutput{
+			Name:  name,
+			Scope: scope,
+			Token: token,
+		})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *AuthTokenOutput) error {
+			fmt.Fprintf(w, "created token %s with scope %s\n", out.Name, out.Scope)
+			fmt.Fprintf(w, "%s\n", out.Token)
+			fmt.Fprintln(w, "store this secret now; it will not be shown again")
+			return nil
+		}),
+	},
+	Type: AuthTokenOutput{},
+}
+
+var authTokenListCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List configured API auth tokens.",
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := n.Repo.Config()
+		if err != nil {
+			return err
+		}
+
+		out := &AuthTokenListOutput{}
+		for _, t := range cfg.APIAuth.Tokens {
+			out.Tokens = append(out.Tokens, AuthTokenOutput{
+				Name:  t.Name,
+				Scope: t.Scope,
+			})
+		}
+		return cmds.EmitOnce(res, out)
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *AuthTokenListOutput) error {
+			tw := tabwriter.NewWriter(w, 1, 2, 1, ' ', 0)
+			for _, t := range out.Tokens {
+				fmt.Fprintf(tw, "%s\t%s\n", t.Name, t.Scope)
+			}
+			return tw.Flush()
+		}),
+	},
+	Type: AuthTokenListOutput{},
+}
+
+var authTokenRevokeCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Revoke an API auth token.",
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("name", true, false, "Label of the token to revoke."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		name := req.Arguments[0]
+		err = updateAPIAuthConfig(n, func(auth *config.APIAuth) error {
+			for i, t := range auth.Tokens {
+				if t.Name == name {
+					auth.Tokens = append(auth.Tokens[:i], auth.Tokens[i+1:]...)
+					return nil
+				}
+			}
+			return fmt.Errorf("no such token: %s", name)
+		})
+		if err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &stringList{[]string{name}})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *stringList) error {
+			for _, s := range out.Strings {
+				fmt.Fprintf(w, "revoked token %s\n", s)
+			}
+			return nil
+		}),
+	},
+	Type: stringList{},
+}
+
+func updateAPIAuthConfig(n *core.IpfsNode, update func(*config.APIAuth) error) error {
+	cfg, err := n.Repo.Config()
+	if err != nil {
+		return err
+	}
+
+	if err := update(&cfg.APIAuth); err != nil {
+		return err
+	}
+
+	return n.Repo.SetConfig(cfg)
+}
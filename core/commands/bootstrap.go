@@ -2,16 +2,19 @@ package commands
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"sort"
 
 	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
+	dnsaddr "github.com/ipsn/go-ipfs/dnsaddr"
 	repo "github.com/ipsn/go-ipfs/repo"
 	fsrepo "github.com/ipsn/go-ipfs/repo/fsrepo"
 
 	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
 	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
 	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
 )
 
 type BootstrapOutput struct {
@@ -33,9 +36,11 @@ Running 'ipfs bootstrap' with no arguments will run 'ipfs bootstrap list'.
 	Type:     bootstrapListCmd.Type,
 
 	Subcommands: map[string]*cmds.Command{
-		"list": bootstrapListCmd,
-		"add":  bootstrapAddCmd,
-		"rm":   bootstrapRemoveCmd,
+		"list":   bootstrapListCmd,
+		"add":    bootstrapAddCmd,
+		"rm":     bootstrapRemoveCmd,
+		"set":    bootstrapSetCmd,
+		"health": bootstrapHealthCmd,
 	},
 }
 
@@ -399,6 +404,340 @@ func bootstrapRemoveAll(r repo.Repo, cfg *config.Config) ([]config.BootstrapPeer
 	return removed, nil
 }
 
+// builtinBootstrapSets are the named bootstrap lists that are always
+// available, next to the custom sets saved in the config.
+var builtinBootstrapSets = map[string][]string{
+	"default": config.DefaultBootstrapAddresses,
+	"ethofs":  config.EthofsBootstrapAddresses,
+}
+
+// bootstrapSetAddresses resolves a set name to its addresses, checking the
+// built-in sets before the custom ones in the config.
+func bootstrapSetAddresses(cfg *config.Config, name string) ([]string, error) {
+	if addrs, ok := builtinBootstrapSets[name]; ok {
+		return addrs, nil
+	}
+	if addrs, ok := cfg.BootstrapSets[name]; ok {
+		return addrs, nil
+	}
+	return nil, fmt.Errorf("unknown bootstrap set '%s'", name)
+}
+
+// BootstrapSetInfo describes one named bootstrap set.
+type BootstrapSetInfo struct {
+	Name    string
+	Peers   int
+	BuiltIn bool
+}
+
+// BootstrapSetList is the result of 'ipfs bootstrap set ls'.
+type BootstrapSetList struct {
+	Sets []BootstrapSetInfo
+}
+
+var bootstrapSetCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Manage named bootstrap sets.",
+		ShortDescription: `
+'ipfs bootstrap set' manages named bootstrap lists. The built-in sets
+'default' and 'ethofs' are always available; custom sets can be saved
+from the current bootstrap list and activated later, so switching
+between networks doesn't mean re-entering addresses by hand.
+` + bootstrapSecurityWarning,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"ls":   bootstrapSetLsCmd,
+		"save": bootstrapSetSaveCmd,
+		"use":  bootstrapSetUseCmd,
+		"rm":   bootstrapSetRmCmd,
+	},
+}
+
+var bootstrapSetLsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List the named bootstrap sets.",
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		cfgRoot, err := cmdenv.GetConfigRoot(env)
+		if err != nil {
+			return err
+		}
+
+		r, err := fsrepo.Open(cfgRoot)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		cfg, err := r.Config()
+		if err != nil {
+			return err
+		}
+
+		var sets []BootstrapSetInfo
+		for name, addrs := range builtinBootstrapSets {
+			sets = append(sets, BootstrapSetInfo{Name: name, Peers: len(addrs), BuiltIn: true})
+		}
+		for name, addrs := range cfg.BootstrapSets {
+			sets = append(sets, BootstrapSetInfo{Name: name, Peers: len(addrs)})
+		}
+		sort.Slice(sets, func(i, j int) bool {
+			return sets[i].Name < sets[j].Name
+		})
+
+		return cmds.EmitOnce(res, &BootstrapSetList{Sets: sets})
+	},
+	Type: BootstrapSetList{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *BootstrapSetList) error {
+			for _, s := range out.Sets {
+				kind := "custom"
+				if s.BuiltIn {
+					kind = "built-in"
+				}
+				if _, err := fmt.Fprintf(w, "%s\t%d peers\t(%s)\n", s.Name, s.Peers, kind); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	},
+}
+
+var bootstrapSetSaveCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline:          "Save the current bootstrap list as a named set.",
+		ShortDescription: `Saves the current bootstrap list under the given name, overwriting an existing custom set of the same name. Built-in set names cannot be used.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("name", true, false, "Name to save the set under."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		name := req.Arguments[0]
+		if _, ok := builtinBootstrapSets[name]; ok {
+			return fmt.Errorf("'%s' is a built-in bootstrap set and cannot be overwritten", name)
+		}
+
+		cfgRoot, err := cmdenv.GetConfigRoot(env)
+		if err != nil {
+			return err
+		}
+
+		r, err := fsrepo.Open(cfgRoot)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		cfg, err := r.Config()
+		if err != nil {
+			return err
+		}
+
+		if cfg.BootstrapSets == nil {
+			cfg.BootstrapSets = make(map[string][]string)
+		}
+		saved := append([]string(nil), cfg.Bootstrap...)
+		cfg.BootstrapSets[name] = saved
+
+		if err := r.SetConfig(cfg); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &BootstrapOutput{saved})
+	},
+	Type: BootstrapOutput{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *BootstrapOutput) error {
+			return bootstrapWritePeers(w, "saved ", out.Peers)
+		}),
+	},
+}
+
+var bootstrapSetUseCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline:          "Replace the bootstrap list with a named set.",
+		ShortDescription: `Replaces the current bootstrap list with the contents of the given set and outputs the new list.` + bootstrapSecurityWarning,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("name", true, false, "Name of the set to activate."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		cfgRoot, err := cmdenv.GetConfigRoot(env)
+		if err != nil {
+			return err
+		}
+
+		r, err := fsrepo.Open(cfgRoot)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		cfg, err := r.Config()
+		if err != nil {
+			return err
+		}
+
+		addrs, err := bootstrapSetAddresses(cfg, req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		// validate before replacing anything
+		if _, err := config.ParseBootstrapPeers(addrs); err != nil {
+			return err
+		}
+
+		cfg.Bootstrap = append([]string(nil), addrs...)
+		if err := r.SetConfig(cfg); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &BootstrapOutput{cfg.Bootstrap})
+	},
+	Type: BootstrapOutput{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *BootstrapOutput) error {
+			return bootstrapWritePeers(w, "", out.Peers)
+		}),
+	},
+}
+
+var bootstrapSetRmCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Remove a custom bootstrap set.",
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("name", true, false, "Name of the set to remove."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		name := req.Arguments[0]
+		if _, ok := builtinBootstrapSets[name]; ok {
+			return fmt.Errorf("'%s' is a built-in bootstrap set and cannot be removed", name)
+		}
+
+		cfgRoot, err := cmdenv.GetConfigRoot(env)
+		if err != nil {
+			return err
+		}
+
+		r, err := fsrepo.Open(cfgRoot)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		cfg, err := r.Config()
+		if err != nil {
+			return err
+		}
+
+		removed, ok := cfg.BootstrapSets[name]
+		if !ok {
+			return fmt.Errorf("unknown bootstrap set '%s'", name)
+		}
+		delete(cfg.BootstrapSets, name)
+
+		if err := r.SetConfig(cfg); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &BootstrapOutput{removed})
+	},
+	Type: BootstrapOutput{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *BootstrapOutput) error {
+			return bootstrapWritePeers(w, "removed ", out.Peers)
+		}),
+	},
+}
+
+// BootstrapPeerHealth reports the status of one bootstrap list entry.
+type BootstrapPeerHealth struct {
+	Entry     string
+	Peer      string `json:",omitempty"`
+	Addrs     int
+	Connected bool
+	Latency   string `json:",omitempty"`
+	Error     string `json:",omitempty"`
+}
+
+// BootstrapHealth is the result of 'ipfs bootstrap health'.
+type BootstrapHealth struct {
+	Peers []BootstrapPeerHealth
+}
+
+var bootstrapHealthCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Report the health of each bootstrap peer.",
+		ShortDescription: `
+'ipfs bootstrap health' re-resolves every bootstrap list entry (expanding
+dnsaddr records) and reports, per peer, whether we are currently connected
+and the last known latency. Entries that fail to resolve are listed with
+the error, making broken bootstrap records easy to spot.
+`,
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+		if n.PeerHost == nil {
+			return ErrNotOnline
+		}
+
+		cfg, err := n.Repo.Config()
+		if err != nil {
+			return err
+		}
+
+		var out []BootstrapPeerHealth
+		for _, entry := range cfg.Bootstrap {
+			pis, err := dnsaddr.Peers(req.Context, []string{entry})
+			if err != nil {
+				out = append(out, BootstrapPeerHealth{Entry: entry, Error: err.Error()})
+				continue
+			}
+
+			for _, pi := range pis {
+				h := BootstrapPeerHealth{
+					Entry:     entry,
+					Peer:      pi.ID.Pretty(),
+					Addrs:     len(pi.Addrs),
+					Connected: n.PeerHost.Network().Connectedness(pi.ID) == inet.Connected,
+				}
+				if lat := n.Peerstore.LatencyEWMA(pi.ID); lat > 0 {
+					h.Latency = lat.String()
+				}
+				out = append(out, h)
+			}
+		}
+
+		return cmds.EmitOnce(res, &BootstrapHealth{Peers: out})
+	},
+	Type: BootstrapHealth{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *BootstrapHealth) error {
+			for _, h := range out.Peers {
+				if h.Error != "" {
+					if _, err := fmt.Fprintf(w, "%s: error: %s\n", h.Entry, h.Error); err != nil {
+						return err
+					}
+					continue
+				}
+				status := "not connected"
+				if h.Connected {
+					status = "connected"
+				}
+				line := fmt.Sprintf("%s: %s, %d addrs", h.Peer, status, h.Addrs)
+				if h.Latency != "" {
+					line += ", latency " + h.Latency
+				}
+				if _, err := fmt.Fprintln(w, line); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	},
+}
+
 const bootstrapSecurityWarning = `
 SECURITY WARNING:
 
@@ -32,6 +32,7 @@ var CatCmd = &cmds.Command{
 	Options: []cmdkit.Option{
 		cmdkit.Int64Option(offsetOptionName, "o", "Byte offset to begin reading from."),
 		cmdkit.Int64Option(lengthOptionName, "l", "Maximum number of bytes to read."),
+		cmdenv.FetchTimeoutOption(),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		api, err := cmdenv.GetApi(env, req)
@@ -58,10 +59,16 @@ var CatCmd = &cmds.Command{
 			return err
 		}
 
-		readers, length, err := cat(req.Context, api, req.Arguments, int64(offset), int64(max))
+		ctx, cancel, err := cmdenv.FetchContext(req)
 		if err != nil {
 			return err
 		}
+		defer cancel()
+
+		readers, length, err := cat(ctx, api, req.Arguments, int64(offset), int64(max))
+		if err != nil {
+			return cmdenv.FetchError(ctx, err)
+		}
 
 		/*
 			if err := corerepo.ConditionalGC(req.Context, node, length); err != nil {
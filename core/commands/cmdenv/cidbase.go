@@ -0,0 +1,42 @@
+package cmdenv
+
+import (
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+	mbase "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multibase"
+)
+
+// CidBaseOptionName is the shared option selecting the multibase that
+// commands print cids in.
+const CidBaseOptionName = "cid-base"
+
+// CidBaseOption returns the --cid-base option declaration, for commands
+// that print cids.
+func CidBaseOption() cmdkit.Option {
+	return cmdkit.StringOption(CidBaseOptionName, "Multibase encoding used for output cids, e.g. base32, base58btc, base64url. Default: whatever the cid was created in.")
+}
+
+// CidEncoder returns a function rendering cids the way the request asked
+// for with --cid-base. Without the option (and for v0 cids, which only
+// have a base58btc form) cids print in their default base.
+func CidEncoder(req *cmds.Request) (func(cid.Cid) string, error) {
+	name, ok := req.Options[CidBaseOptionName].(string)
+	if !ok || name == "" {
+		return func(c cid.Cid) string { return c.String() }, nil
+	}
+
+	enc, err := mbase.EncoderByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	base := enc.Encoding()
+	return func(c cid.Cid) string {
+		s, err := c.StringOfBase(base)
+		if err != nil {
+			return c.String()
+		}
+		return s
+	}, nil
+}
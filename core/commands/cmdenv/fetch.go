@@ -0,0 +1,57 @@
+package cmdenv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+)
+
+// FetchTimeoutOptionName is the shared option bounding how long commands
+// wait for content that has to be fetched from the network.
+const FetchTimeoutOptionName = "timeout-fetch"
+
+// ErrFetchTimeout is returned when content could not be found before the
+// --timeout-fetch deadline. Unlike the overall request timeout it only
+// covers the fetch itself.
+var ErrFetchTimeout = errors.New("content not found within deadline")
+
+// FetchTimeoutOption returns the --timeout-fetch option declaration, for
+// commands that fetch content on demand.
+func FetchTimeoutOption() cmdkit.Option {
+	return cmdkit.StringOption(FetchTimeoutOptionName, "Max time to wait for content not available locally, e.g. \"30s\". Default: wait forever.")
+}
+
+// FetchContext derives the context fetching work should run under: the
+// request context, bounded by --timeout-fetch when set. The returned
+// cancel func must always be called.
+func FetchContext(req *cmds.Request) (context.Context, context.CancelFunc, error) {
+	timeout, ok := req.Options[FetchTimeoutOptionName].(string)
+	if !ok {
+		ctx, cancel := context.WithCancel(req.Context)
+		return ctx, cancel, nil
+	}
+
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing --%s: %s", FetchTimeoutOptionName, err)
+	}
+	if d <= 0 {
+		return nil, nil, fmt.Errorf("--%s must be positive", FetchTimeoutOptionName)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context, d)
+	return ctx, cancel, nil
+}
+
+// FetchError rewrites err to ErrFetchTimeout when the fetch context ran
+// into its deadline, so every command reports the same error.
+func FetchError(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return ErrFetchTimeout
+	}
+	return err
+}
@@ -23,6 +23,7 @@ func TestROCommands(t *testing.T) {
 		"/cat",
 		"/commands",
 		"/dag",
+		"/dag/export",
 		"/dag/get",
 		"/dag/resolve",
 		"/dns",
@@ -82,21 +83,38 @@ func TestCommands(t *testing.T) {
 		"/bootstrap",
 		"/bootstrap/add",
 		"/bootstrap/add/default",
+		"/bootstrap/health",
 		"/bootstrap/list",
 		"/bootstrap/rm",
 		"/bootstrap/rm/all",
+		"/bootstrap/set",
+		"/bootstrap/set/ls",
+		"/bootstrap/set/rm",
+		"/bootstrap/set/save",
+		"/bootstrap/set/use",
 		"/cat",
 		"/commands",
 		"/config",
 		"/config/edit",
+		"/config/reload",
 		"/config/replace",
 		"/config/show",
 		"/config/profile",
 		"/config/profile/apply",
+		"/config/profile/diff",
 		"/dag",
+		"/dag/export",
 		"/dag/get",
+		"/dag/import",
+		"/dag/index",
+		"/dag/patch",
+		"/dag/patch/add-link",
+		"/dag/patch/rm-link",
+		"/dag/patch/set",
+		"/dag/patch/append",
 		"/dag/put",
 		"/dag/resolve",
+		"/dag/stat",
 		"/dht",
 		"/dht/findpeer",
 		"/dht/findprovs",
@@ -104,24 +122,36 @@ func TestCommands(t *testing.T) {
 		"/dht/provide",
 		"/dht/put",
 		"/dht/query",
+		"/auth",
+		"/auth/token",
+		"/auth/token/create",
+		"/auth/token/list",
+		"/auth/token/revoke",
 		"/diag",
 		"/diag/cmds",
 		"/diag/cmds/clear",
 		"/diag/cmds/set-time",
+		"/diag/net",
 		"/diag/sys",
 		"/dns",
+		"/ethofs",
+		"/ethofs/status",
 		"/file",
 		"/file/ls",
 		"/files",
 		"/files/chcid",
+		"/files/chmod",
 		"/files/cp",
 		"/files/flush",
+		"/files/import-car",
 		"/files/ls",
 		"/files/mkdir",
 		"/files/mv",
 		"/files/read",
 		"/files/rm",
+		"/files/search",
 		"/files/stat",
+		"/files/touch",
 		"/filestore",
 		"/filestore/dups",
 		"/filestore/ls",
@@ -130,10 +160,14 @@ func TestCommands(t *testing.T) {
 		"/get",
 		"/id",
 		"/key",
+		"/key/export",
 		"/key/gen",
+		"/key/import",
 		"/key/list",
+		"/key/migrate-store",
 		"/key/rename",
 		"/key/rm",
+		"/key/rotate",
 		"/log",
 		"/log/level",
 		"/log/ls",
@@ -142,6 +176,7 @@ func TestCommands(t *testing.T) {
 		"/mount",
 		"/name",
 		"/name/publish",
+		"/name/follow",
 		"/name/pubsub",
 		"/name/pubsub/state",
 		"/name/pubsub/subs",
@@ -170,9 +205,17 @@ func TestCommands(t *testing.T) {
 		"/p2p/stream/ls",
 		"/pin",
 		"/pin/add",
+		"/pin/follow",
+		"/pin/follow/add",
+		"/pin/follow/ls",
+		"/pin/follow/rm",
+		"/pin/fsck",
 		"/ping",
 		"/pin/ls",
+		"/pin/queue",
+		"/pin/queue/ls",
 		"/pin/rm",
+		"/pin/status",
 		"/pin/update",
 		"/pin/verify",
 		"/pubsub",
@@ -183,8 +226,12 @@ func TestCommands(t *testing.T) {
 		"/refs",
 		"/refs/local",
 		"/repo",
+		"/routing",
+		"/routing/provide",
+		"/routing/reprovide",
 		"/repo/fsck",
 		"/repo/gc",
+		"/repo/unlock",
 		"/repo/stat",
 		"/repo/verify",
 		"/repo/version",
@@ -194,22 +241,40 @@ func TestCommands(t *testing.T) {
 		"/stats/bitswap",
 		"/stats/bw",
 		"/stats/repo",
+		"/stats/serve-proof",
 		"/swarm",
 		"/swarm/addrs",
 		"/swarm/addrs/listen",
 		"/swarm/addrs/local",
 		"/swarm/connect",
+		"/swarm/connmgr",
+		"/swarm/connmgr/limits",
+		"/swarm/connmgr/protect",
+		"/swarm/connmgr/protected",
+		"/swarm/connmgr/trim",
+		"/swarm/connmgr/unprotect",
 		"/swarm/disconnect",
 		"/swarm/filters",
 		"/swarm/filters/add",
 		"/swarm/filters/rm",
+		"/swarm/peering",
+		"/swarm/peering/add",
+		"/swarm/peering/ls",
+		"/swarm/peering/rm",
 		"/swarm/peers",
+		"/swarm/peerstore",
+		"/swarm/peerstore/export",
+		"/swarm/peerstore/import",
+		"/swarm/resume",
+		"/swarm/suspend",
+		"/swarm/throttle",
 		"/tar",
 		"/tar/add",
 		"/tar/cat",
 		"/update",
 		"/urlstore",
 		"/urlstore/add",
+		"/urlstore/verify",
 		"/version",
 		"/cid",
 		"/cid/format",
@@ -8,13 +8,21 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/ipsn/go-ipfs/core"
 	"github.com/ipsn/go-ipfs/core/commands/cmdenv"
 	"github.com/ipsn/go-ipfs/repo"
 	"github.com/ipsn/go-ipfs/repo/fsrepo"
 
 	"github.com/elgris/jsondiff"
+	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	swarm "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-swarm"
+	mafilter "github.com/ipsn/go-ipfs/gxlibs/github.com/whyrusleeping/multiaddr-filter"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
@@ -32,8 +40,10 @@ type ConfigField struct {
 }
 
 const (
-	configBoolOptionName = "bool"
-	configJSONOptionName = "json"
+	configBoolOptionName      = "bool"
+	configJSONOptionName      = "json"
+	configRevertOptionName    = "revert"
+	configEffectiveOptionName = "effective"
 )
 
 var ConfigCmd = &cmds.Command{
@@ -63,6 +73,7 @@ Set the value of the 'Datastore.Path' key:
 		"show":    configShowCmd,
 		"edit":    configEditCmd,
 		"replace": configReplaceCmd,
+		"reload":  configReloadCmd,
 		"profile": configProfileCmd,
 	},
 	Arguments: []cmdkit.Argument{
@@ -145,8 +156,15 @@ var configShowCmd = &cmds.Command{
 		Tagline: "Output config file contents.",
 		ShortDescription: `
 NOTE: For security reasons, this command will omit your private key. If you would like to make a full backup of your config (private key included), you must copy the config file from your repo.
+
+With --effective, IPFS_CONFIG_* environment overrides are merged over
+the file contents the same way the daemon merges them at startup, and
+the overridden paths are listed under the 'EnvConfigOverrides' key.
 `,
 	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(configEffectiveOptionName, "Merge IPFS_CONFIG_* environment overrides into the output."),
+	},
 	Type: map[string]interface{}{},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		cfgRoot, err := cmdenv.GetConfigRoot(env)
@@ -175,6 +193,12 @@ NOTE: For security reasons, this command will omit your private key. If you woul
 			return err
 		}
 
+		if effective, _ := req.Options[configEffectiveOptionName].(bool); effective {
+			if applied := fsrepo.MergeEnvConfig(cfg); len(applied) > 0 {
+				cfg["EnvConfigOverrides"] = applied
+			}
+		}
+
 		return cmds.EmitOnce(res, &cfg)
 	},
 	Encoders: cmds.EncoderMap{
@@ -301,15 +325,79 @@ Available profiles:
 
 	Subcommands: map[string]*cmds.Command{
 		"apply": configProfileApplyCmd,
+		"diff":  configProfileDiffCmd,
+	},
+}
+
+var configProfileDiffCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show what applying a profile would change.",
+		ShortDescription: `
+Prints the difference between the current config and the config that
+'ipfs config profile apply' would produce, without modifying anything.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("profile", true, false, "The profile to diff against the config."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		profile, ok := config.Profiles[req.Arguments[0]]
+		if !ok {
+			return fmt.Errorf("%s is not a profile", req.Arguments[0])
+		}
+
+		cfgRoot, err := cmdenv.GetConfigRoot(env)
+		if err != nil {
+			return err
+		}
+
+		oldCfg, newCfg, err := transformConfig(cfgRoot, req.Arguments[0], profile.Transform, true)
+		if err != nil {
+			return err
+		}
+
+		oldCfgMap, err := scrubPrivKey(oldCfg)
+		if err != nil {
+			return err
+		}
+
+		newCfgMap, err := scrubPrivKey(newCfg)
+		if err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &ConfigUpdateOutput{
+			OldCfg: oldCfgMap,
+			NewCfg: newCfgMap,
+		})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *ConfigUpdateOutput) error {
+			diff := jsondiff.Compare(out.OldCfg, out.NewCfg)
+			buf := jsondiff.Format(diff)
+
+			w.Write(buf)
+
+			return nil
+		}),
 	},
+	Type: ConfigUpdateOutput{},
 }
 
 var configProfileApplyCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Apply profile to config.",
+		ShortDescription: `
+Applying a profile first backs the current config up inside the repo,
+so the change can be undone later with '--revert'.
+
+When run against a live daemon, changes to Swarm.AddrFilters take
+effect immediately; all other settings apply on the next restart.
+`,
 	},
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption("dry-run", "print difference between the current config and the config that would be generated"),
+		cmdkit.BoolOption(configRevertOptionName, "Restore the config backed up when this profile was last applied."),
 	},
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("profile", true, false, "The profile to apply to the config."),
@@ -326,11 +414,23 @@ var configProfileApplyCmd = &cmds.Command{
 			return err
 		}
 
-		oldCfg, newCfg, err := transformConfig(cfgRoot, req.Arguments[0], profile.Transform, dryRun)
+		var oldCfg, newCfg *config.Config
+		if revert, _ := req.Options[configRevertOptionName].(bool); revert {
+			if dryRun {
+				return errors.New("cannot combine --dry-run with --revert")
+			}
+			oldCfg, newCfg, err = revertProfileConfig(cfgRoot, req.Arguments[0])
+		} else {
+			oldCfg, newCfg, err = transformConfig(cfgRoot, req.Arguments[0], profile.Transform, dryRun)
+		}
 		if err != nil {
 			return err
 		}
 
+		if !dryRun {
+			reloadSwarmFilters(env, oldCfg, newCfg)
+		}
+
 		oldCfgMap, err := scrubPrivKey(oldCfg)
 		if err != nil {
 			return err
@@ -432,6 +532,272 @@ func transformConfig(configRoot string, configName string, transformer config.Tr
 	return oldCfg, newCfg, nil
 }
 
+// revertProfileConfig restores the backup written when the named
+// profile was last applied. The consumed backup file is removed, so
+// repeated apply/revert pairs behave like a stack.
+func revertProfileConfig(configRoot string, name string) (*config.Config, *config.Config, error) {
+	backups, err := filepath.Glob(filepath.Join(configRoot, "config-pre-"+name+"-*"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(backups) == 0 {
+		return nil, nil, fmt.Errorf("no config backup found for profile %q; --revert only works after 'profile apply'", name)
+	}
+
+	// backup names carry a random suffix, pick the newest one
+	var newest string
+	var newestTime time.Time
+	for _, b := range backups {
+		st, err := os.Stat(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		if newest == "" || st.ModTime().After(newestTime) {
+			newest, newestTime = b, st.ModTime()
+		}
+	}
+
+	data, err := ioutil.ReadFile(newest)
+	if err != nil {
+		return nil, nil, err
+	}
+	var newCfg config.Config
+	if err := json.Unmarshal(data, &newCfg); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode config backup %s: %s", newest, err)
+	}
+
+	r, err := fsrepo.Open(configRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	cfg, err := r.Config()
+	if err != nil {
+		return nil, nil, err
+	}
+	oldCfg, err := cfg.Clone()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.SetConfig(&newCfg); err != nil {
+		return nil, nil, err
+	}
+	os.Remove(newest)
+
+	return oldCfg, &newCfg, nil
+}
+
+// reloadSwarmFilters pushes Swarm.AddrFilters changes into the live
+// swarm when a daemon is running. It is best effort: everything else
+// in a profile only takes effect on restart anyway.
+func reloadSwarmFilters(env cmds.Environment, oldCfg, newCfg *config.Config) {
+	n, err := cmdenv.GetNode(env)
+	if err != nil {
+		return
+	}
+	applySwarmFilters(n, oldCfg.Swarm.AddrFilters, newCfg.Swarm.AddrFilters)
+}
+
+// applySwarmFilters diffs two address filter lists and updates the
+// live swarm to match the new one.
+func applySwarmFilters(n *core.IpfsNode, old, new []string) {
+	if n.PeerHost == nil {
+		return
+	}
+	swrm, ok := n.PeerHost.Network().(*swarm.Swarm)
+	if !ok {
+		return
+	}
+
+	oldFilters := make(map[string]bool, len(old))
+	for _, f := range old {
+		oldFilters[f] = true
+	}
+	newFilters := make(map[string]bool, len(new))
+	for _, f := range new {
+		newFilters[f] = true
+	}
+
+	for f := range oldFilters {
+		if newFilters[f] {
+			continue
+		}
+		if mask, err := mafilter.NewMask(f); err == nil {
+			swrm.Filters.Remove(mask)
+		}
+	}
+	for f := range newFilters {
+		if oldFilters[f] {
+			continue
+		}
+		if mask, err := mafilter.NewMask(f); err == nil {
+			swrm.Filters.AddDialFilter(mask)
+		}
+	}
+}
+
+// ConfigReloadOutput reports the outcome of a hot config reload.
+type ConfigReloadOutput struct {
+	Applied       []string
+	RestartNeeded []string
+}
+
+var configReloadCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Reload the config on a running daemon.",
+		ShortDescription: `
+Re-reads the config file (including IPFS_CONFIG_* environment
+overrides) and applies the settings that support hot reload: log
+levels and address filters. Changed settings that cannot be applied
+to a running node are reported as requiring a restart.
+
+Sending SIGHUP to the daemon triggers the same reload.
+`,
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+		cfgRoot, err := cmdenv.GetConfigRoot(env)
+		if err != nil {
+			return err
+		}
+
+		out, err := ReloadConfig(nd, cfgRoot)
+		if err != nil {
+			return err
+		}
+		return cmds.EmitOnce(res, out)
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *ConfigReloadOutput) error {
+			for _, p := range out.Applied {
+				fmt.Fprintf(w, "applied: %s\n", p)
+			}
+			for _, p := range out.RestartNeeded {
+				fmt.Fprintf(w, "requires restart: %s\n", p)
+			}
+			if len(out.Applied) == 0 && len(out.RestartNeeded) == 0 {
+				fmt.Fprintln(w, "config unchanged")
+			}
+			return nil
+		}),
+	},
+	Type: ConfigReloadOutput{},
+}
+
+// ReloadConfig re-reads the config from disk (with environment
+// overrides), applies the settings that support hot reload to the
+// running node, and reports what was applied and what needs a
+// restart. The daemon calls this on SIGHUP.
+func ReloadConfig(nd *core.IpfsNode, cfgRoot string) (*ConfigReloadOutput, error) {
+	newCfg, err := fsrepo.ConfigAt(cfgRoot)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fsrepo.ApplyEnvConfigOverrides(newCfg); err != nil {
+		return nil, err
+	}
+
+	curCfg, err := nd.Repo.Config()
+	if err != nil {
+		return nil, err
+	}
+	oldCfg, err := curCfg.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	oldMap, err := config.ToMap(oldCfg)
+	if err != nil {
+		return nil, err
+	}
+	newMap, err := config.ToMap(newCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ConfigReloadOutput{}
+	for _, p := range diffConfigPaths(oldMap, newMap) {
+		switch p {
+		case "Logging.Level", "Logging.Levels":
+			if err := applyLogLevels(newCfg.Logging); err != nil {
+				return nil, err
+			}
+			out.Applied = append(out.Applied, p)
+		case "Swarm.AddrFilters":
+			applySwarmFilters(nd, oldCfg.Swarm.AddrFilters, newCfg.Swarm.AddrFilters)
+			out.Applied = append(out.Applied, p)
+		default:
+			out.RestartNeeded = append(out.RestartNeeded, p)
+		}
+	}
+
+	// make the new values visible to in-process readers
+	*curCfg = *newCfg
+
+	return out, nil
+}
+
+// applyLogLevels sets the configured log levels on the live logging
+// subsystems; output format and destination changes need a restart.
+func applyLogLevels(cfg config.Logging) error {
+	if cfg.Level != "" {
+		if err := logging.SetLogLevel("*", cfg.Level); err != nil {
+			return err
+		}
+	}
+	for subsystem, level := range cfg.Levels {
+		if err := logging.SetLogLevel(subsystem, level); err != nil {
+			return fmt.Errorf("failed to set log level for %s: %s", subsystem, err)
+		}
+	}
+	return nil
+}
+
+// diffConfigPaths compares two config maps section by section and
+// returns the dotted paths (one or two levels deep) that differ.
+func diffConfigPaths(old, new map[string]interface{}) []string {
+	sections := make(map[string]bool, len(old)+len(new))
+	for k := range old {
+		sections[k] = true
+	}
+	for k := range new {
+		sections[k] = true
+	}
+
+	var changed []string
+	for k := range sections {
+		om, ook := old[k].(map[string]interface{})
+		nm, nok := new[k].(map[string]interface{})
+		if !ook || !nok {
+			if !reflect.DeepEqual(old[k], new[k]) {
+				changed = append(changed, k)
+			}
+			continue
+		}
+
+		fields := make(map[string]bool, len(om)+len(nm))
+		for f := range om {
+			fields[f] = true
+		}
+		for f := range nm {
+			fields[f] = true
+		}
+		for f := range fields {
+			if !reflect.DeepEqual(om[f], nm[f]) {
+				changed = append(changed, k+"."+f)
+			}
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
 func getConfig(r repo.Repo, key string) (*ConfigField, error) {
 	value, err := r.GetConfigKey(key)
 	if err != nil {
@@ -1,10 +1,15 @@
 package dagcmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
+	"time"
 
+	car "github.com/ipsn/go-ipfs/car"
 	"github.com/ipsn/go-ipfs/core/commands/cmdenv"
 	"github.com/ipsn/go-ipfs/core/coredag"
 	"github.com/ipsn/go-ipfs/pin"
@@ -18,6 +23,28 @@ import (
 	mh "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multihash"
 )
 
+const (
+	pinRootsOptionName    = "pin-roots"
+	depthOptionName       = "depth"
+	progressOptionName    = "progress"
+	batchSizeOptionName   = "batch-size"
+	concurrencyOptionName = "concurrency"
+	outputCodecOptionName = "output-codec"
+	strictOptionName      = "strict"
+	canonicalOptionName   = "canonical"
+	prettyOptionName      = "pretty"
+	expandOptionName      = "expand"
+	carVersionOptionName  = "version"
+)
+
+// statProgressInterval is how many blocks 'dag stat --progress' walks
+// between progress updates.
+const statProgressInterval = 1000
+
+// putProgressInterval is how many nodes 'dag put --progress' adds
+// between progress updates.
+const putProgressInterval = 1000
+
 var DagCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Interact with ipld dag objects.",
@@ -32,12 +59,22 @@ to deprecate and replace the existing 'ipfs object' command moving forward.
 		"put":     DagPutCmd,
 		"get":     DagGetCmd,
 		"resolve": DagResolveCmd,
+		"export":  DagExportCmd,
+		"import":  DagImportCmd,
+		"index":   DagIndexCmd,
+		"stat":    DagStatCmd,
+		"patch":   DagPatchCmd,
 	},
 }
 
-// OutputObject is the output type of 'dag put' command
+// OutputObject is the output type of 'dag put' command. Progress
+// events carry an undefined Cid and fill in the counters instead.
 type OutputObject struct {
-	Cid cid.Cid
+	Cid            cid.Cid
+	Nodes          int    `json:",omitempty"`
+	Bytes          uint64 `json:",omitempty"`
+	NodesPerSecond int    `json:",omitempty"`
+	Canonical      []byte `json:",omitempty"`
 }
 
 // ResolveOutput is the output type of 'dag resolve' command
@@ -46,12 +83,29 @@ type ResolveOutput struct {
 	RemPath string
 }
 
+// ImportOutput is the output type of the 'dag import' command
+type ImportOutput struct {
+	Roots []cid.Cid
+}
+
 var DagPutCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Add a dag node to ipfs.",
 		ShortDescription: `
 'ipfs dag put' accepts input from a file or stdin and parses it
 into an object of the specified format.
+
+For bulk ingestion, --batch-size and --concurrency tune how nodes are
+buffered and committed to the blockstore, and --progress emits periodic
+progress events instead of staying silent until the roots are printed.
+
+With --strict, serialized input (--input-enc raw or cbor) is rejected
+unless it is already in canonical form, i.e. re-encoding the decoded
+node reproduces the input bytes exactly. Differences in map key
+ordering or number forms would otherwise silently yield a different
+cid than the one other tools compute for the "same" document. With
+--canonical, the canonicalized block bytes are written out instead of
+the cid.
 `,
 	},
 	Arguments: []cmdkit.Argument{
@@ -62,6 +116,12 @@ into an object of the specified format.
 		cmdkit.StringOption("input-enc", "Format that the input object will be.").WithDefault("json"),
 		cmdkit.BoolOption("pin", "Pin this object when adding."),
 		cmdkit.StringOption("hash", "Hash function to use").WithDefault(""),
+		cmdkit.IntOption(batchSizeOptionName, "Maximum number of nodes buffered before a batch commit (0 uses the default)."),
+		cmdkit.IntOption(concurrencyOptionName, "Maximum number of batch commits in flight (0 uses the default)."),
+		cmdkit.BoolOption(progressOptionName, "p", "Emit progress events while adding nodes."),
+		cmdkit.BoolOption(strictOptionName, "Reject serialized input that is not in canonical form."),
+		cmdkit.BoolOption(canonicalOptionName, "Write the canonicalized block bytes instead of the cid."),
+		cmdenv.CidBaseOption(),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		nd, err := cmdenv.GetNode(env)
@@ -73,6 +133,19 @@ into an object of the specified format.
 		format, _ := req.Options["format"].(string)
 		hash, _ := req.Options["hash"].(string)
 		dopin, _ := req.Options["pin"].(bool)
+		batchSize, _ := req.Options[batchSizeOptionName].(int)
+		concurrency, _ := req.Options[concurrencyOptionName].(int)
+		progress, _ := req.Options[progressOptionName].(bool)
+		strict, _ := req.Options[strictOptionName].(bool)
+		canonical, _ := req.Options[canonicalOptionName].(bool)
+
+		if strict {
+			switch ienc {
+			case "raw", "cbor":
+			default:
+				return fmt.Errorf("--%s requires a serialized input encoding (--input-enc raw or cbor)", strictOptionName)
+			}
+		}
 
 		// mhType tells inputParser which hash should be used. MaxUint64 means 'use
 		// default hash' (sha256 for cbor, sha1 for git..)
@@ -86,20 +159,48 @@ into an object of the specified format.
 			}
 		}
 
+		// admission control against the hosting contract quota
+		var uploadSize uint64
+		if size, err := req.Files.Size(); err == nil && size > 0 {
+			uploadSize = uint64(size)
+		}
+		if err := nd.EthoFS.CheckQuota(req.Context, uploadSize); err != nil {
+			return err
+		}
+
+		var bopts []ipld.BatchOption
+		if batchSize > 0 {
+			bopts = append(bopts, ipld.MaxNodesBatchOption(batchSize))
+		}
+		if concurrency > 0 {
+			bopts = append(bopts, ipld.ParallelCommitsBatchOption(concurrency))
+		}
+
 		cids := cid.NewSet()
-		b := ipld.NewBatch(req.Context, nd.DAG)
+		b := ipld.NewBatch(req.Context, nd.DAG, bopts...)
 
 		if dopin {
 			defer nd.Blockstore.PinLock().Unlock()
 		}
 
+		start := time.Now()
+		var numNodes int
+		var numBytes uint64
+
 		it := req.Files.Entries()
 		for it.Next() {
 			file := files.FileFromEntry(it)
 			if file == nil {
 				return fmt.Errorf("expected a regular file")
 			}
-			nds, err := coredag.ParseInputs(ienc, format, file, mhType, -1)
+			var input io.Reader = file
+			var inbuf *bytes.Buffer
+			if strict {
+				inbuf = new(bytes.Buffer)
+				input = io.TeeReader(file, inbuf)
+			}
+
+			nds, err := coredag.ParseInputs(ienc, format, input, mhType, -1)
 			if err != nil {
 				return err
 			}
@@ -107,16 +208,42 @@ into an object of the specified format.
 				return fmt.Errorf("no node returned from ParseInputs")
 			}
 
+			if strict {
+				if len(nds) != 1 {
+					return fmt.Errorf("--%s requires exactly one node per input file", strictOptionName)
+				}
+				if !bytes.Equal(inbuf.Bytes(), nds[0].RawData()) {
+					return fmt.Errorf("input is not canonical %s: re-encoding changed the bytes (check map key ordering and number forms)", format)
+				}
+			}
+
 			for _, nd := range nds {
 				err := b.Add(req.Context, nd)
 				if err != nil {
 					return err
 				}
+
+				numNodes++
+				numBytes += uint64(len(nd.RawData()))
+				if progress && numNodes%putProgressInterval == 0 {
+					rate := int(float64(numNodes) / time.Since(start).Seconds())
+					if err := res.Emit(&OutputObject{
+						Nodes:          numNodes,
+						Bytes:          numBytes,
+						NodesPerSecond: rate,
+					}); err != nil {
+						return err
+					}
+				}
 			}
 
 			cid := nds[0].Cid()
 			cids.Add(cid)
-			if err := res.Emit(&OutputObject{Cid: cid}); err != nil {
+			out := &OutputObject{Cid: cid}
+			if canonical {
+				out.Canonical = nds[0].RawData()
+			}
+			if err := res.Emit(out); err != nil {
 				return err
 			}
 		}
@@ -127,6 +254,7 @@ into an object of the specified format.
 		if err := b.Commit(); err != nil {
 			return err
 		}
+		nd.EthoFS.ChargeQuota(uploadSize)
 
 		if dopin {
 			cids.ForEach(func(c cid.Cid) error {
@@ -144,7 +272,19 @@ into an object of the specified format.
 	Type: OutputObject{},
 	Encoders: cmds.EncoderMap{
 		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *OutputObject) error {
-			fmt.Fprintln(w, out.Cid.String())
+			if !out.Cid.Defined() {
+				fmt.Fprintf(w, "\radded %d nodes (%d bytes, %d nodes/s)...", out.Nodes, out.Bytes, out.NodesPerSecond)
+				return nil
+			}
+			if len(out.Canonical) > 0 {
+				_, err := w.Write(out.Canonical)
+				return err
+			}
+			enc, err := cmdenv.CidEncoder(req)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, enc(out.Cid))
 			return nil
 		}),
 	},
@@ -156,43 +296,255 @@ var DagGetCmd = &cmds.Command{
 		ShortDescription: `
 'ipfs dag get' fetches a dag node from ipfs and prints it out in the specified
 format.
+
+With --depth, the nodes of the subgraph below the resolved node are
+streamed in depth first order instead, up to the given number of link
+hops (0 means only the node itself).
+
+With --output-codec, the node is serialized with the given codec
+(dag-json, dag-cbor or raw) and the encoded bytes are streamed
+directly, instead of being buffered as a single response object. Use
+this when getting nodes too large to hold a second serialized copy in
+memory.
+
+With --pretty, the node is rendered as indented dag-json for manual
+inspection: links are annotated with the size the parent records for
+them, and --expand inlines the linked nodes themselves up to the given
+number of link hops.
 `,
 	},
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("ref", true, false, "The object to get").EnableStdin(),
 	},
+	Options: []cmdkit.Option{
+		cmdkit.IntOption(depthOptionName, "Also emit all nodes up to this many link hops below the resolved node.").WithDefault(-1),
+		cmdkit.StringOption(outputCodecOptionName, "Serialize the node with this codec (dag-json, dag-cbor, raw) and stream the bytes."),
+		cmdkit.BoolOption(prettyOptionName, "Render indented dag-json with annotated links."),
+		cmdkit.IntOption(expandOptionName, "With --pretty, also inline child nodes up to this many link hops."),
+		cmdenv.FetchTimeoutOption(),
+	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		nd, err := cmdenv.GetNode(env)
 		if err != nil {
 			return err
 		}
 
+		depth, _ := req.Options[depthOptionName].(int)
+
 		p, err := path.ParsePath(req.Arguments[0])
 		if err != nil {
 			return err
 		}
 
-		lastCid, rem, err := nd.Resolver.ResolveToLastNode(req.Context, p)
+		ctx, cancel, err := cmdenv.FetchContext(req)
 		if err != nil {
 			return err
 		}
-		obj, err := nd.DAG.Get(req.Context, lastCid)
+		defer cancel()
+
+		lastCid, rem, err := nd.Resolver.ResolveToLastNode(ctx, p)
 		if err != nil {
-			return err
+			return cmdenv.FetchError(ctx, err)
+		}
+		obj, err := nd.DAG.Get(ctx, lastCid)
+		if err != nil {
+			return cmdenv.FetchError(ctx, err)
 		}
 
-		var out interface{} = obj
-		if len(rem) > 0 {
-			final, _, err := obj.Resolve(rem)
+		codec, _ := req.Options[outputCodecOptionName].(string)
+
+		pretty, _ := req.Options[prettyOptionName].(bool)
+		expand, _ := req.Options[expandOptionName].(int)
+		if expand > 0 && !pretty {
+			return fmt.Errorf("--%s only makes sense together with --%s", expandOptionName, prettyOptionName)
+		}
+		if pretty {
+			if codec != "" {
+				return fmt.Errorf("cannot use --%s together with --%s", prettyOptionName, outputCodecOptionName)
+			}
+			if depth >= 0 {
+				return fmt.Errorf("cannot use --%s together with --%s", prettyOptionName, depthOptionName)
+			}
+
+			var out interface{} = obj
+			if len(rem) > 0 {
+				final, _, err := obj.Resolve(rem)
+				if err != nil {
+					return err
+				}
+				out = final
+			}
+
+			rendered, err := prettyRender(ctx, nd.DAG, out, linkSizes(obj), expand)
 			if err != nil {
 				return err
 			}
-			out = final
+			data, err := json.MarshalIndent(rendered, "", "  ")
+			if err != nil {
+				return err
+			}
+			return res.Emit(bytes.NewReader(append(data, '\n')))
+		}
+
+		if codec != "" {
+			if depth >= 0 {
+				return fmt.Errorf("cannot use --%s together with --%s", outputCodecOptionName, depthOptionName)
+			}
+			switch codec {
+			case "raw", "dag-cbor":
+				if len(rem) > 0 {
+					return fmt.Errorf("cannot serialize a path inside a block as %s: /%s", codec, path.Join(rem))
+				}
+				if codec == "dag-cbor" && obj.Cid().Type() != cid.DagCBOR {
+					return fmt.Errorf("cannot serialize a %s node as dag-cbor", cid.CodecToStr[obj.Cid().Type()])
+				}
+				// the block bytes are the serialization, stream them as is
+				return res.Emit(bytes.NewReader(obj.RawData()))
+			case "dag-json":
+				var out interface{} = obj
+				if len(rem) > 0 {
+					final, _, err := obj.Resolve(rem)
+					if err != nil {
+						return err
+					}
+					out = final
+				}
+				// encode straight into the response stream instead of
+				// round-tripping through the emitter's object marshaling
+				piper, pipew := io.Pipe()
+				go func() {
+					pipew.CloseWithError(json.NewEncoder(pipew).Encode(out))
+				}()
+				return res.Emit(piper)
+			default:
+				return fmt.Errorf("unknown output codec: %s", codec)
+			}
 		}
-		return cmds.EmitOnce(res, &out)
+
+		if depth < 0 {
+			var out interface{} = obj
+			if len(rem) > 0 {
+				final, _, err := obj.Resolve(rem)
+				if err != nil {
+					return err
+				}
+				out = final
+			}
+			return cmds.EmitOnce(res, &out)
+		}
+
+		if len(rem) > 0 {
+			return fmt.Errorf("cannot use --depth with a path inside a block: /%s", path.Join(rem))
+		}
+
+		// stream the subgraph depth first, emitting every node once
+		seen := cid.NewSet()
+		var walk func(obj ipld.Node, depth int) error
+		walk = func(obj ipld.Node, depth int) error {
+			if !seen.Visit(obj.Cid()) {
+				return nil
+			}
+			var out interface{} = obj
+			if err := res.Emit(&out); err != nil {
+				return err
+			}
+			if depth == 0 {
+				return nil
+			}
+			for _, l := range obj.Links() {
+				child, err := nd.DAG.Get(ctx, l.Cid)
+				if err != nil {
+					return cmdenv.FetchError(ctx, err)
+				}
+				if err := walk(child, depth-1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return walk(obj, depth)
 	},
 }
 
+// linkSizes collects the sizes a node records for its links, keyed by
+// child cid, for annotating pretty output.
+func linkSizes(obj ipld.Node) map[string]uint64 {
+	sizes := make(map[string]uint64)
+	for _, l := range obj.Links() {
+		if l.Size > 0 {
+			sizes[l.Cid.String()] = l.Size
+		}
+	}
+	return sizes
+}
+
+// prettyRender converts v (a node, or a value resolved inside one) to
+// a plain json tree with every link annotated by prettyAnnotate.
+func prettyRender(ctx context.Context, ng ipld.NodeGetter, v interface{}, sizes map[string]uint64, expand int) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain interface{}
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return nil, err
+	}
+
+	return prettyAnnotate(ctx, ng, plain, sizes, expand)
+}
+
+// prettyAnnotate walks a plain json tree, adding the recorded size to
+// every link object and, up to expand link hops, inlining the linked
+// node under a "node" key.
+func prettyAnnotate(ctx context.Context, ng ipld.NodeGetter, v interface{}, sizes map[string]uint64, expand int) (interface{}, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if s, ok := val["/"].(string); ok && len(val) == 1 {
+			if c, err := cid.Decode(s); err == nil {
+				ann := map[string]interface{}{"/": s}
+				if size, ok := sizes[s]; ok {
+					ann["size"] = size
+				}
+				if expand > 0 {
+					child, err := ng.Get(ctx, c)
+					if err != nil {
+						return nil, cmdenv.FetchError(ctx, err)
+					}
+					sub, err := prettyRender(ctx, ng, child, linkSizes(child), expand-1)
+					if err != nil {
+						return nil, err
+					}
+					ann["node"] = sub
+				}
+				return ann, nil
+			}
+		}
+
+		out := make(map[string]interface{}, len(val))
+		for k, c := range val {
+			sub, err := prettyAnnotate(ctx, ng, c, sizes, expand)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = sub
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, c := range val {
+			sub, err := prettyAnnotate(ctx, ng, c, sizes, expand)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = sub
+		}
+		return out, nil
+	}
+
+	return v, nil
+}
+
 // DagResolveCmd returns address of highest block within a path and a path remainder
 var DagResolveCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
@@ -204,6 +556,9 @@ var DagResolveCmd = &cmds.Command{
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("ref", true, false, "The path to resolve").EnableStdin(),
 	},
+	Options: []cmdkit.Option{
+		cmdenv.CidBaseOption(),
+	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		nd, err := cmdenv.GetNode(env)
 		if err != nil {
@@ -227,7 +582,11 @@ var DagResolveCmd = &cmds.Command{
 	},
 	Encoders: cmds.EncoderMap{
 		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *ResolveOutput) error {
-			p := out.Cid.String()
+			enc, err := cmdenv.CidEncoder(req)
+			if err != nil {
+				return err
+			}
+			p := enc(out.Cid)
 			if out.RemPath != "" {
 				p = path.Join([]string{p, out.RemPath})
 			}
@@ -238,3 +597,287 @@ var DagResolveCmd = &cmds.Command{
 	},
 	Type: ResolveOutput{},
 }
+
+// DagStat is the output type of the 'dag stat' command
+type DagStat struct {
+	NumBlocks int
+	Size      uint64
+	MaxDepth  int
+
+	// InProgress marks intermediate progress updates; the last
+	// emitted stat is always a final one.
+	InProgress bool `json:",omitempty"`
+}
+
+// DagStatCmd walks a dag and reports its size and shape
+var DagStatCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Report block count, cumulative size and depth of a dag.",
+		ShortDescription: `
+'ipfs dag stat' walks the entire dag below the given root and reports
+how many blocks it holds, their cumulative size in bytes and the
+maximum link depth. Blocks referenced more than once are counted once.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("root", true, false, "CID of the root of the dag to stat").EnableStdin(),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(progressOptionName, "p", "Emit progress updates while walking large dags."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		progress, _ := req.Options[progressOptionName].(bool)
+
+		p, err := path.ParsePath(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		lastCid, rem, err := nd.Resolver.ResolveToLastNode(req.Context, p)
+		if err != nil {
+			return err
+		}
+		if len(rem) > 0 {
+			return fmt.Errorf("cannot stat a path inside a block: /%s", path.Join(rem))
+		}
+
+		stat := &DagStat{}
+		seen := cid.NewSet()
+		var walk func(c cid.Cid, depth int) error
+		walk = func(c cid.Cid, depth int) error {
+			if !seen.Visit(c) {
+				return nil
+			}
+
+			obj, err := nd.DAG.Get(req.Context, c)
+			if err != nil {
+				return err
+			}
+
+			stat.NumBlocks++
+			stat.Size += uint64(len(obj.RawData()))
+			if depth > stat.MaxDepth {
+				stat.MaxDepth = depth
+			}
+
+			if progress && stat.NumBlocks%statProgressInterval == 0 {
+				stat.InProgress = true
+				if err := res.Emit(stat); err != nil {
+					return err
+				}
+			}
+
+			for _, l := range obj.Links() {
+				if err := walk(l.Cid, depth+1); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if err := walk(lastCid, 0); err != nil {
+			return err
+		}
+
+		stat.InProgress = false
+		return res.Emit(stat)
+	},
+	Type: DagStat{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *DagStat) error {
+			if out.InProgress {
+				fmt.Fprintf(w, "\rfetched %d blocks (%d bytes)...", out.NumBlocks, out.Size)
+				return nil
+			}
+			fmt.Fprintf(w, "\rBlocks:    %d\nSize:      %d\nMax depth: %d\n", out.NumBlocks, out.Size, out.MaxDepth)
+			return nil
+		}),
+	},
+}
+
+// DagExportCmd streams the dag rooted at a cid as a car file
+var DagExportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Stream the dag rooted at a cid as a car file.",
+		ShortDescription: `
+'ipfs dag export' writes the entire dag below the given root as a
+CAR (Content Addressable aRchive) stream on stdout. The output can
+be moved between nodes offline and loaded with 'ipfs dag import'.
+
+By default a CARv1 stream is written. With --version=2 the output is
+a CARv2 file with an embedded index, so blocks can later be read at
+random without scanning the whole file.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("root", true, false, "CID of the root of the dag to export").EnableStdin(),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.IntOption(carVersionOptionName, "Car format version to write (1 or 2).").WithDefault(1),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		p, err := path.ParsePath(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		lastCid, rem, err := nd.Resolver.ResolveToLastNode(req.Context, p)
+		if err != nil {
+			return err
+		}
+		if len(rem) > 0 {
+			return fmt.Errorf("cannot export a path inside a block: /%s", path.Join(rem))
+		}
+
+		version, _ := req.Options[carVersionOptionName].(int)
+		if version != 1 && version != 2 {
+			return fmt.Errorf("unsupported car version: %d", version)
+		}
+
+		piper, pipew := io.Pipe()
+		go func() {
+			if version == 2 {
+				pipew.CloseWithError(car.ExportV2(req.Context, nd.DAG, []cid.Cid{lastCid}, pipew))
+			} else {
+				pipew.CloseWithError(car.Export(req.Context, nd.DAG, []cid.Cid{lastCid}, pipew))
+			}
+		}()
+
+		return res.Emit(piper)
+	},
+}
+
+// DagImportCmd imports a car stream into the local blockstore
+var DagImportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Import a car file into ipfs.",
+		ShortDescription: `
+'ipfs dag import' reads a CAR (Content Addressable aRchive) stream,
+adds every block it contains to the local blockstore and returns the
+root cids listed in the car header. Both CARv1 streams and CARv2
+files are accepted; a CARv2 index is skipped, as every block is
+checked against its cid before it is stored anyway.
+
+By default the roots are pinned recursively once the import finishes;
+pass --pin-roots=false to import without pinning.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.FileArg("car", true, true, "The car file to import.").EnableStdin(),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(pinRootsOptionName, "Pin the root cids listed in the car header.").WithDefault(true),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		dopin, _ := req.Options[pinRootsOptionName].(bool)
+
+		// admission control against the hosting contract quota
+		var uploadSize uint64
+		if size, err := req.Files.Size(); err == nil && size > 0 {
+			uploadSize = uint64(size)
+		}
+		if err := nd.EthoFS.CheckQuota(req.Context, uploadSize); err != nil {
+			return err
+		}
+
+		if dopin {
+			defer nd.Blockstore.PinLock().Unlock()
+		}
+
+		var allRoots []cid.Cid
+		it := req.Files.Entries()
+		for it.Next() {
+			file := files.FileFromEntry(it)
+			if file == nil {
+				return fmt.Errorf("expected a regular file")
+			}
+
+			roots, err := car.Import(nd.Blocks, file)
+			if err != nil {
+				return err
+			}
+			allRoots = append(allRoots, roots...)
+		}
+		if it.Err() != nil {
+			return it.Err()
+		}
+		nd.EthoFS.ChargeQuota(uploadSize)
+
+		if dopin {
+			for _, c := range allRoots {
+				// the roots have to be complete in the blockstore for a
+				// recursive pin to make sense
+				if _, err := nd.DAG.Get(req.Context, c); err != nil {
+					return fmt.Errorf("car root %s cannot be pinned: %s", c, err)
+				}
+				nd.Pinning.PinWithMode(c, pin.Recursive)
+			}
+			if err := nd.Pinning.Flush(); err != nil {
+				return err
+			}
+		}
+
+		return cmds.EmitOnce(res, &ImportOutput{Roots: allRoots})
+	},
+	Type: ImportOutput{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *ImportOutput) error {
+			for _, c := range out.Roots {
+				fmt.Fprintf(w, "imported %s\n", c)
+			}
+			return nil
+		}),
+	},
+}
+
+// DagIndexCmd generates a detached index for a car file
+var DagIndexCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Generate an index for a car file.",
+		ShortDescription: `
+'ipfs dag index' scans a car file (either version) and writes a
+detached index on stdout, mapping every block to the offset of its
+section within the CARv1 payload. The index allows random access
+into a large car file without scanning it. It is the same format a
+CARv2 file produced by 'ipfs dag export --version=2' embeds.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.FileArg("car", true, false, "The car file to index.").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		it := req.Files.Entries()
+		if !it.Next() {
+			if it.Err() != nil {
+				return it.Err()
+			}
+			return fmt.Errorf("expected a car file")
+		}
+		file := files.FileFromEntry(it)
+		if file == nil {
+			return fmt.Errorf("expected a regular file")
+		}
+
+		idx, err := car.BuildIndex(file)
+		if err != nil {
+			return err
+		}
+
+		return res.Emit(bytes.NewReader(idx))
+	},
+}
@@ -0,0 +1,451 @@
+package dagcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ipsn/go-ipfs/core"
+	"github.com/ipsn/go-ipfs/core/commands/cmdenv"
+	"github.com/ipsn/go-ipfs/dagutils"
+
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+	cbornode "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-cbor"
+	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
+	dag "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-merkledag"
+	path "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-path"
+)
+
+// DagPatchCmd mutates dag nodes server-side and reports the new root,
+// the ipld counterpart to 'ipfs object patch'.
+var DagPatchCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Mutate a dag node and return the new root.",
+		ShortDescription: `
+'ipfs dag patch' applies a mutation to a dag node and prints the cid
+of the resulting root, without the client having to fetch, modify and
+re-put the object.
+
+'add-link' and 'rm-link' work on both dag-pb and dag-cbor nodes; 'set'
+and 'append' edit fields and arrays of dag-cbor nodes (for dag-pb data
+see 'ipfs object patch set-data' and 'append-data').
+`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"add-link": dagPatchAddLinkCmd,
+		"rm-link":  dagPatchRmLinkCmd,
+		"set":      dagPatchSetCmd,
+		"append":   dagPatchAppendCmd,
+	},
+}
+
+var dagPatchAddLinkCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Add a link to a dag node.",
+		ShortDescription: `
+Links the given child under a path inside the root node. On dag-pb
+nodes intermediate nodes along the path are traversed like
+'ipfs object patch add-link'; on dag-cbor nodes the path names the
+field or array index that will hold the link.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("root", true, false, "The node to modify."),
+		cmdkit.StringArg("path", true, false, "Path at which to place the link."),
+		cmdkit.StringArg("child", true, false, "The node the link points to."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		root, err := dagPatchResolve(req.Context, nd, req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		child, err := dagPatchResolve(req.Context, nd, req.Arguments[2])
+		if err != nil {
+			return err
+		}
+
+		var newCid cid.Cid
+		switch root := root.(type) {
+		case *dag.ProtoNode:
+			e := dagutils.NewDagEditor(root, nd.DAG)
+			if err := e.InsertNodeAtPath(req.Context, req.Arguments[1], child, nil); err != nil {
+				return err
+			}
+			nnode, err := e.Finalize(req.Context, nd.DAG)
+			if err != nil {
+				return err
+			}
+			newCid = nnode.Cid()
+		case *cbornode.Node:
+			newCid, err = cborPatch(req.Context, nd.DAG, root, req.Arguments[1], cborSetOp(child.Cid()))
+			if err != nil {
+				return err
+			}
+		default:
+			return errNotPatchable(root)
+		}
+
+		return cmds.EmitOnce(res, &OutputObject{Cid: newCid})
+	},
+	Type:     OutputObject{},
+	Encoders: dagPatchEncoders,
+}
+
+var dagPatchRmLinkCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Remove a link from a dag node.",
+		ShortDescription: `
+Removes the link, field or array element named by the path from the
+root node.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("root", true, false, "The node to modify."),
+		cmdkit.StringArg("path", true, false, "Path of the link to remove."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		root, err := dagPatchResolve(req.Context, nd, req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		var newCid cid.Cid
+		switch root := root.(type) {
+		case *dag.ProtoNode:
+			e := dagutils.NewDagEditor(root, nd.DAG)
+			if err := e.RmLink(req.Context, req.Arguments[1]); err != nil {
+				return err
+			}
+			nnode, err := e.Finalize(req.Context, nd.DAG)
+			if err != nil {
+				return err
+			}
+			newCid = nnode.Cid()
+		case *cbornode.Node:
+			newCid, err = cborPatch(req.Context, nd.DAG, root, req.Arguments[1], cborRmOp)
+			if err != nil {
+				return err
+			}
+		default:
+			return errNotPatchable(root)
+		}
+
+		return cmds.EmitOnce(res, &OutputObject{Cid: newCid})
+	},
+	Type:     OutputObject{},
+	Encoders: dagPatchEncoders,
+}
+
+var dagPatchSetCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Set a field of a dag-cbor node.",
+		ShortDescription: `
+Sets the field or array element named by the path to the given value.
+The value is parsed as JSON; input that is not valid JSON is taken as
+a plain string, so quoting scalars is optional.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("root", true, false, "The node to modify."),
+		cmdkit.StringArg("path", true, false, "Path of the field to set."),
+		cmdkit.StringArg("value", true, false, "The value to set, as JSON."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		return dagPatchCborValue(req, res, env, func(val interface{}) cborPatchOp {
+			return cborSetOp(val)
+		})
+	},
+	Type:     OutputObject{},
+	Encoders: dagPatchEncoders,
+}
+
+var dagPatchAppendCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Append a value to an array of a dag-cbor node.",
+		ShortDescription: `
+Appends the given value to the array named by the path. The value is
+parsed as JSON, falling back to a plain string.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("root", true, false, "The node to modify."),
+		cmdkit.StringArg("path", true, false, "Path of the array to append to."),
+		cmdkit.StringArg("value", true, false, "The value to append, as JSON."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		return dagPatchCborValue(req, res, env, cborAppendOp)
+	},
+	Type:     OutputObject{},
+	Encoders: dagPatchEncoders,
+}
+
+var dagPatchEncoders = cmds.EncoderMap{
+	cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *OutputObject) error {
+		fmt.Fprintln(w, out.Cid.String())
+		return nil
+	}),
+}
+
+func errNotPatchable(nd ipld.Node) error {
+	return fmt.Errorf("%s is neither a dag-pb nor a dag-cbor node", nd.Cid())
+}
+
+// dagPatchResolve resolves a ref to a whole node; patches address
+// their target inside the node through the separate path argument.
+func dagPatchResolve(ctx context.Context, nd *core.IpfsNode, ref string) (ipld.Node, error) {
+	p, err := path.ParsePath(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	c, rem, err := nd.Resolver.ResolveToLastNode(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if len(rem) > 0 {
+		return nil, fmt.Errorf("%s does not reference a whole node", ref)
+	}
+
+	return nd.DAG.Get(ctx, c)
+}
+
+// dagPatchCborValue runs the shared part of 'set' and 'append': both
+// take a value argument and only apply to dag-cbor nodes.
+func dagPatchCborValue(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment, mkop func(val interface{}) cborPatchOp) error {
+	nd, err := cmdenv.GetNode(env)
+	if err != nil {
+		return err
+	}
+
+	root, err := dagPatchResolve(req.Context, nd, req.Arguments[0])
+	if err != nil {
+		return err
+	}
+	cbnd, ok := root.(*cbornode.Node)
+	if !ok {
+		return fmt.Errorf("%s is not a dag-cbor node", root.Cid())
+	}
+
+	var val interface{}
+	if err := json.Unmarshal([]byte(req.Arguments[2]), &val); err != nil {
+		// not valid JSON, accept it as a bare string
+		val = req.Arguments[2]
+	}
+
+	newCid, err := cborPatch(req.Context, nd.DAG, cbnd, req.Arguments[1], mkop(val))
+	if err != nil {
+		return err
+	}
+
+	return cmds.EmitOnce(res, &OutputObject{Cid: newCid})
+}
+
+// cborPatchOp applies one mutation to the entry named key of a
+// decoded cbor container, returning the replacement container.
+type cborPatchOp func(parent interface{}, key string) (interface{}, error)
+
+// cborPatch decodes a dag-cbor node, applies op to the value at pth
+// inside it, re-encodes it with the node's original hash function and
+// stores the result, returning the new cid.
+func cborPatch(ctx context.Context, dserv ipld.DAGService, nd *cbornode.Node, pth string, op cborPatchOp) (cid.Cid, error) {
+	var obj interface{}
+	if err := cbornode.DecodeInto(nd.RawData(), &obj); err != nil {
+		return cid.Undef, err
+	}
+
+	var segs []string
+	for _, s := range strings.Split(pth, "/") {
+		if s != "" {
+			segs = append(segs, s)
+		}
+	}
+	if len(segs) == 0 {
+		return cid.Undef, fmt.Errorf("path must name a field inside the node")
+	}
+
+	obj, err := cborMutate(obj, segs, op)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	nnode, err := cbornode.WrapObject(obj, nd.Cid().Prefix().MhType, -1)
+	if err != nil {
+		return cid.Undef, err
+	}
+	if err := dserv.Add(ctx, nnode); err != nil {
+		return cid.Undef, err
+	}
+	return nnode.Cid(), nil
+}
+
+// cborMutate walks obj down to the parent of the last path segment
+// and applies op there, handing modified containers back up so the
+// returned object is the new root.
+func cborMutate(obj interface{}, segs []string, op cborPatchOp) (interface{}, error) {
+	if len(segs) == 1 {
+		return op(obj, segs[0])
+	}
+
+	switch o := obj.(type) {
+	case map[interface{}]interface{}:
+		child, ok := o[segs[0]]
+		if !ok {
+			return nil, fmt.Errorf("no such field: %s", segs[0])
+		}
+		nchild, err := cborMutate(child, segs[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		o[segs[0]] = nchild
+		return o, nil
+	case map[string]interface{}:
+		child, ok := o[segs[0]]
+		if !ok {
+			return nil, fmt.Errorf("no such field: %s", segs[0])
+		}
+		nchild, err := cborMutate(child, segs[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		o[segs[0]] = nchild
+		return o, nil
+	case []interface{}:
+		i, err := cborIndex(segs[0], len(o))
+		if err != nil {
+			return nil, err
+		}
+		nchild, err := cborMutate(o[i], segs[1:], op)
+		if err != nil {
+			return nil, err
+		}
+		o[i] = nchild
+		return o, nil
+	default:
+		return nil, fmt.Errorf("cannot traverse %T at %q", obj, segs[0])
+	}
+}
+
+// cborSetOp sets the entry named key to val; on arrays an index equal
+// to the length extends the array by one.
+func cborSetOp(val interface{}) cborPatchOp {
+	return func(parent interface{}, key string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[interface{}]interface{}:
+			p[key] = val
+			return p, nil
+		case map[string]interface{}:
+			p[key] = val
+			return p, nil
+		case []interface{}:
+			i, err := cborIndex(key, len(p)+1)
+			if err != nil {
+				return nil, err
+			}
+			if i == len(p) {
+				return append(p, val), nil
+			}
+			p[i] = val
+			return p, nil
+		default:
+			return nil, fmt.Errorf("cannot set %q on a %T", key, parent)
+		}
+	}
+}
+
+func cborRmOp(parent interface{}, key string) (interface{}, error) {
+	switch p := parent.(type) {
+	case map[interface{}]interface{}:
+		if _, ok := p[key]; !ok {
+			return nil, fmt.Errorf("no such field: %s", key)
+		}
+		delete(p, key)
+		return p, nil
+	case map[string]interface{}:
+		if _, ok := p[key]; !ok {
+			return nil, fmt.Errorf("no such field: %s", key)
+		}
+		delete(p, key)
+		return p, nil
+	case []interface{}:
+		i, err := cborIndex(key, len(p))
+		if err != nil {
+			return nil, err
+		}
+		return append(p[:i], p[i+1:]...), nil
+	default:
+		return nil, fmt.Errorf("cannot remove %q from a %T", key, parent)
+	}
+}
+
+// cborAppendOp appends val to the array named key; a missing field
+// becomes a one-element array.
+func cborAppendOp(val interface{}) cborPatchOp {
+	return func(parent interface{}, key string) (interface{}, error) {
+		set := func(cur interface{}) (interface{}, error) {
+			if cur == nil {
+				return []interface{}{val}, nil
+			}
+			arr, ok := cur.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s is not an array", key)
+			}
+			return append(arr, val), nil
+		}
+
+		switch p := parent.(type) {
+		case map[interface{}]interface{}:
+			narr, err := set(p[key])
+			if err != nil {
+				return nil, err
+			}
+			p[key] = narr
+			return p, nil
+		case map[string]interface{}:
+			narr, err := set(p[key])
+			if err != nil {
+				return nil, err
+			}
+			p[key] = narr
+			return p, nil
+		case []interface{}:
+			i, err := cborIndex(key, len(p))
+			if err != nil {
+				return nil, err
+			}
+			narr, err := set(p[i])
+			if err != nil {
+				return nil, err
+			}
+			p[i] = narr
+			return p, nil
+		default:
+			return nil, fmt.Errorf("cannot append to %q on a %T", key, parent)
+		}
+	}
+}
+
+func cborIndex(key string, length int) (int, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an array index", key)
+	}
+	if i < 0 || i >= length {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
@@ -13,5 +13,6 @@ var DiagCmd = &cmds.Command{
 	Subcommands: map[string]*cmds.Command{
 		"sys":  sysDiagCmd,
 		"cmds": ActiveReqsCmd,
+		"net":  diagNetCmd,
 	},
 }
@@ -0,0 +1,210 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
+
+	humanize "github.com/dustin/go-humanize"
+	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+)
+
+const (
+	diagNetWatchOptionName    = "watch"
+	diagNetIntervalOptionName = "interval"
+)
+
+// DiagNetConn describes one swarm connection in a diagnostic report.
+type DiagNetConn struct {
+	Peer      string
+	Addr      string
+	Transport string
+	Direction string
+	Age       string
+	Streams   map[string]int // open streams by protocol
+	BytesIn   uint64
+	BytesOut  uint64
+}
+
+// DiagNetOutput is the result of 'ipfs diag net'.
+type DiagNetOutput struct {
+	Conns        []DiagNetConn
+	TotalConns   int
+	TotalStreams int
+}
+
+var diagNetCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show a summary of all swarm connections.",
+		ShortDescription: `
+'ipfs diag net' prints a netstat-like overview of every open connection:
+remote peer, transport, direction, age, open streams by protocol, and
+bandwidth totals for the peer. With --watch the output is refreshed at
+the given interval until interrupted.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(diagNetWatchOptionName, "w", "Refresh the output at an interval."),
+		cmdkit.StringOption(diagNetIntervalOptionName, "i", "Time between refreshes, if 'watch' is set.").WithDefault("2s"),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		if !nd.OnlineMode() {
+			return cmdkit.Errorf(cmdkit.ErrClient, ErrNotOnline.Error())
+		}
+
+		watch, _ := req.Options[diagNetWatchOptionName].(bool)
+		intervalStr, _ := req.Options[diagNetIntervalOptionName].(string)
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			return err
+		}
+
+		for {
+			out := &DiagNetOutput{}
+			for _, c := range nd.PeerHost.Network().Conns() {
+				info := DiagNetConn{
+					Peer:      c.RemotePeer().Pretty(),
+					Addr:      c.RemoteMultiaddr().String(),
+					Transport: connTransport(c.RemoteMultiaddr()),
+					Direction: directionString(c.Stat().Direction),
+					Age:       "n/a",
+					Streams:   make(map[string]int),
+				}
+
+				if nd.ConnTracker != nil {
+					if opened, ok := nd.ConnTracker.OpenedAt(c); ok {
+						info.Age = time.Since(opened).Truncate(time.Second).String()
+					}
+				}
+
+				for _, s := range c.GetStreams() {
+					proto := string(s.Protocol())
+					if proto == "" {
+						proto = "<no protocol name>"
+					}
+					info.Streams[proto]++
+					out.TotalStreams++
+				}
+
+				if nd.Reporter != nil {
+					stats := nd.Reporter.GetBandwidthForPeer(c.RemotePeer())
+					info.BytesIn = uint64(stats.TotalIn)
+					info.BytesOut = uint64(stats.TotalOut)
+				}
+
+				out.Conns = append(out.Conns, info)
+			}
+
+			sort.Slice(out.Conns, func(i, j int) bool {
+				return out.Conns[i].Peer < out.Conns[j].Peer
+			})
+			out.TotalConns = len(out.Conns)
+
+			if err := res.Emit(out); err != nil {
+				return err
+			}
+			if !watch {
+				return nil
+			}
+			select {
+			case <-time.After(interval):
+			case <-req.Context.Done():
+				return req.Context.Err()
+			}
+		}
+	},
+	Type: DiagNetOutput{},
+	PostRun: cmds.PostRunMap{
+		cmds.CLI: func(res cmds.Response, re cmds.ResponseEmitter) error {
+			watch, _ := res.Request().Options[diagNetWatchOptionName].(bool)
+
+			for {
+				v, err := res.Next()
+				if err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return err
+				}
+
+				out := v.(*DiagNetOutput)
+				if watch {
+					// clear the terminal between refreshes
+					fmt.Fprint(os.Stdout, "\033[H\033[2J")
+				}
+				printDiagNet(os.Stdout, out)
+				if !watch {
+					return nil
+				}
+			}
+		},
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *DiagNetOutput) error {
+			printDiagNet(w, out)
+			return nil
+		}),
+	},
+}
+
+func printDiagNet(out io.Writer, d *DiagNetOutput) {
+	tw := tabwriter.NewWriter(out, 1, 2, 1, ' ', 0)
+	fmt.Fprintf(tw, "Peer\tTransport\tDirection\tAge\tStreams\tIn\tOut\tAddress\n")
+	for _, c := range d.Conns {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			c.Peer, c.Transport, c.Direction, c.Age, streamCount(c.Streams),
+			humanize.Bytes(c.BytesIn), humanize.Bytes(c.BytesOut), c.Addr)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(out, "\n%d connections, %d streams\n", d.TotalConns, d.TotalStreams)
+
+	protos := make(map[string]int)
+	for _, c := range d.Conns {
+		for proto, n := range c.Streams {
+			protos[proto] += n
+		}
+	}
+	names := make([]string, 0, len(protos))
+	for proto := range protos {
+		names = append(names, proto)
+	}
+	sort.Strings(names)
+	for _, proto := range names {
+		fmt.Fprintf(out, "  %s: %d\n", proto, protos[proto])
+	}
+}
+
+func streamCount(streams map[string]int) int {
+	total := 0
+	for _, n := range streams {
+		total += n
+	}
+	return total
+}
+
+// connTransport derives a transport name from the connection's remote
+// multiaddr; the last transport component wins (e.g. /udp/…/quic is
+// reported as quic).
+func connTransport(addr ma.Multiaddr) string {
+	transport := "unknown"
+	for _, p := range addr.Protocols() {
+		switch p.Name {
+		case "tcp", "udp", "utp", "quic", "ws", "wss", "p2p-circuit":
+			transport = p.Name
+		}
+	}
+	return transport
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
+	ethofs "github.com/ipsn/go-ipfs/ethofs"
+
+	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+)
+
+var EthofsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Interact with ethoFS hosting contract synchronization.",
+		ShortDescription: `
+'ipfs ethofs' exposes the state of the Ether-1 hosting contract pin
+synchronization, configured through the EthoFS config section.
+`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"status": ethofsStatusCmd,
+	},
+}
+
+var ethofsStatusCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show hosting contract sync state.",
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		if n.EthoFS == nil {
+			return cmds.EmitOnce(res, &ethofs.Status{})
+		}
+		st := n.EthoFS.Status()
+		return cmds.EmitOnce(res, &st)
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, st *ethofs.Status) error {
+			if !st.Enabled {
+				fmt.Fprintln(w, "ethoFS contract sync is disabled")
+				return nil
+			}
+
+			fmt.Fprintf(w, "Contract:     %s\n", st.ContractAddress)
+			fmt.Fprintf(w, "RPC endpoint: %s\n", st.RPCEndpoint)
+			fmt.Fprintf(w, "Interval:     %s\n", st.SyncInterval)
+			fmt.Fprintf(w, "Managed pins: %d\n", st.ManagedPins)
+			fmt.Fprintf(w, "Syncs:        %d\n", st.SyncCount)
+			if st.LastSync != "" {
+				fmt.Fprintf(w, "Last sync:    %s\n", st.LastSync)
+			} else {
+				fmt.Fprintln(w, "Last sync:    never")
+			}
+			if st.LastError != "" {
+				fmt.Fprintf(w, "Last error:   %s\n", st.LastError)
+			}
+			return nil
+		}),
+	},
+	Type: ethofs.Status{},
+}
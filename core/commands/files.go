@@ -7,9 +7,13 @@ import (
 	"io"
 	"os"
 	gopath "path"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	car "github.com/ipsn/go-ipfs/car"
 	"github.com/ipsn/go-ipfs/core"
 	"github.com/ipsn/go-ipfs/core/commands/cmdenv"
 	"github.com/ipsn/go-ipfs/core/coreapi/interface"
@@ -20,6 +24,7 @@ import (
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-mfs"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-exchange-offline"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+	files "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-files"
 	ft "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-unixfs"
 	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
 	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
@@ -52,16 +57,20 @@ operations.
 		cmdkit.BoolOption(filesFlushOptionName, "f", "Flush target and ancestors after write.").WithDefault(true),
 	},
 	Subcommands: map[string]*cmds.Command{
-		"read":  filesReadCmd,
-		"write": filesWriteCmd,
-		"mv":    filesMvCmd,
-		"cp":    filesCpCmd,
-		"ls":    filesLsCmd,
-		"mkdir": filesMkdirCmd,
-		"stat":  filesStatCmd,
-		"rm":    filesRmCmd,
-		"flush": filesFlushCmd,
-		"chcid": filesChcidCmd,
+		"read":       filesReadCmd,
+		"write":      filesWriteCmd,
+		"mv":         filesMvCmd,
+		"cp":         filesCpCmd,
+		"import-car": filesImportCarCmd,
+		"ls":         filesLsCmd,
+		"search":     filesSearchCmd,
+		"mkdir":      filesMkdirCmd,
+		"stat":       filesStatCmd,
+		"rm":         filesRmCmd,
+		"flush":      filesFlushCmd,
+		"chcid":      filesChcidCmd,
+		"chmod":      filesChmodCmd,
+		"touch":      filesTouchCmd,
 	},
 }
 
@@ -81,9 +90,11 @@ type statOutput struct {
 	CumulativeSize uint64
 	Blocks         int
 	Type           string
-	WithLocality   bool   `json:",omitempty"`
-	Local          bool   `json:",omitempty"`
-	SizeLocal      uint64 `json:",omitempty"`
+	WithLocality   bool              `json:",omitempty"`
+	Local          bool              `json:",omitempty"`
+	SizeLocal      uint64            `json:",omitempty"`
+	MimeType       string            `json:",omitempty"`
+	Metadata       map[string]string `json:",omitempty"`
 }
 
 const (
@@ -233,22 +244,32 @@ func statNode(nd ipld.Node) (*statOutput, error) {
 		}
 
 		var ndtype string
+		out := &statOutput{
+			Hash:           c.String(),
+			Blocks:         len(nd.Links()),
+			Size:           d.FileSize(),
+			CumulativeSize: cumulsize,
+		}
+
 		switch d.Type() {
 		case ft.TDirectory, ft.THAMTShard:
 			ndtype = "directory"
-		case ft.TFile, ft.TMetadata, ft.TRaw:
+		case ft.TFile, ft.TRaw:
+			ndtype = "file"
+		case ft.TMetadata:
 			ndtype = "file"
+			// surface the content type and any key/value pairs the
+			// metadata node carries
+			if md, err := ft.MetadataFromBytes(n.Data()); err == nil {
+				out.MimeType = md.MimeType
+				out.Metadata = md.Pairs
+			}
 		default:
 			return nil, fmt.Errorf("unrecognized node type: %s", d.Type())
 		}
 
-		return &statOutput{
-			Hash:           c.String(),
-			Blocks:         len(nd.Links()),
-			Size:           d.FileSize(),
-			CumulativeSize: cumulsize,
-			Type:           ndtype,
-		}, nil
+		out.Type = ndtype
+		return out, nil
 	case *dag.RawNode:
 		return &statOutput{
 			Hash:           c.String(),
@@ -351,6 +372,104 @@ var filesCpCmd = &cmds.Command{
 	},
 }
 
+var filesImportCarCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Import a car file directly into mfs.",
+		ShortDescription: `
+Reads a CARv1 (Content Addressable aRchive) stream, adds every block it
+contains to the local blockstore and links the root listed in the car
+header at the given mfs path in one operation, so the imported tree is
+never exposed to the garbage collector unreferenced. The root has to be
+a unixfs node. A car with several roots is linked as one entry per root
+cid under the given directory.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("path", true, false, "Path within mfs to link the imported root at."),
+		cmdkit.FileArg("car", true, false, "The car file to import.").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		flush, _ := req.Options[filesFlushOptionName].(bool)
+
+		path, err := checkPath(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		// admission control against the hosting contract quota
+		var uploadSize uint64
+		if size, err := req.Files.Size(); err == nil && size > 0 {
+			uploadSize = uint64(size)
+		}
+		if err := nd.EthoFS.CheckQuota(req.Context, uploadSize); err != nil {
+			return err
+		}
+
+		var roots []cid.Cid
+		it := req.Files.Entries()
+		for it.Next() {
+			file := files.FileFromEntry(it)
+			if file == nil {
+				return fmt.Errorf("expected a regular file")
+			}
+			rs, err := car.Import(nd.Blocks, file)
+			if err != nil {
+				return err
+			}
+			roots = append(roots, rs...)
+		}
+		if it.Err() != nil {
+			return it.Err()
+		}
+		nd.EthoFS.ChargeQuota(uploadSize)
+
+		if len(roots) == 0 {
+			return fmt.Errorf("import-car: car header lists no roots")
+		}
+
+		link := func(dst string, c cid.Cid) error {
+			node, err := nd.DAG.Get(req.Context, c)
+			if err != nil {
+				return fmt.Errorf("import-car: car root %s is incomplete: %s", c, err)
+			}
+			switch n := node.(type) {
+			case *dag.ProtoNode:
+				if _, err := ft.FSNodeFromBytes(n.Data()); err != nil {
+					return fmt.Errorf("import-car: root %s is not a unixfs node: %s", c, err)
+				}
+			case *dag.RawNode:
+				// raw leaves are valid unixfs files
+			default:
+				return fmt.Errorf("import-car: root %s is not a unixfs node", c)
+			}
+			if err := mfs.PutNode(nd.FilesRoot, dst, node); err != nil {
+				return fmt.Errorf("import-car: cannot put node in path %s: %s", dst, err)
+			}
+			if flush {
+				if err := mfs.FlushPath(nd.FilesRoot, dst); err != nil {
+					return fmt.Errorf("import-car: cannot flush the created file %s: %s", dst, err)
+				}
+			}
+			return nil
+		}
+
+		if len(roots) == 1 && !strings.HasSuffix(path, "/") {
+			return link(path, roots[0])
+		}
+		for _, c := range roots {
+			if err := link(gopath.Join(path, c.String()), c); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 func getNodeFromPath(ctx context.Context, node *core.IpfsNode, api iface.CoreAPI, p string) (ipld.Node, error) {
 	switch {
 	case strings.HasPrefix(p, "/ipfs/"):
@@ -504,6 +623,158 @@ Examples:
 	Type: filesLsOutput{},
 }
 
+const (
+	searchRegexOptionName = "regex"
+	searchDepthOptionName = "depth"
+)
+
+type searchEntry struct {
+	Path string
+	Type int
+	Size int64
+	Hash string
+}
+
+var filesSearchCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Search the local mutable namespace by name.",
+		ShortDescription: `
+Recursively walks the mfs tree under a directory and streams every
+entry whose name matches the given pattern. Patterns are shell globs
+by default; pass --regex to match with a regular expression instead.
+
+Examples:
+
+    $ ipfs files search '*.jpg'
+    $ ipfs files search --regex '^report-[0-9]+' /work
+    $ ipfs files search --depth=2 'readme*' /projects
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("pattern", true, false, "Name pattern to search for."),
+		cmdkit.StringArg("path", false, false, "Directory to search under. Defaults to '/'."),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(searchRegexOptionName, "Treat the pattern as a regular expression instead of a glob."),
+		cmdkit.IntOption(searchDepthOptionName, "Maximum number of directory levels to descend. 0 means no limit.").WithDefault(0),
+		cmdkit.BoolOption(longOptionName, "Use long listing format."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		pattern := req.Arguments[0]
+
+		arg := "/"
+		if len(req.Arguments) > 1 {
+			arg = req.Arguments[1]
+		}
+		path, err := checkPath(arg)
+		if err != nil {
+			return err
+		}
+
+		var match func(string) bool
+		if useRegex, _ := req.Options[searchRegexOptionName].(bool); useRegex {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid regular expression: %s", err)
+			}
+			match = re.MatchString
+		} else {
+			if _, err := gopath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern: %s", err)
+			}
+			match = func(name string) bool {
+				matched, _ := gopath.Match(pattern, name)
+				return matched
+			}
+		}
+
+		maxDepth, _ := req.Options[searchDepthOptionName].(int)
+
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		fsn, err := mfs.Lookup(nd.FilesRoot, path)
+		if err != nil {
+			return err
+		}
+		dir, ok := fsn.(*mfs.Directory)
+		if !ok {
+			return fmt.Errorf("%s is not a directory", path)
+		}
+
+		return searchDir(req.Context, res, dir, path, match, maxDepth, 1)
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *searchEntry) error {
+			name := out.Path
+			if out.Type == int(mfs.TDir) {
+				name += "/"
+			}
+
+			long, _ := req.Options[longOptionName].(bool)
+			if long {
+				fmt.Fprintf(w, "%s\t%s\t%d\n", name, out.Hash, out.Size)
+			} else {
+				fmt.Fprintf(w, "%s\n", name)
+			}
+			return nil
+		}),
+	},
+	Type: searchEntry{},
+}
+
+// searchDir emits the entries of one directory level that match and
+// descends into its subdirectories. Listing goes through the unixfs
+// directory implementation, so sharded (HAMT) directories are walked
+// shard by shard without being materialized.
+func searchDir(ctx context.Context, res cmds.ResponseEmitter, dir *mfs.Directory, prefix string, match func(string) bool, maxDepth, depth int) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	listing, err := dir.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, nl := range listing {
+		childPath := gopath.Join(prefix, nl.Name)
+		if match(nl.Name) {
+			err := res.Emit(&searchEntry{
+				Path: childPath,
+				Type: nl.Type,
+				Size: nl.Size,
+				Hash: nl.Hash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if nl.Type != int(mfs.TDir) {
+			continue
+		}
+		if maxDepth > 0 && depth >= maxDepth {
+			continue
+		}
+
+		child, err := dir.Child(nl.Name)
+		if err != nil {
+			return err
+		}
+		cdir, ok := child.(*mfs.Directory)
+		if !ok {
+			continue
+		}
+		if err := searchDir(ctx, res, cdir, childPath, match, maxDepth, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 const (
 	filesOffsetOptionName = "offset"
 	filesCountOptionName  = "count"
@@ -925,6 +1196,216 @@ func updatePath(rt *mfs.Root, pth string, builder cid.Builder, flush bool) error
 	return nil
 }
 
+const filesMtimeOptionName = "mtime"
+
+var filesChmodCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Change the mode bits recorded for a file or directory.",
+		ShortDescription: `
+Change the unix mode bits stored in the unixfs data of a file or directory
+in MFS. The mode is given in octal, e.g. 0644 or 755. The mode is advisory;
+it is recorded in the dag but not enforced by ipfs itself.
+
+Examples:
+
+    $ ipfs files chmod 0644 /myfile
+    $ ipfs files chmod -r 0755 /mydir
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("mode", true, false, "Mode bits in octal, e.g. 0755."),
+		cmdkit.StringArg("path", true, false, "Path to change."),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(recursiveOptionName, "r", "Apply the mode to the whole subtree."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		mode, err := strconv.ParseUint(req.Arguments[0], 8, 32)
+		if err != nil {
+			return fmt.Errorf("parsing mode %q: %s", req.Arguments[0], err)
+		}
+		if mode > 07777 {
+			return fmt.Errorf("mode %q is out of range", req.Arguments[0])
+		}
+
+		path, err := checkPath(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		recursive, _ := req.Options[recursiveOptionName].(bool)
+		flush, _ := req.Options[filesFlushOptionName].(bool)
+
+		return updateUnixfsAttrs(req.Context, nd.FilesRoot, path, recursive, flush, func(n *ft.FSNode) {
+			n.SetMode(os.FileMode(mode))
+		})
+	},
+}
+
+var filesTouchCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Set the modification time recorded for a file or directory.",
+		ShortDescription: `
+Set the modification time stored in the unixfs data of a file or directory
+in MFS. The time defaults to now and is recorded with second precision.
+
+Examples:
+
+    $ ipfs files touch /myfile
+    $ ipfs files touch --mtime 1500000000 /myfile
+    $ ipfs files touch -r /mydir
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("path", true, false, "Path to update."),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(recursiveOptionName, "r", "Apply the time to the whole subtree."),
+		cmdkit.Int64Option(filesMtimeOptionName, "Modification time to record, in seconds since the unix epoch. Default: now."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		mtime := time.Now()
+		if secs, ok := req.Options[filesMtimeOptionName].(int64); ok {
+			mtime = time.Unix(secs, 0)
+		}
+
+		path, err := checkPath(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		recursive, _ := req.Options[recursiveOptionName].(bool)
+		flush, _ := req.Options[filesFlushOptionName].(bool)
+
+		return updateUnixfsAttrs(req.Context, nd.FilesRoot, path, recursive, flush, func(n *ft.FSNode) {
+			n.SetMtime(mtime)
+		})
+	},
+}
+
+// updateUnixfsAttrs rewrites the unixfs data of the entry at pth using
+// update and replaces the entry in its parent directory. With recursive
+// set it also walks into directories and applies the update to every
+// entry below pth.
+func updateUnixfsAttrs(ctx context.Context, rt *mfs.Root, pth string, recursive, flush bool, update func(*ft.FSNode)) error {
+	if pth == "/" {
+		return fmt.Errorf("cannot change attributes of the root directory")
+	}
+
+	if pth[len(pth)-1] == '/' {
+		pth = pth[:len(pth)-1]
+	}
+
+	if err := updateUnixfsEntry(rt, pth, recursive, update); err != nil {
+		return err
+	}
+
+	if recursive {
+		if err := updateUnixfsChildren(ctx, rt, pth, update); err != nil {
+			return err
+		}
+	}
+
+	if flush {
+		return mfs.FlushPath(rt, pth)
+	}
+
+	return nil
+}
+
+func updateUnixfsEntry(rt *mfs.Root, pth string, recursive bool, update func(*ft.FSNode)) error {
+	fsn, err := mfs.Lookup(rt, pth)
+	if err != nil {
+		return err
+	}
+
+	nd, err := fsn.GetNode()
+	if err != nil {
+		return err
+	}
+
+	pnd, ok := nd.(*dag.ProtoNode)
+	if !ok {
+		if recursive {
+			// raw nodes have no unixfs envelope to hold attributes, skip
+			// them instead of failing halfway through the walk
+			return nil
+		}
+		return fmt.Errorf("%s: cannot set attributes on a raw node", pth)
+	}
+
+	fsnd, err := ft.FSNodeFromBytes(pnd.Data())
+	if err != nil {
+		return err
+	}
+
+	update(fsnd)
+
+	b, err := fsnd.GetBytes()
+	if err != nil {
+		return err
+	}
+
+	newnd := pnd.Copy().(*dag.ProtoNode)
+	newnd.SetData(b)
+
+	dirp, name := gopath.Split(pth)
+	parent, err := mfs.Lookup(rt, dirp)
+	if err != nil {
+		return fmt.Errorf("parent lookup: %s", err)
+	}
+
+	pdir, ok := parent.(*mfs.Directory)
+	if !ok {
+		return fmt.Errorf("no such file or directory: %s", pth)
+	}
+
+	if err := pdir.Unlink(name); err != nil {
+		return err
+	}
+
+	return pdir.AddChild(name, newnd)
+}
+
+func updateUnixfsChildren(ctx context.Context, rt *mfs.Root, pth string, update func(*ft.FSNode)) error {
+	fsn, err := mfs.Lookup(rt, pth)
+	if err != nil {
+		return err
+	}
+
+	dir, ok := fsn.(*mfs.Directory)
+	if !ok {
+		return nil
+	}
+
+	names, err := dir.ListNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		child := gopath.Join(pth, name)
+		if err := updateUnixfsEntry(rt, child, true, update); err != nil {
+			return err
+		}
+		if err := updateUnixfsChildren(ctx, rt, child, update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 var filesRmCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Remove a file.",
@@ -18,6 +18,7 @@ import (
 	path "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-path"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
 	uarchive "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-unixfs/archive"
+	uio "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-unixfs/io"
 	dag "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-merkledag"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
 )
@@ -29,6 +30,8 @@ const (
 	archiveOptionName          = "archive"
 	compressOptionName         = "compress"
 	compressionLevelOptionName = "compression-level"
+	resumeOptionName           = "resume"
+	getOffsetOptionName        = "offset"
 )
 
 var GetCmd = &cmds.Command{
@@ -44,6 +47,11 @@ To output a TAR archive instead of unpacked files, use '--archive' or '-a'.
 
 To compress the output with GZIP compression, use '--compress' or '-C'. You
 may also specify the level of compression by specifying '-l=<1-9>'.
+
+To pick up an interrupted download of a single file where it left off,
+use '--resume'. The already-written part of the output file is kept
+and only the remainder is fetched. Resume does not work together with
+'--archive' or '--compress'.
 `,
 	},
 
@@ -55,10 +63,29 @@ may also specify the level of compression by specifying '-l=<1-9>'.
 		cmdkit.BoolOption(archiveOptionName, "a", "Output a TAR archive."),
 		cmdkit.BoolOption(compressOptionName, "C", "Compress the output with GZIP compression."),
 		cmdkit.IntOption(compressionLevelOptionName, "l", "The level of compression (1-9)."),
+		cmdkit.BoolOption(resumeOptionName, "Resume a partially downloaded file."),
+		cmdkit.Int64Option(getOffsetOptionName, "Byte offset to start the output at (set automatically by '--resume')."),
+		cmdenv.FetchTimeoutOption(),
 	},
 	PreRun: func(req *cmds.Request, env cmds.Environment) error {
 		_, err := getCompressOptions(req)
-		return err
+		if err != nil {
+			return err
+		}
+
+		// with --resume, figure out how much of the output file we
+		// already have and ask the node for just the remainder
+		if resume, _ := req.Options[resumeOptionName].(bool); resume {
+			archive, _ := req.Options[archiveOptionName].(bool)
+			cmprs, _ := req.Options[compressOptionName].(bool)
+			if archive || cmprs {
+				return errors.New("cannot resume an archived or compressed download")
+			}
+			if st, err := os.Stat(getOutPath(req)); err == nil && st.Mode().IsRegular() {
+				req.Options[getOffsetOptionName] = st.Size()
+			}
+		}
+		return nil
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		cmplvl, err := getCompressOptions(req)
@@ -71,11 +98,16 @@ may also specify the level of compression by specifying '-l=<1-9>'.
 			return err
 		}
 		p := path.Path(req.Arguments[0])
-		ctx := req.Context
-		dn, err := core.Resolve(ctx, node.Namesys, node.Resolver, p)
+		ctx, cancel, err := cmdenv.FetchContext(req)
 		if err != nil {
 			return err
 		}
+		defer cancel()
+
+		dn, err := core.Resolve(ctx, node.Namesys, node.Resolver, p)
+		if err != nil {
+			return cmdenv.FetchError(ctx, err)
+		}
 
 		switch dn := dn.(type) {
 		case *dag.ProtoNode:
@@ -91,6 +123,23 @@ may also specify the level of compression by specifying '-l=<1-9>'.
 			return err
 		}
 
+		// resumed download: stream the raw remainder of the file
+		// instead of a tar archive
+		if offset, _ := req.Options[getOffsetOptionName].(int64); offset > 0 {
+			dr, err := uio.NewDagReader(ctx, dn, node.DAG)
+			if err != nil {
+				return err
+			}
+			if uint64(offset) > dr.Size() {
+				return fmt.Errorf("local file is larger (%d bytes) than %s (%d bytes)", offset, p, dr.Size())
+			}
+			if _, err := dr.Seek(offset, io.SeekStart); err != nil {
+				return err
+			}
+			res.SetLength(dr.Size() - uint64(offset))
+			return res.Emit(dr)
+		}
+
 		archive, _ := req.Options[archiveOptionName].(bool)
 		reader, err := uarchive.DagArchive(ctx, dn, p.String(), node.DAG, archive, cmplvl)
 		if err != nil {
@@ -130,6 +179,10 @@ may also specify the level of compression by specifying '-l=<1-9>'.
 				Size:        int64(res.Length()),
 			}
 
+			if offset, _ := req.Options[getOffsetOptionName].(int64); offset > 0 {
+				return gw.writeAppended(outReader, outPath, offset)
+			}
+
 			return gw.Write(outReader, outPath)
 		},
 	},
@@ -228,6 +281,27 @@ func (gw *getWriter) writeArchive(r io.Reader, fpath string) error {
 	return err
 }
 
+// writeAppended continues a resumed download: the stream holds the
+// raw bytes of the file past offset, which are appended to the
+// existing output file.
+func (gw *getWriter) writeAppended(r io.Reader, fpath string, offset int64) error {
+	file, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	fmt.Fprintf(gw.Out, "Resuming file at %s (%d bytes already present)\n", fpath, offset)
+	bar := makeProgressBar(gw.Err, offset+gw.Size)
+	bar.Set64(offset)
+	barR := bar.NewProxyReader(r)
+	bar.Start()
+	defer bar.Finish()
+
+	_, err = io.Copy(file, &clearlineReader{barR, gw.Err})
+	return err
+}
+
 func (gw *getWriter) writeExtracted(r io.Reader, fpath string) error {
 	fmt.Fprintf(gw.Out, "Saving file(s) to %s\n", fpath)
 	bar := makeProgressBar(gw.Err, gw.Size)
@@ -35,10 +35,17 @@ type IdOutput struct {
 	Addresses       []string
 	AgentVersion    string
 	ProtocolVersion string
+
+	APICertFingerprint string `json:",omitempty"`
+
+	// only set for the local node with --reachability
+	Reachability string `json:",omitempty"`
+	ObservedAddr string `json:",omitempty"`
 }
 
 const (
-	formatOptionName = "format"
+	formatOptionName       = "format"
+	reachabilityOptionName = "reachability"
 )
 
 var IDCmd = &cmds.Command{
@@ -65,6 +72,7 @@ EXAMPLE:
 	},
 	Options: []cmdkit.Option{
 		cmdkit.StringOption(formatOptionName, "f", "Optional output format."),
+		cmdkit.BoolOption(reachabilityOptionName, "Include the NAT reachability determined by AutoNAT (local node only)."),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		n, err := cmdenv.GetNode(env)
@@ -88,6 +96,15 @@ EXAMPLE:
 			if err != nil {
 				return err
 			}
+			if reach, _ := req.Options[reachabilityOptionName].(bool); reach {
+				info := output.(*IdOutput)
+				info.Reachability = n.Reachability()
+				if n.AmbientNAT != nil {
+					if addr, err := n.AmbientNAT.PublicAddr(); err == nil {
+						info.ObservedAddr = addr.String()
+					}
+				}
+			}
 			return cmds.EmitOnce(res, output)
 		}
 
@@ -191,5 +208,6 @@ func printSelf(node *core.IpfsNode) (interface{}, error) {
 	}
 	info.ProtocolVersion = identify.LibP2PVersion
 	info.AgentVersion = identify.ClientVersion
+	info.APICertFingerprint = node.APICertFingerprint
 	return info, nil
 }
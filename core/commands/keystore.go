@@ -1,15 +1,26 @@
 package commands
 
 import (
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
 	"text/tabwriter"
 
+	commands "github.com/ipsn/go-ipfs/commands"
 	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
 	options "github.com/ipsn/go-ipfs/core/coreapi/interface/options"
+	keystore "github.com/ipsn/go-ipfs/keystore"
+	fsrepo "github.com/ipsn/go-ipfs/repo/fsrepo"
 
 	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
 	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	files "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-files"
+	ci "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-crypto"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
 )
 
 var KeyCmd = &cmds.Command{
@@ -30,10 +41,14 @@ publish'.
 		`,
 	},
 	Subcommands: map[string]*cmds.Command{
-		"gen":    keyGenCmd,
-		"list":   keyListCmd,
-		"rename": keyRenameCmd,
-		"rm":     keyRmCmd,
+		"export":        keyExportCmd,
+		"gen":           keyGenCmd,
+		"import":        keyImportCmd,
+		"list":          keyListCmd,
+		"migrate-store": keyMigrateStoreCmd,
+		"rename":        keyRenameCmd,
+		"rm":            keyRmCmd,
+		"rotate":        keyRotateCmd,
 	},
 }
 
@@ -247,3 +262,458 @@ func keyOutputListEncoders() cmds.EncoderFunc {
 		return nil
 	})
 }
+
+const (
+	keyFormatOptionName       = "format"
+	keyPasswordOptionName     = "password"
+	keyRotateOldKeyOptionName = "oldkey"
+
+	keyFormatPem      = "pem"
+	keyFormatProtobuf = "libp2p-protobuf"
+
+	keyPemBlockType = "LIBP2P PRIVATE KEY"
+)
+
+// KeyExportOutput is the output type of keyExportCmd. Key holds the
+// encoded (and possibly encrypted) private key material.
+type KeyExportOutput struct {
+	Name string
+	Id   string
+	Key  []byte
+}
+
+var keyExportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Export a keypair",
+		ShortDescription: `
+Exports a named keypair (or 'self' for the node identity key) so it can
+be backed up or moved to another node with 'ipfs key import'.
+
+By default the key is written as a PEM block wrapping the serialized
+libp2p private key; with --format=libp2p-protobuf the raw serialized
+bytes are written instead. With --password the key material is
+encrypted using the same scrypt/AES-256-GCM scheme as the encrypted
+keystore backend.
+
+  > ipfs key export mykey > mykey.pem
+  > ipfs key export --password=secret self > self.pem
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("name", true, false, "name of key to export"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption(keyFormatOptionName, "f", "Export format [pem, libp2p-protobuf].").WithDefault(keyFormatPem),
+		cmdkit.StringOption(keyPasswordOptionName, "Encrypt the exported key with this password."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		name := req.Arguments[0]
+
+		var sk ci.PrivKey
+		if name == "self" {
+			sk = n.PrivateKey
+		} else {
+			sk, err = n.Repo.Keystore().Get(name)
+			if err != nil {
+				return err
+			}
+		}
+
+		pid, err := peer.IDFromPrivateKey(sk)
+		if err != nil {
+			return err
+		}
+
+		data, err := sk.Bytes()
+		if err != nil {
+			return err
+		}
+
+		if password, _ := req.Options[keyPasswordOptionName].(string); password != "" {
+			data, err = keystore.Seal([]byte(password), data)
+			if err != nil {
+				return err
+			}
+		}
+
+		format, _ := req.Options[keyFormatOptionName].(string)
+		switch format {
+		case keyFormatPem:
+			data = pem.EncodeToMemory(&pem.Block{Type: keyPemBlockType, Bytes: data})
+		case keyFormatProtobuf:
+		default:
+			return fmt.Errorf("unknown export format: %s (supported: %s, %s)", format, keyFormatPem, keyFormatProtobuf)
+		}
+
+		return cmds.EmitOnce(res, &KeyExportOutput{
+			Name: name,
+			Id:   pid.Pretty(),
+			Key:  data,
+		})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, ko *KeyExportOutput) error {
+			_, err := w.Write(ko.Key)
+			return err
+		}),
+	},
+	Type: KeyExportOutput{},
+}
+
+var keyImportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Import a keypair",
+		ShortDescription: `
+Imports a keypair written by 'ipfs key export' into the keystore under
+the given name. Both the PEM and the raw libp2p-protobuf formats are
+accepted; password protected keys need the --password used at export.
+
+  > ipfs key import mykey mykey.pem
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("name", true, false, "name to give the imported key"),
+		cmdkit.FileArg("key", true, false, "key file written by 'ipfs key export'").EnableStdin(),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption(keyPasswordOptionName, "Decrypt the key with this password."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		name := req.Arguments[0]
+		if name == "self" {
+			return fmt.Errorf("cannot import key with name 'self'")
+		}
+
+		it := req.Files.Entries()
+		if !it.Next() {
+			if it.Err() != nil {
+				return it.Err()
+			}
+			return fmt.Errorf("no key file given")
+		}
+
+		f := files.FileFromEntry(it)
+		if f == nil {
+			return fmt.Errorf("expected a file")
+		}
+
+		data, err := ioutil.ReadAll(f)
+		if err != nil {
+			return err
+		}
+
+		if block, _ := pem.Decode(data); block != nil {
+			if block.Type != keyPemBlockType {
+				return fmt.Errorf("unexpected PEM block type: %s", block.Type)
+			}
+			data = block.Bytes
+		}
+
+		password, _ := req.Options[keyPasswordOptionName].(string)
+		if keystore.IsEncrypted(data) {
+			if password == "" {
+				return fmt.Errorf("key is password protected, decrypt it with --password")
+			}
+			data, err = keystore.Open([]byte(password), data)
+			if err != nil {
+				return fmt.Errorf("decrypting key: %s", err)
+			}
+		} else if password != "" {
+			return fmt.Errorf("key is not password protected")
+		}
+
+		sk, err := ci.UnmarshalPrivateKey(data)
+		if err != nil {
+			return err
+		}
+
+		if err := n.Repo.Keystore().Put(name, sk); err != nil {
+			return err
+		}
+
+		pid, err := peer.IDFromPrivateKey(sk)
+		if err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &KeyOutput{
+			Name: name,
+			Id:   pid.Pretty(),
+		})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, ko *KeyOutput) error {
+			_, err := w.Write([]byte(ko.Id + "\n"))
+			return err
+		}),
+	},
+	Type: KeyOutput{},
+}
+
+// KeyRotateOutput is the output type of keyRotateCmd. Transition holds
+// a base64 encoded signature by the old identity key over the statement
+// "ipfs-key-rotation:<old-peer-id>:<new-peer-id>".
+type KeyRotateOutput struct {
+	OldId      string
+	NewId      string
+	OldKeyName string
+	Transition string
+}
+
+var keyRotateCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Rotate the node identity keypair",
+		ShortDescription: `
+Replaces the node's identity key with a freshly generated one. The old
+key is kept in the keystore under the name given with --oldkey so names
+published under the old identity can still be updated.
+
+The old key signs a transition record over the statement
+"ipfs-key-rotation:<old-peer-id>:<new-peer-id>", printed base64
+encoded, which can be published to prove that both identities belong to
+the same operator.
+
+The daemon must not be running when this command is run.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption(keyRotateOldKeyOptionName, "o", "Keystore name for the old identity key."),
+		cmdkit.StringOption(keyStoreTypeOptionName, "t", "type of the new key to generate [rsa, ed25519]").WithDefault("rsa"),
+		cmdkit.IntOption(keyStoreSizeOptionName, "s", "size of the new key to generate").WithDefault(2048),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		oldName, _ := req.Options[keyRotateOldKeyOptionName].(string)
+		if oldName == "" {
+			return fmt.Errorf("please specify a name for the old key with --oldkey")
+		}
+		if oldName == "self" {
+			return fmt.Errorf("cannot store the old key under the name 'self'")
+		}
+
+		var typ int
+		switch t, _ := req.Options[keyStoreTypeOptionName].(string); t {
+		case "rsa":
+			typ = ci.RSA
+		case "ed25519":
+			typ = ci.Ed25519
+		default:
+			return fmt.Errorf("unrecognized key type: %s", t)
+		}
+		size, _ := req.Options[keyStoreSizeOptionName].(int)
+
+		cfgRoot, err := cmdenv.GetConfigRoot(env)
+		if err != nil {
+			return err
+		}
+
+		r, err := fsrepo.Open(cfgRoot)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+
+		cfg, err := r.Config()
+		if err != nil {
+			return err
+		}
+
+		oldSk, err := cfg.Identity.DecodePrivateKey("")
+		if err != nil {
+			return err
+		}
+		oldID := cfg.Identity.PeerID
+
+		newSk, newPk, err := ci.GenerateKeyPair(typ, size)
+		if err != nil {
+			return err
+		}
+
+		newID, err := peer.IDFromPublicKey(newPk)
+		if err != nil {
+			return err
+		}
+
+		// stash the old key before touching the config so a failure
+		// further down cannot lose it
+		if err := r.Keystore().Put(oldName, oldSk); err != nil {
+			return fmt.Errorf("storing old key: %s", err)
+		}
+
+		skb, err := newSk.Bytes()
+		if err != nil {
+			return err
+		}
+		cfg.Identity.PrivKey = base64.StdEncoding.EncodeToString(skb)
+		cfg.Identity.PeerID = newID.Pretty()
+
+		if err := r.SetConfig(cfg); err != nil {
+			return err
+		}
+
+		sig, err := oldSk.Sign([]byte("ipfs-key-rotation:" + oldID + ":" + newID.Pretty()))
+		if err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &KeyRotateOutput{
+			OldId:      oldID,
+			NewId:      newID.Pretty(),
+			OldKeyName: oldName,
+			Transition: base64.StdEncoding.EncodeToString(sig),
+		})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, kro *KeyRotateOutput) error {
+			fmt.Fprintf(w, "rotated identity %s -> %s; old key saved as '%s'\n", kro.OldId, kro.NewId, kro.OldKeyName)
+			fmt.Fprintf(w, "transition: %s\n", kro.Transition)
+			return nil
+		}),
+	},
+	Type: KeyRotateOutput{},
+}
+
+const keyStorePathOptionName = "path"
+
+var keyMigrateStoreCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Move keys to a different keystore backend.",
+		ShortDescription: `
+Moves every key in the repo's keystore to the given backend and updates
+the "Keystore" config section to match, so the new backend is used from
+the next start. Backends:
+
+  fs            plaintext key files (the default backend)
+  encrypted-fs  password protected key files; the password is taken
+                from the IPFS_KEYSTORE_PASSWORD environment variable or
+                the Keystore.PasswordFile config key
+
+A running daemon keeps using the old backend until it is restarted.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("backend", true, false, "Destination backend ('fs' or 'encrypted-fs')."),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption(keyStorePathOptionName, "Destination directory, absolute or relative to the repo (default: \"keystore\")."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := n.Repo.Config()
+		if err != nil {
+			return err
+		}
+
+		repoRoot := env.(*commands.Context).ConfigRoot
+
+		resolve := func(p string) string {
+			if p == "" {
+				p = "keystore"
+			}
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(repoRoot, p)
+			}
+			return p
+		}
+
+		backend := req.Arguments[0]
+		dstPath, _ := req.Options[keyStorePathOptionName].(string)
+		dstDir := resolve(dstPath)
+
+		curType := cfg.Keystore.Type
+		if curType == "" {
+			curType = "fs"
+		}
+		samePath := dstDir == resolve(cfg.Keystore.Path)
+		if backend == curType && samePath {
+			return fmt.Errorf("keys are already stored in the %s backend at %s", backend, dstDir)
+		}
+
+		var dst keystore.Keystore
+		switch backend {
+		case "fs":
+			dst, err = keystore.NewFSKeystore(dstDir)
+		case "encrypted-fs":
+			var password []byte
+			password, err = keystore.ReadPassword(cfg.Keystore.PasswordFile)
+			if err != nil {
+				return err
+			}
+			dst, err = keystore.NewEncryptedFSKeystore(dstDir, password)
+		default:
+			return fmt.Errorf("unknown keystore backend: %s (supported: fs, encrypted-fs)", backend)
+		}
+		if err != nil {
+			return err
+		}
+
+		src := n.Repo.Keystore()
+		names, err := src.List()
+		if err != nil {
+			return err
+		}
+		sort.Strings(names)
+
+		// read everything up front so a bad source key aborts the
+		// migration before anything is moved
+		keys := make(map[string]ci.PrivKey, len(names))
+		for _, name := range names {
+			k, err := src.Get(name)
+			if err != nil {
+				return fmt.Errorf("reading key '%s': %s", name, err)
+			}
+			keys[name] = k
+		}
+
+		if samePath {
+			// in-place backend change: the destination reuses the
+			// source file names, so each key is rewritten in turn
+			for _, name := range names {
+				if err := src.Delete(name); err != nil {
+					return fmt.Errorf("migrating key '%s': %s", name, err)
+				}
+				if err := dst.Put(name, keys[name]); err != nil {
+					src.Put(name, keys[name]) // best effort restore
+					return fmt.Errorf("migrating key '%s': %s", name, err)
+				}
+			}
+		} else {
+			for _, name := range names {
+				if err := dst.Put(name, keys[name]); err != nil {
+					return fmt.Errorf("migrating key '%s': %s", name, err)
+				}
+			}
+			for _, name := range names {
+				if err := src.Delete(name); err != nil {
+					return fmt.Errorf("removing key '%s' from the old keystore: %s", name, err)
+				}
+			}
+		}
+
+		if err := n.Repo.SetConfigKey("Keystore.Type", backend); err != nil {
+			return err
+		}
+		if err := n.Repo.SetConfigKey("Keystore.Path", dstPath); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &stringList{names})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(stringListEncoder),
+	},
+	Type: stringList{},
+}
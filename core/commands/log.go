@@ -38,6 +38,10 @@ var logLevelCmd = &cmds.Command{
 		ShortDescription: `
 Change the verbosity of one or all subsystems log output. This does not affect
 the event log.
+
+Levels set this way only last until the daemon exits. To make them
+survive restarts, set "Logging.Level" and "Logging.Levels" in the
+config; those are applied on every start.
 `,
 	},
 
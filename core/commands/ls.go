@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -67,6 +68,7 @@ The JSON output contains type information.
 		cmdkit.BoolOption(lsHeadersOptionNameTime, "v", "Print table headers (Hash, Size, Name)."),
 		cmdkit.BoolOption(lsResolveTypeOptionName, "Resolve linked objects to find out their types.").WithDefault(true),
 		cmdkit.BoolOption(lsStreamOptionName, "s", "Enable exprimental streaming of directory entries as they are traversed."),
+		cmdenv.FetchTimeoutOption(),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		nd, err := cmdenv.GetNode(env)
@@ -94,19 +96,25 @@ The JSON output contains type information.
 
 		paths := req.Arguments
 
+		ctx, cancel, err := cmdenv.FetchContext(req)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
 		var dagnodes []ipld.Node
 		for _, fpath := range paths {
 			p, err := iface.ParsePath(fpath)
 			if err != nil {
 				return err
 			}
-			dagnode, err := api.ResolveNode(req.Context, p)
+			dagnode, err := api.ResolveNode(ctx, p)
 			if err != nil {
-				return err
+				return cmdenv.FetchError(ctx, err)
 			}
 			dagnodes = append(dagnodes, dagnode)
 		}
-		ng := merkledag.NewSession(req.Context, nd.DAG)
+		ng := merkledag.NewSession(ctx, nd.DAG)
 		ro := merkledag.NewReadOnlyDagService(ng)
 
 		stream, _ := req.Options[lsStreamOptionName].(bool)
@@ -124,16 +132,16 @@ The JSON output contains type information.
 				if dir == nil {
 					links = dagnode.Links()
 				} else {
-					links, err = dir.Links(req.Context)
+					links, err = dir.Links(ctx)
 					if err != nil {
-						return err
+						return cmdenv.FetchError(ctx, err)
 					}
 				}
 				outputLinks := make([]LsLink, len(links))
 				for j, link := range links {
-					lsLink, err := makeLsLink(req, dserv, resolve, link)
+					lsLink, err := makeLsLink(ctx, dserv, resolve, link)
 					if err != nil {
-						return err
+						return cmdenv.FetchError(ctx, err)
 					}
 					outputLinks[j] = *lsLink
 				}
@@ -156,18 +164,18 @@ The JSON output contains type information.
 			if dir == nil {
 				linkResults = makeDagNodeLinkResults(req, dagnode)
 			} else {
-				linkResults = dir.EnumLinksAsync(req.Context)
+				linkResults = dir.EnumLinksAsync(ctx)
 			}
 
 			for linkResult := range linkResults {
 
 				if linkResult.Err != nil {
-					return linkResult.Err
+					return cmdenv.FetchError(ctx, linkResult.Err)
 				}
 				link := linkResult.Link
-				lsLink, err := makeLsLink(req, dserv, resolve, link)
+				lsLink, err := makeLsLink(ctx, dserv, resolve, link)
 				if err != nil {
-					return err
+					return cmdenv.FetchError(ctx, err)
 				}
 				output := []LsObject{{
 					Hash:  paths[i],
@@ -224,7 +232,7 @@ func makeDagNodeLinkResults(req *cmds.Request, dagnode ipld.Node) <-chan unixfs.
 	return linkResults
 }
 
-func makeLsLink(req *cmds.Request, dserv ipld.DAGService, resolve bool, link *ipld.Link) (*LsLink, error) {
+func makeLsLink(ctx context.Context, dserv ipld.DAGService, resolve bool, link *ipld.Link) (*LsLink, error) {
 	t := unixfspb.Data_DataType(-1)
 
 	switch link.Cid.Type() {
@@ -232,7 +240,7 @@ func makeLsLink(req *cmds.Request, dserv ipld.DAGService, resolve bool, link *ip
 		// No need to check with raw leaves
 		t = unixfs.TFile
 	case cid.DagProtobuf:
-		linkNode, err := link.GetNode(req.Context, dserv)
+		linkNode, err := link.GetNode(ctx, dserv)
 		if err == ipld.ErrNotFound && !resolve {
 			// not an error
 			linkNode = nil
@@ -7,10 +7,18 @@ import (
 	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
 )
 
+// A native Windows backend needs WinFsp (or Dokan) bindings such as
+// cgofuse, which are not vendored in this tree; until they are, the
+// command only reports why it cannot mount.
 var MountCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
-		Tagline:          "Not yet implemented on Windows.",
-		ShortDescription: "Not yet implemented on Windows. :(",
+		Tagline: "Not yet implemented on Windows.",
+		ShortDescription: `
+Mounting is not implemented on Windows yet. It requires a userspace
+file system driver such as WinFsp or Dokan and Go bindings for it,
+which this build does not ship. On Windows, use the gateway
+(http://127.0.0.1:8080/ipfs/...) or 'ipfs get' instead.
+`,
 	},
 
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
@@ -0,0 +1,153 @@
+package name
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	core "github.com/ipsn/go-ipfs/core"
+	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
+	options "github.com/ipsn/go-ipfs/core/coreapi/interface/options"
+	pin "github.com/ipsn/go-ipfs/pin"
+
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+	path "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-path"
+)
+
+const (
+	followIntervalOptionName = "interval"
+	followPinOptionName      = "pin"
+)
+
+// FollowEvent is emitted every time the watched name's value changes
+// (including once for the initial value).
+type FollowEvent struct {
+	Name     string
+	Value    string
+	Previous string
+	Time     time.Time
+}
+
+var FollowCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Watch an IPNS name and stream its value changes.",
+		ShortDescription: `
+'ipfs name follow' keeps resolving an IPNS name and emits an event
+whenever the published value changes, starting with the current
+value. Resolution goes through the regular resolver stack, so when
+IPNS over pubsub is enabled updates typically arrive ahead of the
+periodic re-checks.
+
+With --pin, the root of each new value is pinned recursively and the
+previously pinned root is unpinned, so the node keeps mirroring
+whatever the name points at.
+
+The command streams until it is interrupted.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("name", true, false, "The IPNS name to follow."),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption(followIntervalOptionName, "i", "Time between resolution checks.").WithDefault("30s"),
+		cmdkit.BoolOption(followPinOptionName, "Pin new values recursively and unpin replaced ones."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+		if !nd.OnlineMode() {
+			return errors.New("this command must be run in online mode")
+		}
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		intervalStr, _ := req.Options[followIntervalOptionName].(string)
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			return fmt.Errorf("invalid interval: %s", err)
+		}
+		if interval <= 0 {
+			return fmt.Errorf("interval must be greater than 0, was %s", interval)
+		}
+		dopin, _ := req.Options[followPinOptionName].(bool)
+
+		name := req.Arguments[0]
+
+		var last string
+		var pinned cid.Cid
+		for {
+			p, err := api.Name().Resolve(req.Context, name, options.Name.Cache(false))
+			if err == nil && p.String() != last {
+				if dopin {
+					c, err := followPinSwap(req, nd, p.String(), pinned)
+					if err != nil {
+						return err
+					}
+					pinned = c
+				}
+
+				if err := res.Emit(&FollowEvent{
+					Name:     name,
+					Value:    p.String(),
+					Previous: last,
+					Time:     time.Now(),
+				}); err != nil {
+					return err
+				}
+				last = p.String()
+			}
+
+			select {
+			case <-time.After(interval):
+			case <-req.Context.Done():
+				return nil
+			}
+		}
+	},
+	Type: FollowEvent{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *FollowEvent) error {
+			if out.Previous == "" {
+				fmt.Fprintf(w, "%s %s: %s\n", out.Time.Format(time.RFC3339), out.Name, out.Value)
+			} else {
+				fmt.Fprintf(w, "%s %s: %s (was %s)\n", out.Time.Format(time.RFC3339), out.Name, out.Value, out.Previous)
+			}
+			return nil
+		}),
+	},
+}
+
+// followPinSwap pins the root of the new value recursively and unpins
+// the root pinned for the previous one, returning the new pinned cid.
+func followPinSwap(req *cmds.Request, nd *core.IpfsNode, value string, old cid.Cid) (cid.Cid, error) {
+	p, err := path.ParsePath(value)
+	if err != nil {
+		return cid.Undef, err
+	}
+	root, err := core.Resolve(req.Context, nd.Namesys, nd.Resolver, p)
+	if err != nil {
+		return cid.Undef, fmt.Errorf("resolving new value %s: %s", value, err)
+	}
+
+	defer nd.Blockstore.PinLock().Unlock()
+
+	if err := nd.Pinning.Pin(req.Context, root, true); err != nil {
+		return cid.Undef, err
+	}
+	if old.Defined() && old != root.Cid() {
+		if err := nd.Pinning.Unpin(req.Context, old, true); err != nil && err != pin.ErrNotPinned {
+			return cid.Undef, err
+		}
+	}
+	if err := nd.Pinning.Flush(); err != nil {
+		return cid.Undef, err
+	}
+	return root.Cid(), nil
+}
@@ -62,6 +62,7 @@ Resolve the value of a dnslink:
 	Subcommands: map[string]*cmds.Command{
 		"publish": PublishCmd,
 		"resolve": IpnsCmd,
+		"follow":  FollowCmd,
 		"pubsub":  IpnsPubsubCmd,
 	},
 }
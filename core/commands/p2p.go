@@ -15,8 +15,10 @@ import (
 	p2p "github.com/ipsn/go-ipfs/p2p"
 
 	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
 	ipfsaddr "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-addr"
 	madns "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr-dns"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
 	pstore "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peerstore"
 	protocol "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-protocol"
 	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
@@ -39,6 +41,10 @@ type P2PStreamInfoOutput struct {
 	Protocol      string
 	OriginAddress string
 	TargetAddress string
+	Peer          string
+	BytesIn       uint64
+	BytesOut      uint64
+	Open          string
 }
 
 // P2PLsOutput is output type of ls command
@@ -54,6 +60,12 @@ type P2PStreamsOutput struct {
 const (
 	allowCustomProtocolOptionName = "allow-custom-protocol"
 	reportPeerIDOptionName        = "report-peer-id"
+	udpOptionName                 = "udp"
+	keepaliveOptionName           = "keepalive"
+	persistOptionName             = "persist"
+	allowPeerOptionName           = "allow-peer"
+	denyPeerOptionName            = "deny-peer"
+	aclTokenOptionName            = "token"
 )
 
 var resolveTimeout = 10 * time.Second
@@ -91,6 +103,15 @@ Example:
   ipfs p2p forward ` + P2PProtoPrefix + `myproto /ip4/127.0.0.1/tcp/4567 /ipfs/QmPeer
     - Forward connections to 127.0.0.1:4567 to '` + P2PProtoPrefix + `myproto' service on /ipfs/QmPeer
 
+With --udp, <listen-address> must be a UDP endpoint. Datagrams are carried
+over per-source libp2p streams with a simple length-prefixed framing, which
+allows forwarding datagram protocols like DNS. The receiving side unwraps
+the framing when the 'ipfs p2p listen' target is a UDP address.
+
+Example:
+  ipfs p2p forward --udp ` + P2PProtoPrefix + `dns /ip4/127.0.0.1/udp/5353 /ipfs/QmPeer
+    - Forward datagrams sent to 127.0.0.1:5353 to '` + P2PProtoPrefix + `dns' service on /ipfs/QmPeer
+
 `,
 	},
 	Arguments: []cmdkit.Argument{
@@ -100,6 +121,9 @@ Example:
 	},
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption(allowCustomProtocolOptionName, "Don't require /x/ prefix"),
+		cmdkit.BoolOption(udpOptionName, "Forward UDP datagrams instead of TCP connections"),
+		cmdkit.BoolOption(keepaliveOptionName, "Periodically probe the remote peer and report state changes"),
+		cmdkit.BoolOption(persistOptionName, "Persist this forward in the config and re-establish it on daemon start"),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		n, err := p2pGetNode(env)
@@ -124,15 +148,47 @@ Example:
 		}
 
 		allowCustom, _ := req.Options[allowCustomProtocolOptionName].(bool)
+		udp, _ := req.Options[udpOptionName].(bool)
+		keepalive, _ := req.Options[keepaliveOptionName].(bool)
+		persist, _ := req.Options[persistOptionName].(bool)
 
 		if !allowCustom && !strings.HasPrefix(string(proto), P2PProtoPrefix) {
 			return errors.New("protocol name must be within '" + P2PProtoPrefix + "' namespace")
 		}
 
-		return forwardLocal(n.Context(), n.P2P, n.Peerstore, proto, listen, targets)
+		if err := forwardLocal(n.Context(), n.P2P, n.Peerstore, proto, listen, targets, udp, keepalive); err != nil {
+			return err
+		}
+
+		if persist {
+			return updateP2PConfig(n, func(c *config.P2PConfig) {
+				c.Forwards = append(c.Forwards, config.P2PForward{
+					Protocol:      protoOpt,
+					ListenAddress: listenOpt,
+					TargetAddress: targetOpt,
+					UDP:           udp,
+					Keepalive:     keepalive,
+				})
+			})
+		}
+
+		return nil
 	},
 }
 
+// updateP2PConfig applies update to the persisted p2p section of the
+// repo config
+func updateP2PConfig(n *core.IpfsNode, update func(*config.P2PConfig)) error {
+	cfg, err := n.Repo.Config()
+	if err != nil {
+		return err
+	}
+
+	update(&cfg.P2P)
+
+	return n.Repo.SetConfig(cfg)
+}
+
 // parseIpfsAddr is a function that takes in addr string and return ipfsAddrs
 func parseIpfsAddr(addr string) ([]ipfsaddr.IPFSAddr, error) {
 	mutiladdr, err := ma.NewMultiaddr(addr)
@@ -176,6 +232,12 @@ Example:
   ipfs p2p listen ` + P2PProtoPrefix + `myproto /ip4/127.0.0.1/tcp/1234
     - Forward connections to 'myproto' libp2p service to 127.0.0.1:1234
 
+Access to the service can be restricted with --allow-peer/--deny-peer,
+which take comma-separated base58 peer IDs. When --allow-peer is set only
+the listed peers are admitted; otherwise every peer except those in
+--deny-peer is. With --token set, inbound streams must additionally send
+'<token>' followed by a newline before any payload data is forwarded.
+
 `,
 	},
 	Arguments: []cmdkit.Argument{
@@ -185,6 +247,10 @@ Example:
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption(allowCustomProtocolOptionName, "Don't require /x/ prefix"),
 		cmdkit.BoolOption(reportPeerIDOptionName, "r", "Send remote base58 peerid to target when a new connection is established"),
+		cmdkit.StringOption(allowPeerOptionName, "Comma-separated peer IDs allowed to connect"),
+		cmdkit.StringOption(denyPeerOptionName, "Comma-separated peer IDs refused connection"),
+		cmdkit.StringOption(aclTokenOptionName, "Token remote peers must send before any payload data"),
+		cmdkit.BoolOption(persistOptionName, "Persist this listener in the config and re-establish it on daemon start"),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		n, err := p2pGetNode(env)
@@ -209,16 +275,81 @@ Example:
 
 		allowCustom, _ := req.Options[allowCustomProtocolOptionName].(bool)
 		reportPeerID, _ := req.Options[reportPeerIDOptionName].(bool)
+		allowOpt, _ := req.Options[allowPeerOptionName].(string)
+		denyOpt, _ := req.Options[denyPeerOptionName].(string)
+		token, _ := req.Options[aclTokenOptionName].(string)
 
 		if !allowCustom && !strings.HasPrefix(string(proto), P2PProtoPrefix) {
 			return errors.New("protocol name must be within '" + P2PProtoPrefix + "' namespace")
 		}
 
-		_, err = n.P2P.ForwardRemote(n.Context(), proto, target, reportPeerID)
-		return err
+		var acl *p2p.ACL
+		if allowOpt != "" || denyOpt != "" || token != "" {
+			allow, err := parsePeerIDList(allowOpt)
+			if err != nil {
+				return err
+			}
+			deny, err := parsePeerIDList(denyOpt)
+			if err != nil {
+				return err
+			}
+			acl = p2p.NewACL(allow, deny, token)
+		}
+
+		if _, err = n.P2P.ForwardRemote(n.Context(), proto, target, reportPeerID, acl); err != nil {
+			return err
+		}
+
+		if persist, _ := req.Options[persistOptionName].(bool); persist {
+			return updateP2PConfig(n, func(c *config.P2PConfig) {
+				c.Listeners = append(c.Listeners, config.P2PListener{
+					Protocol:      protoOpt,
+					TargetAddress: targetOpt,
+					ReportPeerID:  reportPeerID,
+					AllowPeers:    splitPeerList(allowOpt),
+					DenyPeers:     splitPeerList(denyOpt),
+					Token:         token,
+				})
+			})
+		}
+
+		return nil
 	},
 }
 
+// splitPeerList splits a comma-separated peer ID list, dropping empty
+// entries
+func splitPeerList(list string) []string {
+	if list == "" {
+		return nil
+	}
+
+	var out []string
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// parsePeerIDList parses a comma-separated list of base58 peer IDs
+func parsePeerIDList(list string) ([]peer.ID, error) {
+	if list == "" {
+		return nil, nil
+	}
+
+	var ids []peer.ID
+	for _, s := range strings.Split(list, ",") {
+		id, err := peer.IDB58Decode(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid peer ID '%s': %s", s, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 // checkPort checks whether target multiaddr contains tcp or udp protocol
 // and whether the port is equal to 0
 func checkPort(target ma.Multiaddr) error {
@@ -254,14 +385,19 @@ func checkPort(target ma.Multiaddr) error {
 }
 
 // forwardLocal forwards local connections to a libp2p service
-func forwardLocal(ctx context.Context, p *p2p.P2P, ps pstore.Peerstore, proto protocol.ID, bindAddr ma.Multiaddr, addrs []ipfsaddr.IPFSAddr) error {
+func forwardLocal(ctx context.Context, p *p2p.P2P, ps pstore.Peerstore, proto protocol.ID, bindAddr ma.Multiaddr, addrs []ipfsaddr.IPFSAddr, udp, keepalive bool) error {
 	for _, addr := range addrs {
 		ps.AddAddr(addr.ID(), addr.Multiaddr(), pstore.TempAddrTTL)
 	}
 	// TODO: return some info
 	// the length of the addrs must large than 0
 	// peerIDs in addr must be the same and choose addr[0] to connect
-	_, err := p.ForwardLocal(ctx, addrs[0].ID(), proto, bindAddr)
+	var err error
+	if udp {
+		_, err = p.ForwardLocalUDP(ctx, addrs[0].ID(), proto, bindAddr)
+	} else {
+		_, err = p.ForwardLocal(ctx, addrs[0].ID(), proto, bindAddr, keepalive)
+	}
 	return err
 }
 
@@ -392,6 +528,71 @@ var p2pCloseCmd = &cmds.Command{
 		done := n.P2P.ListenersLocal.Close(match)
 		done += n.P2P.ListenersP2P.Close(match)
 
+		// the persisted predicates mirror match: all given filters have
+		// to agree before an entry is dropped
+		matchForward := func(f config.P2PForward) bool {
+			if closeAll {
+				return true
+			}
+			if p && protoOpt != f.Protocol {
+				return false
+			}
+			if l && listenOpt != f.ListenAddress {
+				return false
+			}
+			if t && targetOpt != f.TargetAddress {
+				return false
+			}
+			return true
+		}
+
+		// a persisted listener answers on our own peer multiaddr, which
+		// is what its live listener reports as the listen address
+		selfAddr, err := ma.NewMultiaddr("/" + ma.ProtocolWithCode(ma.P_IPFS).Name + "/" + n.Identity.Pretty())
+		if err != nil {
+			return err
+		}
+		matchListener := func(li config.P2PListener) bool {
+			if closeAll {
+				return true
+			}
+			if p && protoOpt != li.Protocol {
+				return false
+			}
+			if l && !listen.Equal(selfAddr) {
+				return false
+			}
+			if t && targetOpt != li.TargetAddress {
+				return false
+			}
+			return true
+		}
+
+		// drop matching persisted entries so they are not re-established
+		// on the next daemon start
+		err = updateP2PConfig(n, func(c *config.P2PConfig) {
+			forwards := c.Forwards[:0]
+			for _, f := range c.Forwards {
+				if matchForward(f) {
+					continue
+				}
+				forwards = append(forwards, f)
+			}
+			c.Forwards = forwards
+
+			listeners := c.Listeners[:0]
+			for _, li := range c.Listeners {
+				if matchListener(li) {
+					continue
+				}
+				listeners = append(listeners, li)
+			}
+			c.Listeners = listeners
+		})
+		if err != nil {
+			return err
+		}
+
 		return cmds.EmitOnce(res, done)
 	},
 	Type: int(0),
@@ -425,7 +626,7 @@ var p2pStreamLsCmd = &cmds.Command{
 		Tagline: "List active p2p streams.",
 	},
 	Options: []cmdkit.Option{
-		cmdkit.BoolOption(p2pHeadersOptionName, "v", "Print table headers (ID, Protocol, Local, Remote)."),
+		cmdkit.BoolOption(p2pHeadersOptionName, "v", "Print table headers (ID, Protocol, Local, Remote, Peer, In, Out, Open)."),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		n, err := p2pGetNode(env)
@@ -444,6 +645,11 @@ var p2pStreamLsCmd = &cmds.Command{
 
 				OriginAddress: s.OriginAddr.String(),
 				TargetAddress: s.TargetAddr.String(),
+
+				Peer:     s.Peer().Pretty(),
+				BytesIn:  s.BytesIn(),
+				BytesOut: s.BytesOut(),
+				Open:     time.Since(s.OpenedAt).Truncate(time.Second).String(),
 			})
 		}
 		n.P2P.Streams.Unlock()
@@ -457,10 +663,10 @@ var p2pStreamLsCmd = &cmds.Command{
 			tw := tabwriter.NewWriter(w, 1, 2, 1, ' ', 0)
 			for _, stream := range out.Streams {
 				if headers {
-					fmt.Fprintln(tw, "ID\tProtocol\tOrigin\tTarget")
+					fmt.Fprintln(tw, "ID\tProtocol\tOrigin\tTarget\tPeer\tIn\tOut\tOpen")
 				}
 
-				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", stream.HandlerID, stream.Protocol, stream.OriginAddress, stream.TargetAddress)
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\n", stream.HandlerID, stream.Protocol, stream.OriginAddress, stream.TargetAddress, stream.Peer, stream.BytesIn, stream.BytesOut, stream.Open)
 			}
 			tw.Flush()
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	core "github.com/ipsn/go-ipfs/core"
@@ -14,6 +15,7 @@ import (
 	options "github.com/ipsn/go-ipfs/core/coreapi/interface/options"
 	corerepo "github.com/ipsn/go-ipfs/core/corerepo"
 	pin "github.com/ipsn/go-ipfs/pin"
+	pinfollow "github.com/ipsn/go-ipfs/pinfollow"
 
 	bserv "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-blockservice"
 	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
@@ -22,6 +24,7 @@ import (
 	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
 	dag "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-merkledag"
 	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
 )
 
 var PinCmd = &cmds.Command{
@@ -35,6 +38,10 @@ var PinCmd = &cmds.Command{
 		"ls":     listPinCmd,
 		"verify": verifyPinCmd,
 		"update": updatePinCmd,
+		"follow": followPinCmd,
+		"fsck":   fsckPinCmd,
+		"status": statusPinCmd,
+		"queue":  queuePinCmd,
 	},
 }
 
@@ -42,14 +49,27 @@ type PinOutput struct {
 	Pins []string
 }
 
+// QueuedPin identifies one background pin request created by
+// 'pin add --background'.
+type QueuedPin struct {
+	ID  string
+	Cid string
+}
+
 type AddPinOutput struct {
 	Pins     []string
-	Progress int `json:",omitempty"`
+	Queued   []QueuedPin `json:",omitempty"`
+	Progress int         `json:",omitempty"`
 }
 
 const (
 	pinRecursiveOptionName = "recursive"
 	pinProgressOptionName  = "progress"
+	pinNameOptionName       = "name"
+	pinMetaOptionName       = "meta"
+	pinBeforeOptionName     = "before"
+	pinAfterOptionName      = "after"
+	pinBackgroundOptionName = "background"
 )
 
 var addPinCmd = &cmds.Command{
@@ -64,6 +84,10 @@ var addPinCmd = &cmds.Command{
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption(pinRecursiveOptionName, "r", "Recursively pin the object linked to by the specified object(s).").WithDefault(true),
 		cmdkit.BoolOption(pinProgressOptionName, "Show progress"),
+		cmdkit.StringOption(pinNameOptionName, "n", "An optional name for the created pin(s)."),
+		cmdkit.StringOption(pinMetaOptionName, "Comma separated key=value metadata to attach to the created pin(s)."),
+		cmdkit.BoolOption(provideOptionName, "Announce the pinned roots when batched root announcements are enabled.").WithDefault(true),
+		cmdkit.BoolOption(pinBackgroundOptionName, "Queue the pin and return request ids immediately instead of waiting. See 'pin status' and 'pin queue ls'."),
 	},
 	Type: AddPinOutput{},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
@@ -82,16 +106,68 @@ var addPinCmd = &cmds.Command{
 		// set recursive flag
 		recursive, _ := req.Options[pinRecursiveOptionName].(bool)
 		showProgress, _ := req.Options[pinProgressOptionName].(bool)
+		name, _ := req.Options[pinNameOptionName].(string)
+		metaStr, _ := req.Options[pinMetaOptionName].(string)
+		provide, _ := req.Options[provideOptionName].(bool)
+
+		labels, err := parsePinLabels(metaStr)
+		if err != nil {
+			return err
+		}
+
+		setMeta := func(added []cid.Cid) error {
+			if provide && n.RootProvider != nil {
+				for _, c := range added {
+					n.RootProvider.Enqueue(c)
+				}
+			}
+			if name == "" && len(labels) == 0 {
+				return nil
+			}
+			for _, c := range added {
+				if err := n.Pinning.SetMeta(c, &pin.Meta{Name: name, Labels: labels}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
 
 		if err := req.ParseBodyArgs(); err != nil {
 			return err
 		}
 
+		background, _ := req.Options[pinBackgroundOptionName].(bool)
+		if background {
+			var queued []QueuedPin
+			for _, a := range req.Arguments {
+				pth, err := iface.ParsePath(a)
+				if err != nil {
+					return err
+				}
+				rp, err := api.ResolvePath(req.Context, pth)
+				if err != nil {
+					return err
+				}
+				rq, err := n.PinQueue.Add(rp.Cid(), recursive)
+				if err != nil {
+					return err
+				}
+				if err := setMeta([]cid.Cid{rp.Cid()}); err != nil {
+					return err
+				}
+				queued = append(queued, QueuedPin{ID: rq.ID, Cid: rq.Cid.String()})
+			}
+			return cmds.EmitOnce(res, &AddPinOutput{Queued: queued})
+		}
+
 		if !showProgress {
 			added, err := corerepo.Pin(n.Pinning, api, req.Context, req.Arguments, recursive)
 			if err != nil {
 				return err
 			}
+			if err := setMeta(added); err != nil {
+				return err
+			}
 			return cmds.EmitOnce(res, &AddPinOutput{Pins: cidsToStrings(added)})
 		}
 
@@ -124,6 +200,9 @@ var addPinCmd = &cmds.Command{
 						return err
 					}
 				}
+				if err := setMeta(val.pins); err != nil {
+					return err
+				}
 				return res.Emit(&AddPinOutput{Pins: cidsToStrings(val.pins)})
 			case <-ticker.C:
 				if err := res.Emit(&AddPinOutput{Progress: v.Value()}); err != nil {
@@ -145,6 +224,10 @@ var addPinCmd = &cmds.Command{
 				pintype = "directly"
 			}
 
+			for _, q := range out.Queued {
+				fmt.Fprintf(w, "queued %s %s\n", q.ID, q.Cid)
+			}
+
 			for _, k := range out.Pins {
 				fmt.Fprintf(w, "pinned %s %s\n", k, pintype)
 			}
@@ -167,7 +250,7 @@ var addPinCmd = &cmds.Command{
 				if !ok {
 					return e.TypeErr(out, v)
 				}
-				if out.Pins == nil {
+				if out.Pins == nil && out.Queued == nil {
 					// this can only happen if the progress option is set
 					fmt.Fprintf(os.Stderr, "Fetched/Processed %d nodes\r", out.Progress)
 				} else {
@@ -287,6 +370,9 @@ Example:
 	Options: []cmdkit.Option{
 		cmdkit.StringOption(pinTypeOptionName, "t", "The type of pinned keys to list. Can be \"direct\", \"indirect\", \"recursive\", or \"all\".").WithDefault("all"),
 		cmdkit.BoolOption(pinQuietOptionName, "q", "Write just hashes of objects."),
+		cmdkit.StringOption(pinNameOptionName, "n", "Only list pins with this exact name."),
+		cmdkit.StringOption(pinBeforeOptionName, "Only list pins created before this RFC3339 timestamp."),
+		cmdkit.StringOption(pinAfterOptionName, "Only list pins created after this RFC3339 timestamp."),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		n, err := cmdenv.GetNode(env)
@@ -311,6 +397,16 @@ Example:
 			return err
 		}
 
+		name, _ := req.Options[pinNameOptionName].(string)
+		before, err := parsePinTime(req, pinBeforeOptionName)
+		if err != nil {
+			return err
+		}
+		after, err := parsePinTime(req, pinAfterOptionName)
+		if err != nil {
+			return err
+		}
+
 		var keys map[string]RefKeyObject
 
 		if len(req.Arguments) > 0 {
@@ -323,6 +419,11 @@ Example:
 			return err
 		}
 
+		keys, err = pinLsMeta(keys, n, name, before, after)
+		if err != nil {
+			return err
+		}
+
 		return cmds.EmitOnce(res, &RefKeyList{Keys: keys})
 	},
 	Type: RefKeyList{},
@@ -333,6 +434,8 @@ Example:
 			for k, v := range out.Keys {
 				if quiet {
 					fmt.Fprintf(w, "%s\n", k)
+				} else if v.Name != "" {
+					fmt.Fprintf(w, "%s %s %s\n", k, v.Type, v.Name)
 				} else {
 					fmt.Fprintf(w, "%s %s\n", k, v.Type)
 				}
@@ -343,6 +446,96 @@ Example:
 	},
 }
 
+// PinQueueList is the output type of 'pin queue ls'
+type PinQueueList struct {
+	Requests []pin.Request
+}
+
+func pinRequestString(r *pin.Request) string {
+	s := fmt.Sprintf("%s %s %s", r.ID, r.Cid, r.State)
+	if r.State == pin.StatePinning {
+		s += fmt.Sprintf(" %d blocks fetched", r.Progress)
+	}
+	if r.Error != "" {
+		s += " " + r.Error
+	}
+	return s
+}
+
+var statusPinCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the status of a background pin request.",
+		ShortDescription: `
+'ipfs pin status' reports the state of a pin request queued with
+'ipfs pin add --background': queued, pinning (with the number of blocks
+fetched so far), pinned or failed. Requests are only tracked for the
+lifetime of the daemon.
+`,
+	},
+
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("request-id", true, false, "ID of the pin request to look up."),
+	},
+	Type: pin.Request{},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		r, ok := n.PinQueue.Get(req.Arguments[0])
+		if !ok {
+			return fmt.Errorf("unknown pin request '%s'", req.Arguments[0])
+		}
+
+		return cmds.EmitOnce(res, &r)
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *pin.Request) error {
+			fmt.Fprintln(w, pinRequestString(out))
+			return nil
+		}),
+	},
+}
+
+var queuePinCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Inspect the background pin queue.",
+	},
+
+	Subcommands: map[string]*cmds.Command{
+		"ls": queueLsPinCmd,
+	},
+}
+
+var queueLsPinCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List all background pin requests.",
+		ShortDescription: `
+Lists the pin requests queued with 'ipfs pin add --background' and their
+current state, in submission order.
+`,
+	},
+
+	Type: PinQueueList{},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &PinQueueList{Requests: n.PinQueue.List()})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *PinQueueList) error {
+			for i := range out.Requests {
+				fmt.Fprintln(w, pinRequestString(&out.Requests[i]))
+			}
+			return nil
+		}),
+	},
+}
+
 const (
 	pinUnpinOptionName = "unpin"
 )
@@ -447,14 +640,249 @@ var verifyPinCmd = &cmds.Command{
 	},
 }
 
+const (
+	pinRmOptionName     = "rm"
+	pinRepairOptionName = "repair"
+)
+
+// PinFsckRes is the result reported for each pin checked by 'pin fsck'.
+type PinFsckRes struct {
+	Cid     string
+	Type    string
+	Status  string
+	Missing []string `json:",omitempty"`
+	Err     string   `json:",omitempty"`
+}
+
+var fsckPinCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Find pins whose blocks are missing from the blockstore.",
+		ShortDescription: `
+Checks every direct and recursive pin against the local blockstore and
+reports pins whose root is gone ("missing-root") or whose DAG can only
+be read partially ("incomplete"), as happens after disk corruption.
+Broken pins cause confusing 'pin verify' and 'repo gc' failures.
+
+With --repair the missing blocks are fetched from the network; with
+--rm the broken pins are dropped from the pinset instead.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(pinVerboseOptionName, "Also write the hashes of intact pins."),
+		cmdkit.BoolOption(pinRmOptionName, "Remove broken pins from the pinset."),
+		cmdkit.BoolOption(pinRepairOptionName, "Fetch the missing blocks from the network."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		verbose, _ := req.Options[pinVerboseOptionName].(bool)
+		doRm, _ := req.Options[pinRmOptionName].(bool)
+		doRepair, _ := req.Options[pinRepairOptionName].(bool)
+
+		if doRm && doRepair {
+			return fmt.Errorf("the --rm and --repair options can not be used at the same time")
+		}
+		if doRepair && !n.OnlineMode() {
+			return ErrNotOnline
+		}
+
+		bs := n.Blocks.Blockstore()
+		getLinks := dag.GetLinksWithDAG(dag.NewDAGService(bserv.New(bs, offline.Exchange(bs))))
+
+		removed := false
+		checkPin := func(c cid.Cid, typ string, mode pin.Mode) error {
+			out := PinFsckRes{Cid: c.String(), Type: typ}
+
+			has, err := bs.Has(c)
+			if err != nil {
+				return err
+			}
+
+			var missing []string
+			if typ == "recursive" {
+				missing = pinFsckMissing(req.Context, getLinks, c)
+			} else if !has {
+				missing = []string{c.String()}
+			}
+
+			switch {
+			case !has:
+				out.Status = "missing-root"
+			case len(missing) > 0:
+				out.Status = "incomplete"
+			default:
+				out.Status = "ok"
+			}
+
+			if out.Status != "ok" {
+				out.Missing = missing
+				switch {
+				case doRepair:
+					if err := dag.FetchGraph(req.Context, c, n.DAG); err != nil {
+						out.Err = fmt.Sprintf("repair failed: %s", err)
+					} else {
+						out.Status = "repaired"
+						out.Missing = nil
+					}
+				case doRm:
+					n.Pinning.RemovePinWithMode(c, mode)
+					removed = true
+					out.Status = "removed"
+				}
+			} else if !verbose {
+				return nil
+			}
+
+			return res.Emit(&out)
+		}
+
+		for _, c := range n.Pinning.RecursiveKeys() {
+			if err := checkPin(c, "recursive", pin.Recursive); err != nil {
+				return err
+			}
+		}
+		for _, c := range n.Pinning.DirectKeys() {
+			if err := checkPin(c, "direct", pin.Direct); err != nil {
+				return err
+			}
+		}
+
+		if removed {
+			return n.Pinning.Flush()
+		}
+
+		return nil
+	},
+	Type: PinFsckRes{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *PinFsckRes) error {
+			fmt.Fprintf(w, "%s %s %s\n", out.Cid, out.Type, out.Status)
+			for _, m := range out.Missing {
+				fmt.Fprintf(w, "  missing: %s\n", m)
+			}
+			if out.Err != "" {
+				fmt.Fprintf(w, "  %s\n", out.Err)
+			}
+			return nil
+		}),
+	},
+}
+
+// pinFsckMissing walks the DAG under root using only the local
+// blockstore and returns the cids of blocks that cannot be read.
+func pinFsckMissing(ctx context.Context, getLinks dag.GetLinks, root cid.Cid) []string {
+	var missing []string
+	visited := cid.NewSet()
+
+	var walk func(c cid.Cid)
+	walk = func(c cid.Cid) {
+		if !visited.Visit(c) {
+			return
+		}
+
+		links, err := getLinks(ctx, c)
+		if err != nil {
+			missing = append(missing, c.String())
+			return
+		}
+
+		for _, lnk := range links {
+			walk(lnk.Cid)
+		}
+	}
+	walk(root)
+
+	return missing
+}
+
 type RefKeyObject struct {
-	Type string
+	Type   string
+	Name   string            `json:",omitempty"`
+	Labels map[string]string `json:",omitempty"`
 }
 
 type RefKeyList struct {
 	Keys map[string]RefKeyObject
 }
 
+// parsePinLabels parses the value of --meta, a comma separated list of
+// key=value pairs.
+func parsePinLabels(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid metadata pair %q, expected key=value", pair)
+		}
+		labels[kv[0]] = kv[1]
+	}
+	return labels, nil
+}
+
+// parsePinTime parses an RFC3339 timestamp option, returning the zero
+// time when the option is not set.
+func parsePinTime(req *cmds.Request, opt string) (time.Time, error) {
+	s, ok := req.Options[opt].(string)
+	if !ok || s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing --%s: %s", opt, err)
+	}
+	return t, nil
+}
+
+// pinLsMeta fills in the pin metadata of the listed keys and, when any
+// of the filters is set, drops the entries that do not match. Indirect
+// pins carry no metadata of their own and never match a filter.
+func pinLsMeta(keys map[string]RefKeyObject, n *core.IpfsNode, name string, before, after time.Time) (map[string]RefKeyObject, error) {
+	filtering := name != "" || !before.IsZero() || !after.IsZero()
+
+	out := make(map[string]RefKeyObject, len(keys))
+	for k, v := range keys {
+		if v.Type == "direct" || v.Type == "recursive" {
+			c, err := cid.Decode(k)
+			if err != nil {
+				return nil, err
+			}
+			meta, err := n.Pinning.GetMeta(c)
+			if err != nil {
+				return nil, err
+			}
+			if meta != nil {
+				v.Name = meta.Name
+				v.Labels = meta.Labels
+			}
+			if filtering {
+				if meta == nil {
+					continue
+				}
+				if name != "" && meta.Name != name {
+					continue
+				}
+				if !before.IsZero() && !meta.CreatedAt.Before(before) {
+					continue
+				}
+				if !after.IsZero() && !meta.CreatedAt.After(after) {
+					continue
+				}
+			}
+		} else if filtering {
+			continue
+		}
+		out[k] = v
+	}
+
+	return out, nil
+}
+
 func pinLsKeys(ctx context.Context, args []string, typeStr string, n *core.IpfsNode, api iface.CoreAPI) (map[string]RefKeyObject, error) {
 
 	mode, ok := pin.StringToMode(typeStr)
@@ -636,3 +1064,139 @@ func cidsToStrings(cs []cid.Cid) []string {
 	}
 	return out
 }
+
+var followPinCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Mirror another peer's published pinset.",
+		ShortDescription: `
+'ipfs pin follow' manages followed pinsets. A followed peer publishes
+a JSON pinset document ({"Pins": ["<cid>", ...]}) under its IPNS
+name; this node periodically resolves the name and pins or unpins to
+match. The IPNS record carries the publisher's signature, so the
+pinset is authenticated.
+`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"add": followPinAddCmd,
+		"ls":  followPinLsCmd,
+		"rm":  followPinRmCmd,
+	},
+}
+
+// FollowList is the output of 'ipfs pin follow ls'.
+type FollowList struct {
+	Follows []pinfollow.Follow
+}
+
+func pinFollowService(env cmds.Environment) (*pinfollow.Service, error) {
+	n, err := cmdenv.GetNode(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.PinFollow == nil {
+		return nil, ErrNotOnline
+	}
+	return n.PinFollow, nil
+}
+
+var followPinAddCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Start following a peer's pinset.",
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("peer ID", true, false, "ID of the peer whose pinset to mirror."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		svc, err := pinFollowService(env)
+		if err != nil {
+			return err
+		}
+
+		pid, err := peer.IDB58Decode(req.Arguments[0])
+		if err != nil {
+			return fmt.Errorf("invalid peer id: %s", err)
+		}
+
+		f, err := svc.Add(req.Context, pid)
+		if err != nil {
+			return err
+		}
+		return cmds.EmitOnce(res, &FollowList{Follows: []pinfollow.Follow{*f}})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *FollowList) error {
+			for _, f := range out.Follows {
+				fmt.Fprintf(w, "following pinset of %s\n", f.Peer)
+			}
+			return nil
+		}),
+	},
+	Type: FollowList{},
+}
+
+var followPinLsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List followed pinsets.",
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		svc, err := pinFollowService(env)
+		if err != nil {
+			return err
+		}
+		return cmds.EmitOnce(res, &FollowList{Follows: svc.List()})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *FollowList) error {
+			for _, f := range out.Follows {
+				fmt.Fprintf(w, "%s: %d pins", f.Peer, len(f.Pins))
+				if !f.LastSync.IsZero() {
+					fmt.Fprintf(w, ", last sync %s", f.LastSync.Format(time.RFC3339))
+				} else {
+					fmt.Fprintf(w, ", never synced")
+				}
+				if f.LastError != "" {
+					fmt.Fprintf(w, ", last error: %s", f.LastError)
+				}
+				fmt.Fprintln(w)
+			}
+			return nil
+		}),
+	},
+	Type: FollowList{},
+}
+
+var followPinRmCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Stop following a peer's pinset.",
+		ShortDescription: `
+Stops following the given peer and unpins the content that was
+mirrored on its behalf.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("peer ID", true, false, "ID of the peer to stop following."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		svc, err := pinFollowService(env)
+		if err != nil {
+			return err
+		}
+
+		pid, err := peer.IDB58Decode(req.Arguments[0])
+		if err != nil {
+			return fmt.Errorf("invalid peer id: %s", err)
+		}
+
+		if err := svc.Remove(req.Context, pid); err != nil {
+			return err
+		}
+		return cmds.EmitOnce(res, &MessageOutput{fmt.Sprintf("stopped following pinset of %s\n", pid.Pretty())})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *MessageOutput) error {
+			fmt.Fprint(w, out.Message)
+			return nil
+		}),
+	},
+	Type: MessageOutput{},
+}
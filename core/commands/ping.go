@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"strings"
 	"time"
 
@@ -25,12 +26,31 @@ type PingResult struct {
 	Success bool
 	Time    time.Duration
 	Text    string
+
+	Stats *PingStats `json:",omitempty"`
+}
+
+// PingStats summarizes a finished ping run.
+type PingStats struct {
+	Sent     int
+	Received int
+	Loss     float64 // percent
+
+	Min    time.Duration
+	Avg    time.Duration
+	Max    time.Duration
+	StdDev time.Duration
 }
 
 const (
-	pingCountOptionName = "count"
+	pingCountOptionName    = "count"
+	pingSizeOptionName     = "size"
+	pingIntervalOptionName = "interval"
 )
 
+// maxPingPayload bounds the --size option.
+const maxPingPayload = 128 * 1024
+
 // ErrPingSelf is returned when the user attempts to ping themself.
 var ErrPingSelf = errors.New("error: can't ping self")
 
@@ -48,6 +68,8 @@ trip latency information.
 	},
 	Options: []cmdkit.Option{
 		cmdkit.IntOption(pingCountOptionName, "n", "Number of ping messages to send.").WithDefault(10),
+		cmdkit.IntOption(pingSizeOptionName, "s", "Payload size in bytes.").WithDefault(ping.PingSize),
+		cmdkit.StringOption(pingIntervalOptionName, "i", "Time between ping messages.").WithDefault("1s"),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		n, err := cmdenv.GetNode(env)
@@ -78,6 +100,20 @@ trip latency information.
 			return fmt.Errorf("error: ping count must be greater than 0, was %d", numPings)
 		}
 
+		size, _ := req.Options[pingSizeOptionName].(int)
+		if size <= 0 || size > maxPingPayload {
+			return fmt.Errorf("error: ping payload size must be between 1 and %d bytes, was %d", maxPingPayload, size)
+		}
+
+		intervalStr, _ := req.Options[pingIntervalOptionName].(string)
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			return fmt.Errorf("error: invalid ping interval: %s", err)
+		}
+		if interval <= 0 {
+			return fmt.Errorf("error: ping interval must be greater than 0, was %s", interval)
+		}
+
 		if len(n.Peerstore.Addrs(pid)) == 0 {
 			// Make sure we can find the node in question
 			if err := res.Emit(&PingResult{
@@ -97,15 +133,15 @@ trip latency information.
 		}
 
 		if err := res.Emit(&PingResult{
-			Text:    fmt.Sprintf("PING %s.", pid.Pretty()),
+			Text:    fmt.Sprintf("PING %s with %d bytes of data.", pid.Pretty(), size),
 			Success: true,
 		}); err != nil {
 			return err
 		}
 
-		ctx, cancel := context.WithTimeout(req.Context, kPingTimeout*time.Duration(numPings))
+		ctx, cancel := context.WithTimeout(req.Context, (kPingTimeout+interval)*time.Duration(numPings))
 		defer cancel()
-		pings, err := ping.Ping(ctx, n.PeerHost, pid)
+		pings, err := ping.PingWithPayload(ctx, n.PeerHost, pid, size)
 		if err != nil {
 			return res.Emit(&PingResult{
 				Success: false,
@@ -113,8 +149,8 @@ trip latency information.
 			})
 		}
 
-		var total time.Duration
-		ticker := time.NewTicker(time.Second)
+		var times []time.Duration
+		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		for i := 0; i < numPings; i++ {
 			t, ok := <-pings
@@ -129,23 +165,41 @@ trip latency information.
 				return err
 			}
 
-			total += t
+			times = append(times, t)
 
+			if i == numPings-1 {
+				break
+			}
 			select {
 			case <-ticker.C:
 			case <-ctx.Done():
 				return ctx.Err()
 			}
 		}
-		averagems := total.Seconds() * 1000 / float64(numPings)
+
 		return res.Emit(&PingResult{
-			Success: true,
-			Text:    fmt.Sprintf("Average latency: %.2fms", averagems),
+			Success: len(times) > 0,
+			Text:    fmt.Sprintf("--- %s ping statistics ---", pid.Pretty()),
+			Stats:   pingStats(numPings, times),
 		})
 	},
 	Type: PingResult{},
 	Encoders: cmds.EncoderMap{
 		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *PingResult) error {
+			if out.Stats != nil {
+				if len(out.Text) > 0 {
+					fmt.Fprintln(w, out.Text)
+				}
+				st := out.Stats
+				fmt.Fprintf(w, "%d packets transmitted, %d received, %.1f%% packet loss\n",
+					st.Sent, st.Received, st.Loss)
+				if st.Received > 0 {
+					ms := func(d time.Duration) float64 { return d.Seconds() * 1000 }
+					fmt.Fprintf(w, "rtt min/avg/max/stddev = %.2f/%.2f/%.2f/%.2f ms\n",
+						ms(st.Min), ms(st.Avg), ms(st.Max), ms(st.StdDev))
+				}
+				return nil
+			}
 			if len(out.Text) > 0 {
 				fmt.Fprintln(w, out.Text)
 			} else if out.Success {
@@ -158,6 +212,44 @@ trip latency information.
 	},
 }
 
+// pingStats computes the summary for sent pings and the round-trip
+// times of those answered.
+func pingStats(sent int, times []time.Duration) *PingStats {
+	st := &PingStats{
+		Sent:     sent,
+		Received: len(times),
+	}
+	if sent > 0 {
+		st.Loss = float64(sent-len(times)) / float64(sent) * 100
+	}
+	if len(times) == 0 {
+		return st
+	}
+
+	var total time.Duration
+	st.Min = times[0]
+	st.Max = times[0]
+	for _, t := range times {
+		total += t
+		if t < st.Min {
+			st.Min = t
+		}
+		if t > st.Max {
+			st.Max = t
+		}
+	}
+	st.Avg = total / time.Duration(len(times))
+
+	var sqsum float64
+	for _, t := range times {
+		d := float64(t - st.Avg)
+		sqsum += d * d
+	}
+	st.StdDev = time.Duration(math.Sqrt(sqsum / float64(len(times))))
+
+	return st
+}
+
 func ParsePeerParam(text string) (ma.Multiaddr, peer.ID, error) {
 	// Multiaddr
 	if strings.HasPrefix(text, "/") {
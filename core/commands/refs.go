@@ -125,14 +125,34 @@ NOTE: List all references recursively by using the flag '-r'.
 	Type:     RefWrapper{},
 }
 
+const (
+	refsCodecOptionName     = "codec"
+	refsMinSizeOptionName   = "min-size"
+	refsMaxSizeOptionName   = "max-size"
+	refsCountOnlyOptionName = "count-only"
+)
+
 var RefsLocalCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "List all local references.",
 		ShortDescription: `
 Displays the hashes of all local objects.
+
+The output can be narrowed down server-side while the blockstore is
+being iterated, which is much cheaper than piping every hash to the
+client and filtering there:
+
+    $ ipfs refs local --codec=dag-cbor
+    $ ipfs refs local --min-size=262144 --count-only
 `,
 	},
 
+	Options: []cmdkit.Option{
+		cmdkit.StringOption(refsCodecOptionName, "Only list blocks with this cid codec (e.g. 'dag-pb', 'dag-cbor', 'raw')."),
+		cmdkit.Int64Option(refsMinSizeOptionName, "Only list blocks of at least this many bytes.").WithDefault(int64(-1)),
+		cmdkit.Int64Option(refsMaxSizeOptionName, "Only list blocks of at most this many bytes.").WithDefault(int64(-1)),
+		cmdkit.BoolOption(refsCountOnlyOptionName, "n", "Print only the number of matching blocks."),
+	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		ctx := req.Context
 		n, err := cmdenv.GetNode(env)
@@ -140,19 +160,61 @@ Displays the hashes of all local objects.
 			return err
 		}
 
+		var codec uint64
+		codecName, filterCodec := req.Options[refsCodecOptionName].(string)
+		if filterCodec && codecName != "" {
+			c, ok := cid.Codecs[codecName]
+			if !ok {
+				return fmt.Errorf("unknown cid codec: %q", codecName)
+			}
+			codec = c
+		} else {
+			filterCodec = false
+		}
+
+		minSize, _ := req.Options[refsMinSizeOptionName].(int64)
+		maxSize, _ := req.Options[refsMaxSizeOptionName].(int64)
+		filterSize := minSize >= 0 || maxSize >= 0
+		countOnly, _ := req.Options[refsCountOnlyOptionName].(bool)
+
 		// todo: make async
 		allKeys, err := n.Blockstore.AllKeysChan(ctx)
 		if err != nil {
 			return err
 		}
 
+		var count uint64
 		for k := range allKeys {
-			err := res.Emit(&RefWrapper{Ref: k.String()})
-			if err != nil {
+			if filterCodec && k.Type() != codec {
+				continue
+			}
+			if filterSize {
+				// sizes are not part of the key iteration, so the
+				// size filters cost one extra datastore lookup each
+				size, err := n.Blockstore.GetSize(k)
+				if err != nil {
+					return err
+				}
+				if minSize >= 0 && int64(size) < minSize {
+					continue
+				}
+				if maxSize >= 0 && int64(size) > maxSize {
+					continue
+				}
+			}
+
+			if countOnly {
+				count++
+				continue
+			}
+			if err := res.Emit(&RefWrapper{Ref: k.String()}); err != nil {
 				return err
 			}
 		}
 
+		if countOnly {
+			return res.Emit(&RefWrapper{Ref: fmt.Sprintf("%d", count)})
+		}
 		return nil
 	},
 	Encoders: refsEncoderMap,
@@ -11,12 +11,15 @@ import (
 	"strings"
 	"sync"
 	"text/tabwriter"
+	"time"
 
 	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
 	corerepo "github.com/ipsn/go-ipfs/core/corerepo"
+	gc "github.com/ipsn/go-ipfs/pin/gc"
 	fsrepo "github.com/ipsn/go-ipfs/repo/fsrepo"
 
 	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	lockfile "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-fs-lock"
 	bstore "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-blockstore"
 	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
 	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
@@ -39,6 +42,7 @@ var RepoCmd = &cmds.Command{
 		"stat":    repoStatCmd,
 		"gc":      repoGcCmd,
 		"fsck":    repoFsckCmd,
+		"unlock":  repoUnlockCmd,
 		"version": repoVersionCmd,
 		"verify":  repoVerifyCmd,
 	},
@@ -53,6 +57,8 @@ type GcResult struct {
 const (
 	repoStreamErrorsOptionName = "stream-errors"
 	repoQuietOptionName        = "quiet"
+	repoMaxDurationOptionName  = "max-duration"
+	repoTargetFreeOptionName   = "target-free-bytes"
 )
 
 var repoGcCmd = &cmds.Command{
@@ -62,11 +68,19 @@ var repoGcCmd = &cmds.Command{
 'ipfs repo gc' is a plumbing command that will sweep the local
 set of stored objects and remove ones that are not pinned in
 order to reclaim hard disk space.
+
+By default the whole repo is swept in one run, which can pause other
+operations for minutes on large repos. The run can instead be bounded
+with '--max-duration' and/or '--target-free-bytes'; a bounded run stops
+early once a budget is exhausted, leaving the remaining garbage for a
+later run.
 `,
 	},
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption(repoStreamErrorsOptionName, "Stream errors."),
 		cmdkit.BoolOption(repoQuietOptionName, "q", "Write minimal output."),
+		cmdkit.StringOption(repoMaxDurationOptionName, "Stop the sweep after this long, e.g. \"5m\"."),
+		cmdkit.Uint64Option(repoTargetFreeOptionName, "Stop the sweep once this many bytes have been reclaimed."),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		n, err := cmdenv.GetNode(env)
@@ -76,7 +90,18 @@ order to reclaim hard disk space.
 
 		streamErrors, _ := req.Options[repoStreamErrorsOptionName].(bool)
 
-		gcOutChan := corerepo.GarbageCollectAsync(n, req.Context)
+		var opts gc.Opts
+		if d, ok := req.Options[repoMaxDurationOptionName].(string); ok {
+			opts.MaxDuration, err = time.ParseDuration(d)
+			if err != nil {
+				return fmt.Errorf("parsing --%s: %s", repoMaxDurationOptionName, err)
+			}
+		}
+		if target, ok := req.Options[repoTargetFreeOptionName].(uint64); ok {
+			opts.TargetFreeBytes = target
+		}
+
+		gcOutChan := corerepo.GarbageCollectAsyncWithOpts(n, req.Context, opts)
 
 		if streamErrors {
 			errs := false
@@ -210,6 +235,49 @@ Version         string The repo version.
 	},
 }
 
+var repoUnlockCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Remove a stale repo lock.",
+		ShortDescription: `
+'ipfs repo unlock' removes the repo lock if the process that took it
+no longer exists, e.g. after a crash or container restart. Unlike
+'ipfs repo fsck' it refuses to touch a lock whose holder is still
+alive, or whose holder cannot be determined.
+`,
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		configRoot, err := cmdenv.GetConfigRoot(env)
+		if err != nil {
+			return err
+		}
+
+		locked, err := fsrepo.LockedByOtherProcess(configRoot)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return cmds.EmitOnce(res, &MessageOutput{"Repo is not locked.\n"})
+		}
+
+		if !lockfile.Stale(configRoot, fsrepo.LockFile) {
+			return errors.New("repo lock is held by a live process (or its holder is unknown), not removing")
+		}
+
+		if err := lockfile.Clear(configRoot, fsrepo.LockFile); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &MessageOutput{"Stale repo lock has been removed.\n"})
+	},
+	Type: MessageOutput{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *MessageOutput) error {
+			fmt.Fprintf(w, out.Message)
+			return nil
+		}),
+	},
+}
+
 var repoFsckCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Remove repo lockfiles.",
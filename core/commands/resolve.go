@@ -72,6 +72,8 @@ Resolve the value of an IPFS DAG path:
 		cmdkit.BoolOption(resolveRecursiveOptionName, "r", "Resolve until the result is an IPFS name."),
 		cmdkit.IntOption(resolveDhtRecordCountOptionName, "dhtrc", "Number of records to request for DHT resolution."),
 		cmdkit.StringOption(resolveDhtTimeoutOptionName, "dhtt", "Max time to collect values during DHT resolution eg \"30s\". Pass 0 for no timeout."),
+		cmdenv.FetchTimeoutOption(),
+		cmdenv.CidBaseOption(),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		api, err := cmdenv.GetApi(env, req)
@@ -79,6 +81,12 @@ Resolve the value of an IPFS DAG path:
 			return err
 		}
 
+		ctx, cancel, err := cmdenv.FetchContext(req)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+
 		name := req.Arguments[0]
 		recursive, _ := req.Options[resolveRecursiveOptionName].(bool)
 
@@ -103,10 +111,10 @@ Resolve the value of an IPFS DAG path:
 				}
 				ropts = append(ropts, options.Name.ResolveOption(nsopts.DhtTimeout(d)))
 			}
-			p, err := api.Name().Resolve(req.Context, name, ropts...)
+			p, err := api.Name().Resolve(ctx, name, ropts...)
 			// ErrResolveRecursion is fine
 			if err != nil && err != ns.ErrResolveRecursion {
-				return err
+				return cmdenv.FetchError(ctx, err)
 			}
 			return cmds.EmitOnce(res, &ncmd.ResolvedPath{Path: path.Path(p.String())})
 		}
@@ -117,9 +125,9 @@ Resolve the value of an IPFS DAG path:
 			return err
 		}
 
-		rp, err := api.ResolvePath(req.Context, p)
+		rp, err := api.ResolvePath(ctx, p)
 		if err != nil {
-			return err
+			return cmdenv.FetchError(ctx, err)
 		}
 
 		if rp.Remainder() != "" {
@@ -128,7 +136,12 @@ Resolve the value of an IPFS DAG path:
 			return fmt.Errorf("found non-link at given path")
 		}
 
-		return cmds.EmitOnce(res, &ncmd.ResolvedPath{Path: path.Path("/" + rp.Namespace() + "/" + rp.Cid().String())})
+		cidEnc, err := cmdenv.CidEncoder(req)
+		if err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &ncmd.ResolvedPath{Path: path.Path("/" + rp.Namespace() + "/" + cidEnc(rp.Cid()))})
 	},
 	Encoders: cmds.EncoderMap{
 		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, rp *ncmd.ResolvedPath) error {
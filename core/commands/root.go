@@ -109,6 +109,8 @@ var CommandsDaemonCmd = CommandsCmd(Root)
 
 var rootSubcommands = map[string]*cmds.Command{
 	"add":       AddCmd,
+	"auth":      AuthCmd,
+	"ethofs":    EthofsCmd,
 	"bitswap":   BitswapCmd,
 	"block":     BlockCmd,
 	"cat":       CatCmd,
@@ -137,6 +139,7 @@ var rootSubcommands = map[string]*cmds.Command{
 	"p2p":       P2PCmd,
 	"refs":      RefsCmd,
 	"resolve":   ResolveCmd,
+	"routing":   RoutingCmd,
 	"swarm":     SwarmCmd,
 	"tar":       TarCmd,
 	"file":      unixfs.UnixFSCmd,
@@ -184,6 +187,7 @@ var rootROSubcommands = map[string]*cmds.Command{
 		Subcommands: map[string]*cmds.Command{
 			"get":     dag.DagGetCmd,
 			"resolve": dag.DagResolveCmd,
+			"export":  dag.DagExportCmd,
 		},
 	},
 	"resolve": ResolveCmd,
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
+
+	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+)
+
+var RoutingCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Issue routing announcements.",
+		ShortDescription: `
+'ipfs routing' announces content to the network immediately, instead
+of waiting for the background reprovider to get to it.
+`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"provide":   provideRefDhtCmd,
+		"reprovide": routingReprovideCmd,
+	},
+}
+
+var routingReprovideCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Trigger a full reprovide sweep.",
+		ShortDescription: `
+Immediately announce everything the reprovider strategy covers to the
+network, instead of waiting for the next scheduled sweep.
+`,
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		if !nd.OnlineMode() {
+			return ErrNotOnline
+		}
+
+		if err := res.Emit(&MessageOutput{"starting reprovide sweep\n"}); err != nil {
+			return err
+		}
+
+		if err := nd.Reprovider.Trigger(req.Context); err != nil {
+			return err
+		}
+
+		return res.Emit(&MessageOutput{"reprovide sweep complete\n"})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *MessageOutput) error {
+			fmt.Fprint(w, out.Message)
+			return nil
+		}),
+	},
+	Type: MessageOutput{},
+}
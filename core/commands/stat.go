@@ -6,6 +6,7 @@ import (
 	"os"
 	"time"
 
+	accounting "github.com/ipsn/go-ipfs/accounting"
 	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
 
 	humanize "github.com/dustin/go-humanize"
@@ -27,9 +28,10 @@ for your IPFS node.`,
 	},
 
 	Subcommands: map[string]*cmds.Command{
-		"bw":      statBwCmd,
-		"repo":    repoStatCmd,
-		"bitswap": bitswapStatCmd,
+		"bw":          statBwCmd,
+		"repo":        repoStatCmd,
+		"bitswap":     bitswapStatCmd,
+		"serve-proof": statServeProofCmd,
 	},
 }
 
@@ -192,3 +194,61 @@ func printStats(out io.Writer, bs *metrics.Stats) {
 	fmt.Fprintf(out, "RateIn: %s/s\n", humanize.Bytes(uint64(bs.RateIn)))
 	fmt.Fprintf(out, "RateOut: %s/s\n", humanize.Bytes(uint64(bs.RateOut)))
 }
+
+const statVerifyOptionName = "verify"
+
+var statServeProofCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Export signed serve accounting records.",
+		ShortDescription: `
+'ipfs stats serve-proof' exports the bytes this node served to other
+peers, broken down per peer and per content hash over fixed periods.
+Each period is signed with the node's private key so the records can
+be audited for network reward eligibility.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(statVerifyOptionName, "Verify the period signatures before emitting."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		nd, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+		if nd.Accounting == nil {
+			return fmt.Errorf("serve accounting is only available when the daemon is online")
+		}
+
+		proof, err := nd.Accounting.Proof()
+		if err != nil {
+			return err
+		}
+		if verify, _ := req.Options[statVerifyOptionName].(bool); verify {
+			if err := accounting.Verify(proof); err != nil {
+				return err
+			}
+		}
+		return cmds.EmitOnce(res, proof)
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, proof *accounting.Proof) error {
+			fmt.Fprintf(w, "peer: %s\n", proof.Peer)
+			fmt.Fprintf(w, "periods: %d\n", len(proof.Periods))
+
+			var total uint64
+			for _, sp := range proof.Periods {
+				fmt.Fprintf(w, "  %s - %s: %s in %d blocks, %d peers, %d cids\n",
+					sp.Period.Start.Format(time.RFC3339),
+					sp.Period.End.Format(time.RFC3339),
+					humanize.Bytes(sp.Period.BytesServed),
+					sp.Period.BlocksSent,
+					len(sp.Period.PeerBytes),
+					len(sp.Period.CidBytes))
+				total += sp.Period.BytesServed
+			}
+			fmt.Fprintf(w, "total served: %s\n", humanize.Bytes(total))
+			return nil
+		}),
+	},
+	Type: accounting.Proof{},
+}
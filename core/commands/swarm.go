@@ -1,17 +1,26 @@
 package commands
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"path"
 	"sort"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
 
 	commands "github.com/ipsn/go-ipfs/commands"
+	core "github.com/ipsn/go-ipfs/core"
+	dnsaddr "github.com/ipsn/go-ipfs/dnsaddr"
 	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
 	repo "github.com/ipsn/go-ipfs/repo"
 	fsrepo "github.com/ipsn/go-ipfs/repo/fsrepo"
 
+	files "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-files"
+	circuit "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-circuit"
 	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
 	swarm "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-swarm"
 	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
@@ -44,9 +53,15 @@ ipfs peers in the internet.
 	Subcommands: map[string]*cmds.Command{
 		"addrs":      swarmAddrsCmd,
 		"connect":    swarmConnectCmd,
+		"connmgr":    swarmConnMgrCmd,
 		"disconnect": swarmDisconnectCmd,
 		"filters":    swarmFiltersCmd,
+		"peering":    swarmPeeringCmd,
 		"peers":      swarmPeersCmd,
+		"peerstore":  swarmPeerstoreCmd,
+		"resume":     swarmResumeCmd,
+		"suspend":    swarmSuspendCmd,
+		"throttle":   swarmThrottleCmd,
 	},
 }
 
@@ -55,8 +70,25 @@ const (
 	swarmStreamsOptionName   = "streams"
 	swarmLatencyOptionName   = "latency"
 	swarmDirectionOptionName = "direction"
+	swarmWatchOptionName     = "watch"
+	swarmUploadOptionName    = "up"
+	swarmDownloadOptionName  = "down"
+	swarmLowWaterOptionName  = "low"
+	swarmHighWaterOptionName = "high"
+	swarmRelayOptionName     = "relay"
+)
+
+// events emitted by 'swarm peers --watch'
+const (
+	connEventConnected    = "connected"
+	connEventDisconnected = "disconnected"
+	connEventLatency      = "latency"
 )
 
+// latencyRefreshInterval is how often --watch re-emits latency
+// measurements for connected peers.
+const latencyRefreshInterval = 10 * time.Second
+
 var swarmPeersCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "List peers with open connections.",
@@ -69,6 +101,7 @@ var swarmPeersCmd = &cmds.Command{
 		cmdkit.BoolOption(swarmStreamsOptionName, "Also list information about open streams for each peer"),
 		cmdkit.BoolOption(swarmLatencyOptionName, "Also list information about latency to each peer"),
 		cmdkit.BoolOption(swarmDirectionOptionName, "Also list information about the direction of connection"),
+		cmdkit.BoolOption(swarmWatchOptionName, "Keep the stream open and emit connect/disconnect events."),
 	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		api, err := cmdenv.GetApi(env, req)
@@ -81,6 +114,17 @@ var swarmPeersCmd = &cmds.Command{
 		streams, _ := req.Options[swarmStreamsOptionName].(bool)
 		direction, _ := req.Options[swarmDirectionOptionName].(bool)
 
+		if watch, _ := req.Options[swarmWatchOptionName].(bool); watch {
+			nd, err := cmdenv.GetNode(env)
+			if err != nil {
+				return err
+			}
+			if !nd.OnlineMode() {
+				return ErrNotOnline
+			}
+			return swarmWatchPeers(req, res, nd, verbose || latency)
+		}
+
 		conns, err := api.Swarm().Peers(req.Context)
 		if err != nil {
 			return err
@@ -120,6 +164,16 @@ var swarmPeersCmd = &cmds.Command{
 					ci.Streams = append(ci.Streams, streamInfo{Protocol: string(s)})
 				}
 			}
+			if verbose {
+				// the reporter can be disabled via the config; a peer
+				// listing is still useful without the counters
+				if bw, err := c.Bandwidth(); err == nil {
+					ci.BytesIn = bw.TotalIn
+					ci.BytesOut = bw.TotalOut
+					ci.RateIn = bw.RateIn
+					ci.RateOut = bw.RateOut
+				}
+			}
 			sort.Sort(&ci)
 			out.Peers = append(out.Peers, ci)
 		}
@@ -129,8 +183,12 @@ var swarmPeersCmd = &cmds.Command{
 	},
 	Encoders: cmds.EncoderMap{
 		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, ci *connInfos) error {
+			verbose, _ := req.Options[swarmVerboseOptionName].(bool)
 			pipfs := ma.ProtocolWithCode(ma.P_IPFS).Name
 			for _, info := range ci.Peers {
+				if info.Event != "" {
+					fmt.Fprintf(w, "%s ", info.Event)
+				}
 				fmt.Fprintf(w, "%s/%s/%s", info.Addr, pipfs, info.Peer)
 				if info.Latency != "" {
 					fmt.Fprintf(w, " %s", info.Latency)
@@ -139,6 +197,13 @@ var swarmPeersCmd = &cmds.Command{
 				if info.Direction != inet.DirUnknown {
 					fmt.Fprintf(w, " %s", directionString(info.Direction))
 				}
+				if verbose {
+					fmt.Fprintf(w, " %s in (%s/s), %s out (%s/s)",
+						humanize.Bytes(uint64(info.BytesIn)),
+						humanize.Bytes(uint64(info.RateIn)),
+						humanize.Bytes(uint64(info.BytesOut)),
+						humanize.Bytes(uint64(info.RateOut)))
+				}
 				fmt.Fprintln(w)
 
 				for _, s := range info.Streams {
@@ -167,6 +232,16 @@ type connInfo struct {
 	Muxer     string
 	Direction inet.Direction
 	Streams   []streamInfo
+
+	// bandwidth counters for the peer, only set with --verbose
+	BytesIn  int64   `json:",omitempty"`
+	BytesOut int64   `json:",omitempty"`
+	RateIn   float64 `json:",omitempty"`
+	RateOut  float64 `json:",omitempty"`
+
+	// Event is only set in --watch mode, to one of the connEvent*
+	// constants.
+	Event string `json:",omitempty"`
 }
 
 func (ci *connInfo) Less(i, j int) bool {
@@ -185,6 +260,86 @@ type connInfos struct {
 	Peers []connInfo
 }
 
+// swarmWatchPeers keeps the response stream open, emitting one entry
+// per connection event instead of a one-shot listing. It starts with
+// the current connection set so watchers do not need a separate
+// snapshot call.
+func swarmWatchPeers(req *cmds.Request, res cmds.ResponseEmitter, nd *core.IpfsNode, withLatency bool) error {
+	net := nd.PeerHost.Network()
+
+	latStr := func(p peer.ID) string {
+		lat := nd.Peerstore.LatencyEWMA(p)
+		if lat == 0 {
+			return "n/a"
+		}
+		return lat.String()
+	}
+
+	mkInfo := func(event string, c inet.Conn) connInfo {
+		ci := connInfo{
+			Event:     event,
+			Addr:      c.RemoteMultiaddr().String(),
+			Peer:      c.RemotePeer().Pretty(),
+			Direction: c.Stat().Direction,
+		}
+		if withLatency {
+			ci.Latency = latStr(c.RemotePeer())
+		}
+		return ci
+	}
+
+	emit := func(ci connInfo) error {
+		return res.Emit(&connInfos{Peers: []connInfo{ci}})
+	}
+
+	events := make(chan connInfo, 64)
+	queue := func(event string, c inet.Conn) {
+		select {
+		case events <- mkInfo(event, c):
+		case <-req.Context.Done():
+		}
+	}
+
+	notifee := &inet.NotifyBundle{
+		ConnectedF:    func(_ inet.Network, c inet.Conn) { queue(connEventConnected, c) },
+		DisconnectedF: func(_ inet.Network, c inet.Conn) { queue(connEventDisconnected, c) },
+	}
+	net.Notify(notifee)
+	defer net.StopNotify(notifee)
+
+	// the initial snapshot; connections racing with the registration
+	// above may show up twice, which watchers have to tolerate anyway
+	for _, c := range net.Conns() {
+		if err := emit(mkInfo(connEventConnected, c)); err != nil {
+			return err
+		}
+	}
+
+	var refresh <-chan time.Time
+	if withLatency {
+		t := time.NewTicker(latencyRefreshInterval)
+		defer t.Stop()
+		refresh = t.C
+	}
+
+	for {
+		select {
+		case ci := <-events:
+			if err := emit(ci); err != nil {
+				return err
+			}
+		case <-refresh:
+			for _, c := range net.Conns() {
+				if err := emit(mkInfo(connEventLatency, c)); err != nil {
+					return err
+				}
+			}
+		case <-req.Context.Done():
+			return nil
+		}
+	}
+}
+
 func (ci connInfos) Less(i, j int) bool {
 	return ci.Peers[i].Addr < ci.Peers[j].Addr
 }
@@ -349,11 +504,22 @@ var swarmConnectCmd = &cmds.Command{
 The address format is an IPFS multiaddr:
 
 ipfs swarm connect /ip4/104.131.131.82/tcp/4001/ipfs/QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ
+
+Peers behind NAT can be reached through a relay with the p2p-circuit
+syntax, naming the relay explicitly:
+
+ipfs swarm connect /p2p/<relay-id>/p2p-circuit/p2p/<target-id>
+
+Alternatively, pass --relay to let the node pick a connected relay
+automatically and try it alongside the peer's direct addresses.
 `,
 	},
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("address", true, true, "Address of peer to connect to.").EnableStdin(),
 	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(swarmRelayOptionName, "Also try dialing through a connected relay."),
+	},
 	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
 		api, err := cmdenv.GetApi(env, req)
 		if err != nil {
@@ -362,11 +528,33 @@ ipfs swarm connect /ip4/104.131.131.82/tcp/4001/ipfs/QmaCpDMGvV2BGHeYERUEnRQAwe3
 
 		addrs := req.Arguments
 
-		pis, err := peersWithAddresses(addrs)
+		pis, err := peersWithAddresses(req.Context, addrs)
 		if err != nil {
 			return err
 		}
 
+		if useRelay, _ := req.Options[swarmRelayOptionName].(bool); useRelay {
+			n, err := cmdenv.GetNode(env)
+			if err != nil {
+				return err
+			}
+			if n.PeerHost == nil {
+				return ErrNotOnline
+			}
+
+			relay, err := pickKnownRelay(n, pis)
+			if err != nil {
+				return err
+			}
+			circ, err := ma.NewMultiaddr("/p2p/" + relay.Pretty() + "/p2p-circuit")
+			if err != nil {
+				return err
+			}
+			for i := range pis {
+				pis[i].Addrs = append(pis[i].Addrs, circ)
+			}
+		}
+
 		output := make([]string, len(pis))
 		for i, pi := range pis {
 			output[i] = "connect " + pi.ID.Pretty()
@@ -431,6 +619,33 @@ it will reconnect.
 	Type: stringList{},
 }
 
+// pickKnownRelay returns a connected peer that advertises the relay hop
+// protocol, for routing a relayed connect through. The targets themselves
+// are not considered.
+func pickKnownRelay(n *core.IpfsNode, targets []pstore.PeerInfo) (peer.ID, error) {
+	skip := make(map[peer.ID]struct{}, len(targets))
+	for _, pi := range targets {
+		skip[pi.ID] = struct{}{}
+	}
+
+	for _, p := range n.PeerHost.Network().Peers() {
+		if _, ok := skip[p]; ok {
+			continue
+		}
+		protos, err := n.Peerstore.GetProtocols(p)
+		if err != nil {
+			continue
+		}
+		for _, proto := range protos {
+			if proto == circuit.ProtoID {
+				return p, nil
+			}
+		}
+	}
+
+	return "", errors.New("no connected relays found; connect to a relay first, or name one with the /p2p-circuit syntax")
+}
+
 // parseAddresses is a function that takes in a slice of string peer addresses
 // (multiaddr + peerid) and returns slices of multiaddrs and peerids.
 func parseAddresses(addrs []string) (iaddrs []iaddr.IPFSAddr, err error) {
@@ -445,31 +660,9 @@ func parseAddresses(addrs []string) (iaddrs []iaddr.IPFSAddr, err error) {
 }
 
 // peersWithAddresses is a function that takes in a slice of string peer addresses
-// (multiaddr + peerid) and returns a slice of properly constructed peers
-func peersWithAddresses(addrs []string) ([]pstore.PeerInfo, error) {
-	iaddrs, err := parseAddresses(addrs)
-	if err != nil {
-		return nil, err
-	}
-
-	peers := make(map[peer.ID][]ma.Multiaddr, len(iaddrs))
-	for _, iaddr := range iaddrs {
-		id := iaddr.ID()
-		current, ok := peers[id]
-		if tpt := iaddr.Transport(); tpt != nil {
-			peers[id] = append(current, tpt)
-		} else if !ok {
-			peers[id] = nil
-		}
-	}
-	pis := make([]pstore.PeerInfo, 0, len(peers))
-	for id, maddrs := range peers {
-		pis = append(pis, pstore.PeerInfo{
-			ID:    id,
-			Addrs: maddrs,
-		})
-	}
-	return pis, nil
+// (multiaddr + peerid, or /dnsaddr) and returns a slice of properly constructed peers
+func peersWithAddresses(ctx context.Context, addrs []string) ([]pstore.PeerInfo, error) {
+	return dnsaddr.Peers(ctx, addrs)
 }
 
 var swarmFiltersCmd = &cmds.Command{
@@ -664,6 +857,726 @@ remove your filters from the ipfs config file.
 	Type: stringList{},
 }
 
+// throttleInfo reports the node-wide bandwidth caps in bytes per
+// second; zero means unlimited.
+type throttleInfo struct {
+	UploadRateLimit   int64
+	DownloadRateLimit int64
+}
+
+var swarmThrottleCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Get or set node-wide bandwidth limits.",
+		ShortDescription: `
+'ipfs swarm throttle' shows the current upload and download rate limits
+applied to all swarm traffic, in bytes per second. With --up and/or
+--down the limits are changed immediately and persisted under the
+"Swarm.UploadRateLimit" and "Swarm.DownloadRateLimit" config keys.
+A limit of 0 means unlimited.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.Int64Option(swarmUploadOptionName, "Upload rate limit in bytes per second (0 to remove)."),
+		cmdkit.Int64Option(swarmDownloadOptionName, "Download rate limit in bytes per second (0 to remove)."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		up, upSet := req.Options[swarmUploadOptionName].(int64)
+		down, downSet := req.Options[swarmDownloadOptionName].(int64)
+
+		curUp, curDown := swarm.BandwidthLimits()
+		if !upSet && !downSet {
+			return cmds.EmitOnce(res, &throttleInfo{
+				UploadRateLimit:   curUp,
+				DownloadRateLimit: curDown,
+			})
+		}
+
+		if !upSet {
+			up = curUp
+		}
+		if !downSet {
+			down = curDown
+		}
+		if up < 0 || down < 0 {
+			return errors.New("rate limits cannot be negative")
+		}
+
+		swarm.SetBandwidthLimits(up, down)
+
+		r, err := fsrepo.Open(env.(*commands.Context).ConfigRoot)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		cfg, err := r.Config()
+		if err != nil {
+			return err
+		}
+
+		cfg.Swarm.UploadRateLimit = up
+		cfg.Swarm.DownloadRateLimit = down
+		if err := r.SetConfig(cfg); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &throttleInfo{
+			UploadRateLimit:   up,
+			DownloadRateLimit: down,
+		})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *throttleInfo) error {
+			fmt.Fprintf(w, "Upload:   %s\n", throttleRateString(out.UploadRateLimit))
+			fmt.Fprintf(w, "Download: %s\n", throttleRateString(out.DownloadRateLimit))
+			return nil
+		}),
+	},
+	Type: throttleInfo{},
+}
+
+func throttleRateString(rate int64) string {
+	if rate == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d B/s", rate)
+}
+
+var swarmPeeringCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Manage the peering list.",
+		ShortDescription: `
+'ipfs swarm peering' manages the list of peers this node stays
+permanently connected to. Connections to peered nodes are re-established
+with backoff whenever they drop, and peered nodes are protected from the
+connection manager. The list is persisted under the "Peering.Peers"
+config key, so it survives restarts.
+`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"add": swarmPeeringAddCmd,
+		"ls":  swarmPeeringLsCmd,
+		"rm":  swarmPeeringRmCmd,
+	},
+}
+
+var swarmPeeringAddCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Add peers to the peering list.",
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("address", true, true, "Multiaddr of peer to keep connections to, ending in /ipfs/<peer-id>.").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		iaddrs, err := parseAddresses(req.Arguments)
+		if err != nil {
+			return err
+		}
+
+		added := make([]string, 0, len(iaddrs))
+		for _, ia := range iaddrs {
+			pi := pstore.PeerInfo{ID: ia.ID()}
+			if t := ia.Transport(); t != nil {
+				pi.Addrs = append(pi.Addrs, t)
+			}
+			if err := api.Swarm().AddPeering(req.Context, pi); err != nil {
+				return err
+			}
+			added = append(added, ia.ID().Pretty())
+		}
+
+		r, err := fsrepo.Open(env.(*commands.Context).ConfigRoot)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		cfg, err := r.Config()
+		if err != nil {
+			return err
+		}
+
+		have := make(map[string]struct{}, len(cfg.Peering.Peers))
+		for _, p := range cfg.Peering.Peers {
+			have[p] = struct{}{}
+		}
+		for _, ia := range iaddrs {
+			if _, ok := have[ia.String()]; !ok {
+				cfg.Peering.Peers = append(cfg.Peering.Peers, ia.String())
+				have[ia.String()] = struct{}{}
+			}
+		}
+		if err := r.SetConfig(cfg); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &stringList{added})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(stringListEncoder),
+	},
+	Type: stringList{},
+}
+
+var swarmPeeringLsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List peers on the peering list.",
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		peers, err := api.Swarm().ListPeering(req.Context)
+		if err != nil {
+			return err
+		}
+
+		out := make([]string, 0, len(peers))
+		for _, pi := range peers {
+			if len(pi.Addrs) == 0 {
+				out = append(out, pi.ID.Pretty())
+				continue
+			}
+			for _, a := range pi.Addrs {
+				out = append(out, fmt.Sprintf("%s/ipfs/%s", a, pi.ID.Pretty()))
+			}
+		}
+
+		return cmds.EmitOnce(res, &stringList{out})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(stringListEncoder),
+	},
+	Type: stringList{},
+}
+
+var swarmPeeringRmCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Remove peers from the peering list.",
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("peer", true, true, "ID of peer to stop peering with.").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		removed := make([]string, 0, len(req.Arguments))
+		ids := make(map[peer.ID]struct{}, len(req.Arguments))
+		for _, arg := range req.Arguments {
+			pid, err := peer.IDB58Decode(arg)
+			if err != nil {
+				return fmt.Errorf("invalid peer id '%s': %s", arg, err)
+			}
+			if err := api.Swarm().RemovePeering(req.Context, pid); err != nil {
+				return err
+			}
+			ids[pid] = struct{}{}
+			removed = append(removed, pid.Pretty())
+		}
+
+		r, err := fsrepo.Open(env.(*commands.Context).ConfigRoot)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		cfg, err := r.Config()
+		if err != nil {
+			return err
+		}
+
+		kept := cfg.Peering.Peers[:0]
+		for _, p := range cfg.Peering.Peers {
+			ia, err := iaddr.ParseString(p)
+			if err == nil {
+				if _, rm := ids[ia.ID()]; rm {
+					continue
+				}
+			}
+			kept = append(kept, p)
+		}
+		cfg.Peering.Peers = kept
+		if err := r.SetConfig(cfg); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &stringList{removed})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(stringListEncoder),
+	},
+	Type: stringList{},
+}
+
+var swarmConnMgrCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Inspect and adjust the connection manager.",
+		ShortDescription: `
+'ipfs swarm connmgr' exposes the connection manager's runtime controls:
+protecting peers from trimming, changing the watermarks and triggering
+an immediate trim. These commands act on the running daemon; the
+starting values come from the "Swarm.ConnMgr" config section.
+`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"limits":    swarmConnMgrLimitsCmd,
+		"protect":   swarmConnMgrProtectCmd,
+		"protected": swarmConnMgrProtectedCmd,
+		"trim":      swarmConnMgrTrimCmd,
+		"unprotect": swarmConnMgrUnprotectCmd,
+	},
+}
+
+var swarmConnMgrProtectCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Protect peers from connection trimming.",
+		ShortDescription: `
+'ipfs swarm connmgr protect' marks peers as exempt from trimming, so
+their connections stay open even when the connection count exceeds the
+high watermark. Protection lasts until 'ipfs swarm connmgr unprotect'
+or a daemon restart.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("peer", true, true, "ID of peer to protect.").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		protected := make([]string, 0, len(req.Arguments))
+		for _, arg := range req.Arguments {
+			pid, err := peer.IDB58Decode(arg)
+			if err != nil {
+				return fmt.Errorf("invalid peer id '%s': %s", arg, err)
+			}
+			if err := api.Swarm().Protect(req.Context, pid); err != nil {
+				return err
+			}
+			protected = append(protected, pid.Pretty())
+		}
+
+		return cmds.EmitOnce(res, &stringList{protected})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(stringListEncoder),
+	},
+	Type: stringList{},
+}
+
+var swarmConnMgrUnprotectCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Remove trimming protection from peers.",
+		ShortDescription: `
+'ipfs swarm connmgr unprotect' makes previously protected peers subject
+to connection trimming again.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("peer", true, true, "ID of peer to unprotect.").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		unprotected := make([]string, 0, len(req.Arguments))
+		for _, arg := range req.Arguments {
+			pid, err := peer.IDB58Decode(arg)
+			if err != nil {
+				return fmt.Errorf("invalid peer id '%s': %s", arg, err)
+			}
+			if err := api.Swarm().Unprotect(req.Context, pid); err != nil {
+				return err
+			}
+			unprotected = append(unprotected, pid.Pretty())
+		}
+
+		return cmds.EmitOnce(res, &stringList{unprotected})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(stringListEncoder),
+	},
+	Type: stringList{},
+}
+
+var swarmConnMgrProtectedCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List peers protected from connection trimming.",
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		info, err := api.Swarm().ConnMgrInfo(req.Context)
+		if err != nil {
+			return err
+		}
+
+		out := make([]string, 0, len(info.Protected))
+		for _, p := range info.Protected {
+			out = append(out, p.Pretty())
+		}
+
+		return cmds.EmitOnce(res, &stringList{out})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(stringListEncoder),
+	},
+	Type: stringList{},
+}
+
+// connMgrLimits reports the connection manager watermarks along with
+// the current connection count.
+type connMgrLimits struct {
+	LowWater  int
+	HighWater int
+	ConnCount int
+}
+
+var swarmConnMgrLimitsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Get or set the connection manager watermarks.",
+		ShortDescription: `
+'ipfs swarm connmgr limits' shows the low and high watermarks and the
+current connection count. With --low and/or --high the watermarks are
+changed immediately and persisted under the "Swarm.ConnMgr" config
+keys. Trimming prunes down to the low watermark once the connection
+count passes the high one; setting both to 0 disables trimming.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.IntOption(swarmLowWaterOptionName, "Low watermark; trimming prunes down to this many connections."),
+		cmdkit.IntOption(swarmHighWaterOptionName, "High watermark; exceeding this many connections triggers a trim."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		info, err := api.Swarm().ConnMgrInfo(req.Context)
+		if err != nil {
+			return err
+		}
+
+		low, lowSet := req.Options[swarmLowWaterOptionName].(int)
+		high, highSet := req.Options[swarmHighWaterOptionName].(int)
+		if !lowSet && !highSet {
+			return cmds.EmitOnce(res, &connMgrLimits{
+				LowWater:  info.LowWater,
+				HighWater: info.HighWater,
+				ConnCount: info.ConnCount,
+			})
+		}
+
+		if !lowSet {
+			low = info.LowWater
+		}
+		if !highSet {
+			high = info.HighWater
+		}
+		if low < 0 || high < 0 {
+			return errors.New("watermarks cannot be negative")
+		}
+		if low > high {
+			return fmt.Errorf("low watermark (%d) cannot exceed high watermark (%d)", low, high)
+		}
+
+		if err := api.Swarm().SetWatermarks(req.Context, low, high); err != nil {
+			return err
+		}
+
+		r, err := fsrepo.Open(env.(*commands.Context).ConfigRoot)
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		cfg, err := r.Config()
+		if err != nil {
+			return err
+		}
+
+		cfg.Swarm.ConnMgr.LowWater = low
+		cfg.Swarm.ConnMgr.HighWater = high
+		if err := r.SetConfig(cfg); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &connMgrLimits{
+			LowWater:  low,
+			HighWater: high,
+			ConnCount: info.ConnCount,
+		})
+	},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, out *connMgrLimits) error {
+			fmt.Fprintf(w, "Low:         %d\n", out.LowWater)
+			fmt.Fprintf(w, "High:        %d\n", out.HighWater)
+			fmt.Fprintf(w, "Connections: %d\n", out.ConnCount)
+			return nil
+		}),
+	},
+	Type: connMgrLimits{},
+}
+
+var swarmConnMgrTrimCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Trim connections down to the low watermark now.",
+		ShortDescription: `
+'ipfs swarm connmgr trim' asks the connection manager to prune
+connections immediately instead of waiting for the next automatic trim.
+Protected peers and connections within their grace period are kept.
+`,
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		return api.Swarm().TrimConnections(req.Context)
+	},
+}
+
+var swarmSuspendCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Suspend all networking without stopping the daemon.",
+		ShortDescription: `
+'ipfs swarm suspend' tears down the node's networking at runtime: open
+connections are closed, new ones are refused, and bootstrapping and
+reproviding are paused. The API and gateway keep serving local content.
+
+Use 'ipfs swarm resume' to go back online without restarting the daemon.
+`,
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		if err := api.Swarm().Suspend(req.Context); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &stringList{[]string{"networking suspended"}})
+	},
+	Type: stringList{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(stringListEncoder),
+	},
+}
+
+var swarmResumeCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Re-establish networking after a suspend.",
+		ShortDescription: `
+'ipfs swarm resume' brings a node suspended with 'ipfs swarm suspend' back
+online: bootstrapping and reproviding are restarted and new connections
+are accepted again.
+`,
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		api, err := cmdenv.GetApi(env, req)
+		if err != nil {
+			return err
+		}
+
+		if err := api.Swarm().Resume(req.Context); err != nil {
+			return err
+		}
+
+		return cmds.EmitOnce(res, &stringList{[]string{"networking resumed"}})
+	},
+	Type: stringList{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(stringListEncoder),
+	},
+}
+
+// PeerstoreEntry is one peer in a peerstore snapshot, as produced by
+// 'ipfs swarm peerstore export' and consumed by 'ipfs swarm peerstore import'.
+type PeerstoreEntry struct {
+	Peer      string
+	Addrs     []string
+	Protocols []string `json:",omitempty"`
+	Latency   string   `json:",omitempty"`
+}
+
+// PeerstoreImportResult reports how many snapshot entries were imported.
+type PeerstoreImportResult struct {
+	Peers int
+}
+
+var swarmPeerstoreCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Export and import peerstore snapshots.",
+		ShortDescription: `
+'ipfs swarm peerstore' exports the known peers (addresses, protocols and
+latency) to a snapshot that can be imported on another node or after a
+repo wipe, warm-starting connectivity instead of rediscovering the
+network from scratch.
+`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"export": swarmPeerstoreExportCmd,
+		"import": swarmPeerstoreImportCmd,
+	},
+}
+
+var swarmPeerstoreExportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Export the peerstore as a snapshot.",
+		ShortDescription: `
+'ipfs swarm peerstore export' writes one JSON entry per known peer with
+addresses to stdout. The output can be fed back to
+'ipfs swarm peerstore import' on another node.
+`,
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		peers := n.Peerstore.Peers()
+		sort.Sort(peers)
+
+		for _, p := range peers {
+			addrs := n.Peerstore.Addrs(p)
+			if len(addrs) == 0 {
+				continue
+			}
+
+			entry := &PeerstoreEntry{Peer: p.Pretty()}
+			for _, a := range addrs {
+				entry.Addrs = append(entry.Addrs, a.String())
+			}
+			sort.Sort(sort.StringSlice(entry.Addrs))
+
+			if protos, err := n.Peerstore.GetProtocols(p); err == nil && len(protos) > 0 {
+				sort.Sort(sort.StringSlice(protos))
+				entry.Protocols = protos
+			}
+			if lat := n.Peerstore.LatencyEWMA(p); lat > 0 {
+				entry.Latency = lat.String()
+			}
+
+			if err := res.Emit(entry); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	Type: PeerstoreEntry{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, e *PeerstoreEntry) error {
+			// one JSON object per line so the text output doubles as
+			// the snapshot format
+			return json.NewEncoder(w).Encode(e)
+		}),
+	},
+}
+
+var swarmPeerstoreImportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Import a peerstore snapshot.",
+		ShortDescription: `
+'ipfs swarm peerstore import' reads a snapshot produced by
+'ipfs swarm peerstore export' and adds the contained peers to the local
+peerstore. Imported addresses are kept for an hour unless the peer is
+connected to in the meantime.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.FileArg("snapshot", true, true, "The snapshot file to import.").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, err := cmdenv.GetNode(env)
+		if err != nil {
+			return err
+		}
+
+		imported := 0
+		it := req.Files.Entries()
+		for it.Next() {
+			file := files.FileFromEntry(it)
+			if file == nil {
+				return fmt.Errorf("expected a regular file")
+			}
+
+			dec := json.NewDecoder(file)
+			for {
+				var entry PeerstoreEntry
+				if err := dec.Decode(&entry); err == io.EOF {
+					break
+				} else if err != nil {
+					return fmt.Errorf("malformed snapshot: %s", err)
+				}
+
+				pid, err := peer.IDB58Decode(entry.Peer)
+				if err != nil {
+					return fmt.Errorf("malformed snapshot: invalid peer id %q: %s", entry.Peer, err)
+				}
+
+				addrs := make([]ma.Multiaddr, 0, len(entry.Addrs))
+				for _, s := range entry.Addrs {
+					a, err := ma.NewMultiaddr(s)
+					if err != nil {
+						return fmt.Errorf("malformed snapshot: invalid address %q: %s", s, err)
+					}
+					addrs = append(addrs, a)
+				}
+				if len(addrs) == 0 {
+					continue
+				}
+
+				n.Peerstore.AddAddrs(pid, addrs, pstore.AddressTTL)
+				if len(entry.Protocols) > 0 {
+					if err := n.Peerstore.AddProtocols(pid, entry.Protocols...); err != nil {
+						return err
+					}
+				}
+				if entry.Latency != "" {
+					lat, err := time.ParseDuration(entry.Latency)
+					if err != nil {
+						return fmt.Errorf("malformed snapshot: invalid latency %q: %s", entry.Latency, err)
+					}
+					n.Peerstore.RecordLatency(pid, lat)
+				}
+
+				imported++
+			}
+		}
+		if it.Err() != nil {
+			return it.Err()
+		}
+
+		return cmds.EmitOnce(res, &PeerstoreImportResult{Peers: imported})
+	},
+	Type: PeerstoreImportResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, r *PeerstoreImportResult) error {
+			_, err := fmt.Fprintf(w, "imported %d peers\n", r.Peers)
+			return err
+		}),
+	},
+}
+
 func filtersAdd(r repo.Repo, cfg *config.Config, filters []string) ([]string, error) {
 	addedMap := map[string]struct{}{}
 	addedList := make([]string, 0, len(filters))
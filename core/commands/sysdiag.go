@@ -1,17 +1,22 @@
 package commands
 
 import (
+	"io/ioutil"
 	"os"
 	"path"
 	"runtime"
+	"strings"
 
 	version "github.com/ipsn/go-ipfs"
+	"github.com/ipsn/go-ipfs/core"
 	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
+	fsrepo "github.com/ipsn/go-ipfs/repo/fsrepo"
 
 	manet "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr-net"
 	sysi "github.com/whyrusleeping/go-sysinfo"
 	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
 	cmdkit "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmdkit"
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
 )
 
 var sysDiagCmd = &cmds.Command{
@@ -52,6 +57,31 @@ Prints out information about your computer to aid in easier debugging.
 			return err
 		}
 
+		err = ulimitInfo(info)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := nd.Repo.Config()
+		if err != nil {
+			return err
+		}
+
+		err = repoInfo(cfg, info)
+		if err != nil {
+			return err
+		}
+
+		err = nodeInfo(nd, cfg, info)
+		if err != nil {
+			return err
+		}
+
+		err = configDiffInfo(cfg, info)
+		if err != nil {
+			return err
+		}
+
 		info["ipfs_version"] = version.CurrentVersionNumber
 		info["ipfs_commit"] = version.CurrentCommit
 		return cmds.EmitOnce(res, info)
@@ -135,3 +165,155 @@ func netInfo(online bool, out map[string]interface{}) error {
 	out["net"] = n
 	return nil
 }
+
+func ulimitInfo(out map[string]interface{}) error {
+	soft, hard, err := fdLimits()
+	if err != nil {
+		// not supported on this platform
+		return nil
+	}
+
+	u := make(map[string]interface{})
+	u["fds_soft"] = soft
+	u["fds_hard"] = hard
+	out["ulimit"] = u
+	return nil
+}
+
+func repoInfo(cfg *config.Config, out map[string]interface{}) error {
+	ri := make(map[string]interface{})
+	ri["version"] = fsrepo.RepoVersion
+	ri["path"] = ipfsPath()
+	ri["datastore_spec"] = cfg.Datastore.Spec
+
+	out["repo"] = ri
+	return nil
+}
+
+func nodeInfo(nd *core.IpfsNode, cfg *config.Config, out map[string]interface{}) error {
+	ni := make(map[string]interface{})
+	ni["experiments"] = cfg.Experimental
+
+	addrs := make(map[string]interface{})
+	addrs["swarm_config"] = cfg.Addresses.Swarm
+	addrs["announce"] = cfg.Addresses.Announce
+	addrs["no_announce"] = cfg.Addresses.NoAnnounce
+	if nd.OnlineMode() && nd.PeerHost != nil {
+		var listen []string
+		for _, a := range nd.PeerHost.Addrs() {
+			listen = append(listen, a.String())
+		}
+		addrs["listen"] = listen
+	}
+	ni["addresses"] = addrs
+
+	nat := make(map[string]interface{})
+	nat["disable_nat_port_map"] = cfg.Swarm.DisableNatPortMap
+	nat["enable_auto_nat_service"] = cfg.Swarm.EnableAutoNATService
+	nat["enable_auto_relay"] = cfg.Swarm.EnableAutoRelay
+	nat["autonat_running"] = nd.AutoNAT != nil
+	ni["nat"] = nat
+
+	out["node"] = ni
+	return nil
+}
+
+// configDiffInfo reports the config sections changed from a freshly
+// generated default config, with identity and anything secret-looking
+// stripped out.
+func configDiffInfo(cfg *config.Config, out map[string]interface{}) error {
+	def, err := config.Init(ioutil.Discard, 2048)
+	if err != nil {
+		return err
+	}
+
+	cur, err := config.ToMap(cfg)
+	if err != nil {
+		return err
+	}
+	defm, err := config.ToMap(def)
+	if err != nil {
+		return err
+	}
+
+	// the identity is never comparable and never useful in a report
+	delete(cur, "Identity")
+	delete(defm, "Identity")
+
+	diff := diffMaps(cur, defm)
+	scrubSecrets(diff)
+
+	out["config_diff"] = diff
+	return nil
+}
+
+// diffMaps returns the entries of cur that differ from def,
+// descending into nested objects.
+func diffMaps(cur, def map[string]interface{}) map[string]interface{} {
+	diff := make(map[string]interface{})
+	for k, cv := range cur {
+		dv, ok := def[k]
+		if !ok {
+			diff[k] = cv
+			continue
+		}
+
+		cm, cok := cv.(map[string]interface{})
+		dm, dok := dv.(map[string]interface{})
+		if cok && dok {
+			if sub := diffMaps(cm, dm); len(sub) > 0 {
+				diff[k] = sub
+			}
+			continue
+		}
+
+		if !deepEqualJSON(cv, dv) {
+			diff[k] = cv
+		}
+	}
+	return diff
+}
+
+func deepEqualJSON(a, b interface{}) bool {
+	switch av := a.(type) {
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !deepEqualJSON(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k := range av {
+			if !deepEqualJSON(av[k], bv[k]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// scrubSecrets replaces values under secret-looking keys in place.
+func scrubSecrets(m map[string]interface{}) {
+	for k, v := range m {
+		lk := strings.ToLower(k)
+		if strings.Contains(lk, "key") || strings.Contains(lk, "token") ||
+			strings.Contains(lk, "secret") || strings.Contains(lk, "password") {
+			m[k] = "[REDACTED]"
+			continue
+		}
+		if sub, ok := v.(map[string]interface{}); ok {
+			scrubSecrets(sub)
+		}
+	}
+}
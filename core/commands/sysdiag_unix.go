@@ -0,0 +1,15 @@
+// +build darwin linux netbsd openbsd freebsd
+
+package commands
+
+import "syscall"
+
+// fdLimits returns the soft and hard file descriptor limits of the
+// current process.
+func fdLimits() (soft uint64, hard uint64, err error) {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return 0, 0, err
+	}
+	return uint64(rlimit.Cur), uint64(rlimit.Max), nil
+}
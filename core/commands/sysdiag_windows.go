@@ -0,0 +1,11 @@
+// +build windows
+
+package commands
+
+import "errors"
+
+// fdLimits is not supported on windows; the ulimit section is simply
+// omitted from the report.
+func fdLimits() (uint64, uint64, error) {
+	return 0, 0, errors.New("fd limits not supported on this platform")
+}
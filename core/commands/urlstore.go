@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	cmdenv "github.com/ipsn/go-ipfs/core/commands/cmdenv"
+	e "github.com/ipsn/go-ipfs/core/commands/e"
 	filestore "github.com/ipsn/go-ipfs/filestore"
 	pin "github.com/ipsn/go-ipfs/pin"
 
@@ -21,10 +25,17 @@ import (
 
 var urlStoreCmd = &cmds.Command{
 	Subcommands: map[string]*cmds.Command{
-		"add": urlAdd,
+		"add":    urlAdd,
+		"verify": urlStoreVerify,
 	},
 }
 
+const (
+	removeBadOptionName  = "remove-bad"
+	maxRetriesOptionName = "max-retries"
+	timeoutOptionName    = "download-timeout"
+)
+
 var urlAdd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Add URL via urlstore.",
@@ -35,7 +46,9 @@ The URL provided must be stable and ideally on a web server under your
 control.
 
 The file is added using raw-leaves but otherwise using the default
-settings for 'ipfs add'.
+settings for 'ipfs add'. The --chunker and --hash options accept the
+same values as 'ipfs add', so cids can be made to match a normal add
+of the same content.
 
 This command is considered temporary until a better solution can be
 found.  It may disappear or the semantics can change at any
@@ -45,6 +58,11 @@ time.
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption(trickleOptionName, "t", "Use trickle-dag format for dag generation."),
 		cmdkit.BoolOption(pinOptionName, "Pin this object when adding.").WithDefault(true),
+		cmdkit.StringOption(chunkerOptionName, "s", "Chunking algorithm, size-[bytes] or rabin-[min]-[avg]-[max]").WithDefault("size-262144"),
+		cmdkit.StringOption(hashOptionName, "Hash function to use. Implies CIDv1 if not sha2-256. (experimental)").WithDefault("sha2-256"),
+		cmdkit.IntOption(maxRetriesOptionName, "Times to resume an interrupted download with a Range request before giving up.").WithDefault(3),
+		cmdkit.StringOption(timeoutOptionName, "Timeout for each request to start returning data, e.g. \"30s\" (default: none)."),
+		cmdenv.CidBaseOption(),
 	},
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("url", true, false, "URL to add to IPFS"),
@@ -58,6 +76,11 @@ time.
 			return err
 		}
 
+		cidEnc, err := cmdenv.CidEncoder(req)
+		if err != nil {
+			return err
+		}
+
 		if !filestore.IsURL(url) {
 			return fmt.Errorf("unsupported url syntax: %s", url)
 		}
@@ -73,27 +96,43 @@ time.
 
 		useTrickledag, _ := req.Options[trickleOptionName].(bool)
 		dopin, _ := req.Options[pinOptionName].(bool)
+		chunkerStr, _ := req.Options[chunkerOptionName].(string)
+		hashFunStr, _ := req.Options[hashOptionName].(string)
+		maxRetries, _ := req.Options[maxRetriesOptionName].(int)
+		timeoutStr, _ := req.Options[timeoutOptionName].(string)
 
-		hreq, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return err
+		hashFunCode, ok := mh.Names[strings.ToLower(hashFunStr)]
+		if !ok {
+			return fmt.Errorf("unrecognized hash function: %s", strings.ToLower(hashFunStr))
 		}
 
-		hres, err := http.DefaultClient.Do(hreq)
-		if err != nil {
-			return err
+		client := http.DefaultClient
+		if timeoutStr != "" {
+			d, err := time.ParseDuration(timeoutStr)
+			if err != nil {
+				return fmt.Errorf("parsing --timeout: %s", err)
+			}
+			client = &http.Client{
+				Transport: &http.Transport{ResponseHeaderTimeout: d},
+			}
 		}
-		if hres.StatusCode != http.StatusOK {
-			return fmt.Errorf("expected code 200, got: %d", hres.StatusCode)
+
+		ur := &urlReader{url: url, client: client, retriesLeft: maxRetries}
+		if err := ur.start(); err != nil {
+			return err
 		}
+		defer ur.Close()
 
 		if dopin {
 			// Take the pinlock
 			defer n.Blockstore.PinLock().Unlock()
 		}
 
-		chk := chunk.NewSizeSplitter(hres.Body, chunk.DefaultBlockSize)
-		prefix := cid.NewPrefixV1(cid.DagProtobuf, mh.SHA2_256)
+		chk, err := chunk.FromString(ur, chunkerStr)
+		if err != nil {
+			return err
+		}
+		prefix := cid.NewPrefixV1(cid.DagProtobuf, hashFunCode)
 		dbp := &ihelper.DagBuilderParams{
 			Dagserv:    n.DAG,
 			RawLeaves:  true,
@@ -122,8 +161,8 @@ time.
 		}
 
 		return cmds.EmitOnce(res, &BlockStat{
-			Key:  c.String(),
-			Size: int(hres.ContentLength),
+			Key:  cidEnc(c),
+			Size: int(ur.length),
 		})
 	},
 	Encoders: cmds.EncoderMap{
@@ -133,3 +172,222 @@ time.
 		}),
 	},
 }
+
+// urlReader streams the body of a URL and transparently resumes an
+// interrupted download by re-issuing the GET with a Range header
+// starting at the last byte received.
+type urlReader struct {
+	url         string
+	client      *http.Client
+	retriesLeft int
+
+	body   io.ReadCloser
+	offset int64
+	length int64 // ContentLength of the initial response, -1 if unknown
+}
+
+func (r *urlReader) start() error {
+	return r.fetch(false)
+}
+
+func (r *urlReader) fetch(resume bool) error {
+	hreq, err := http.NewRequest("GET", r.url, nil)
+	if err != nil {
+		return err
+	}
+	want := http.StatusOK
+	if resume {
+		hreq.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+		want = http.StatusPartialContent
+	}
+
+	hres, err := r.client.Do(hreq)
+	if err != nil {
+		return err
+	}
+	if hres.StatusCode != want {
+		hres.Body.Close()
+		if resume {
+			return fmt.Errorf("expected code %d resuming at offset %d, got: %d", want, r.offset, hres.StatusCode)
+		}
+		return fmt.Errorf("expected code 200, got: %d", hres.StatusCode)
+	}
+
+	if !resume {
+		r.length = hres.ContentLength
+	}
+	r.body = hres.Body
+	return nil
+}
+
+func (r *urlReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil {
+			return n, nil
+		}
+		if err == io.EOF && (r.length < 0 || r.offset >= r.length) {
+			// the whole body arrived
+			return n, io.EOF
+		}
+		if r.retriesLeft <= 0 {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return n, err
+		}
+
+		r.retriesLeft--
+		log.Warningf("urlstore add: %s, resuming %s at offset %d (%d retries left)",
+			err, r.url, r.offset, r.retriesLeft)
+		r.body.Close()
+		if err := r.fetch(true); err != nil {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *urlReader) Close() error {
+	return r.body.Close()
+}
+
+var urlStoreVerify = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Verify URL-backed objects in the filestore.",
+		LongDescription: `
+Re-fetch the byte ranges referenced by urlstore entries and check that
+the remote data still hashes to the stored cids.
+
+If one or more <obj> is specified only verify those specific objects,
+otherwise verify all URL-backed objects. References backed by local
+files are not checked; use 'ipfs filestore verify' for those.
+
+The output mirrors 'ipfs filestore verify':
+
+<status> <hash> <size> <url> <offset>
+
+Where <status> is one of:
+ok:       the block can be reconstructed
+changed:  the data behind the URL has changed
+error:    the URL could not be fetched
+missing:  <obj> could not be found in the filestore
+ERROR:    internal error, most likely due to a corrupt database
+
+With --remove-bad, references whose data has changed are dropped from
+the filestore. Fetch errors are treated as transient and never cause
+removal.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("obj", false, true, "Cid of objects to verify."),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(removeBadOptionName, "Remove references whose remote data has changed."),
+	},
+	Run: func(req *cmds.Request, res cmds.ResponseEmitter, env cmds.Environment) error {
+		n, fs, err := getFilestore(env)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := n.Repo.Config()
+		if err != nil {
+			return err
+		}
+		if !cfg.Experimental.UrlstoreEnabled {
+			return filestore.ErrUrlstoreNotEnabled
+		}
+
+		removeBad, _ := req.Options[removeBadOptionName].(bool)
+
+		emit := func(r *filestore.ListRes) error {
+			if removeBad && r.Status == filestore.StatusFileChanged {
+				if err := fs.FileManager().DeleteBlock(r.Key); err != nil {
+					r.Status = filestore.StatusOtherError
+					r.ErrorMsg = fmt.Sprintf("removing reference: %s", err)
+				}
+			}
+			return res.Emit(r)
+		}
+
+		if len(req.Arguments) > 0 {
+			for _, arg := range req.Arguments {
+				c, err := cid.Decode(arg)
+				if err != nil {
+					ret := &filestore.ListRes{
+						Status:   filestore.StatusOtherError,
+						ErrorMsg: fmt.Sprintf("%s: %v", arg, err),
+					}
+					if err := res.Emit(ret); err != nil {
+						return err
+					}
+					continue
+				}
+				r := filestore.Verify(fs, c)
+				if r.FilePath != "" && !filestore.IsURL(r.FilePath) {
+					r.Status = filestore.StatusOtherError
+					r.ErrorMsg = fmt.Sprintf("%s: not backed by a URL", arg)
+				}
+				if err := emit(r); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		// list without verifying so file-backed entries can be skipped
+		// before any data is read
+		next, err := filestore.ListAll(fs, false)
+		if err != nil {
+			return err
+		}
+		for {
+			r := next()
+			if r == nil {
+				return nil
+			}
+			if !filestore.IsURL(r.FilePath) {
+				continue
+			}
+			if r.Status == filestore.StatusOk {
+				r = filestore.Verify(fs, r.Key)
+			}
+			if err := emit(r); err != nil {
+				return err
+			}
+		}
+	},
+	PostRun: cmds.PostRunMap{
+		cmds.CLI: func(res cmds.Response, re cmds.ResponseEmitter) error {
+			removeBad, _ := res.Request().Options[removeBadOptionName].(bool)
+			for {
+				v, err := res.Next()
+				if err != nil {
+					if err == io.EOF {
+						return nil
+					}
+					return err
+				}
+
+				list, ok := v.(*filestore.ListRes)
+				if !ok {
+					return e.TypeErr(list, v)
+				}
+
+				if list.Status == filestore.StatusOtherError {
+					fmt.Fprintf(os.Stderr, "%s\n", list.ErrorMsg)
+				}
+				suffix := ""
+				if removeBad && list.Status == filestore.StatusFileChanged {
+					suffix = " (removed)"
+				}
+				fmt.Fprintf(os.Stdout, "%s %s%s\n", list.Status.Format(), list.FormatLong(), suffix)
+			}
+		},
+	},
+	Type: filestore.ListRes{},
+}
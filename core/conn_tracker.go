@@ -0,0 +1,47 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+)
+
+// ConnTracker records when swarm connections were opened, so diagnostic
+// commands can report connection age. The network itself does not keep
+// this information.
+type ConnTracker struct {
+	mu     sync.Mutex
+	opened map[inet.Conn]time.Time
+}
+
+// newConnTracker creates a tracker and subscribes it to the network's
+// connection events.
+func newConnTracker(network inet.Network) *ConnTracker {
+	t := &ConnTracker{
+		opened: make(map[inet.Conn]time.Time),
+	}
+
+	network.Notify(&inet.NotifyBundle{
+		ConnectedF: func(_ inet.Network, c inet.Conn) {
+			t.mu.Lock()
+			t.opened[c] = time.Now()
+			t.mu.Unlock()
+		},
+		DisconnectedF: func(_ inet.Network, c inet.Conn) {
+			t.mu.Lock()
+			delete(t.opened, c)
+			t.mu.Unlock()
+		},
+	})
+
+	return t
+}
+
+// OpenedAt returns the time the given connection was opened, if known.
+func (t *ConnTracker) OpenedAt(c inet.Conn) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	opened, ok := t.opened[c]
+	return opened, ok
+}
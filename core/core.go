@@ -18,20 +18,31 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	version "github.com/ipsn/go-ipfs"
 	rp "github.com/ipsn/go-ipfs/exchange/reprovide"
 	filestore "github.com/ipsn/go-ipfs/filestore"
+	dnsaddr "github.com/ipsn/go-ipfs/dnsaddr"
 	mount "github.com/ipsn/go-ipfs/fuse/mount"
 	namesys "github.com/ipsn/go-ipfs/namesys"
 	ipnsrp "github.com/ipsn/go-ipfs/namesys/republisher"
+	accounting "github.com/ipsn/go-ipfs/accounting"
+	ethofs "github.com/ipsn/go-ipfs/ethofs"
+	onion "github.com/ipsn/go-ipfs/onion"
 	p2p "github.com/ipsn/go-ipfs/p2p"
+	peering "github.com/ipsn/go-ipfs/peering"
 	pin "github.com/ipsn/go-ipfs/pin"
+	pinfollow "github.com/ipsn/go-ipfs/pinfollow"
+	events "github.com/ipsn/go-ipfs/plugin/events"
+	gater "github.com/ipsn/go-ipfs/plugin/gater"
 	repo "github.com/ipsn/go-ipfs/repo"
 
 	circuit "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-circuit"
 	ic "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-crypto"
+	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+	protocol "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-protocol"
 	u "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-util"
 	exchange "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-exchange-interface"
 	bserv "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-blockservice"
@@ -50,6 +61,11 @@ import (
 	goprocess "github.com/ipsn/go-ipfs/gxlibs/github.com/jbenet/goprocess"
 	mamask "github.com/ipsn/go-ipfs/gxlibs/github.com/whyrusleeping/multiaddr-filter"
 	quic "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-quic-transport"
+	swarm "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-swarm"
+	tptu "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-transport-upgrader"
+	tcp "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-tcp-transport"
+	websocket "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-ws-transport"
+	proxy "golang.org/x/net/proxy"
 	mfs "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-mfs"
 	bitswap "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-bitswap"
 	bsnet "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-bitswap/network"
@@ -61,6 +77,7 @@ import (
 	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
 	smux "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-stream-muxer"
 	pstore "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peerstore"
+	path "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-path"
 	resolver "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-path/resolver"
 	mplex "github.com/ipsn/go-ipfs/gxlibs/github.com/whyrusleeping/go-smux-multiplex"
 	pubsub "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-pubsub"
@@ -69,6 +86,7 @@ import (
 	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
 	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
 	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	ambientnat "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-autonat"
 	autonat "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-autonat-svc"
 	merkledag "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-merkledag"
 	nilrouting "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-routing/none"
@@ -81,6 +99,7 @@ import (
 const IpnsValidatorTag = "ipns"
 
 const kReprovideFrequency = time.Hour * 12
+const kBatchProvideFrequency = time.Second * 30
 const discoveryConnTimeout = time.Second * 30
 const DefaultIpnsCacheSize = 128
 
@@ -109,16 +128,20 @@ type IpfsNode struct {
 
 	// Local node
 	Pinning         pin.Pinner // the pinning manager
+	PinQueue        *pin.Queue // background pin requests
 	Mounts          Mounts     // current mount state, if any.
 	PrivateKey      ic.PrivKey // the local node's private Key
 	PNetFingerprint []byte     // fingerprint of private network
 
+	APICertFingerprint string // fingerprint of the API TLS certificate, when serving HTTPS
+
 	// Services
 	Peerstore       pstore.Peerstore     // storage for other Peer instances
 	Blockstore      bstore.GCBlockstore  // the block store (lower level)
 	Filestore       *filestore.Filestore // the filestore blockstore
 	BaseBlocks      bstore.Blockstore    // the raw blockstore, no filestore wrapping
 	GCLocker        bstore.GCLocker      // the locker used to protect the blockstore during gc
+	FetchCache      bstore.Blockstore    // read view of the fetch LRU over the block store, when enabled
 	Blocks          bserv.BlockService   // the block service, get/add blocks.
 	DAG             ipld.DAGService      // the merkle dag service, get/add objects.
 	Resolver        *resolver.Resolver   // the path resolution system
@@ -130,23 +153,37 @@ type IpfsNode struct {
 	// Online
 	PeerHost     p2phost.Host        // the network host (server+client)
 	Bootstrapper io.Closer           // the periodic bootstrapper
+	Peering      *peering.PeeringService // maintains connections to the configured peers
 	Routing      routing.IpfsRouting // the routing system. recommend ipfs-dht
 	Exchange     exchange.Interface  // the block exchange + strategy (bitswap)
 	Namesys      namesys.NameSystem  // the name system, resolves paths to hashes
 	Reprovider   *rp.Reprovider      // the value reprovider system
+	RootProvider *rp.BatchProvider   // batched root announcements (optional)
 	IpnsRepub    *ipnsrp.Republisher
 
-	AutoNAT  *autonat.AutoNATService
-	PubSub   *pubsub.PubSub
-	PSRouter *psrouter.PubsubValueStore
-	DHT      *dht.IpfsDHT
-	P2P      *p2p.P2P
+	EthoFS       *ethofs.Service     // hosting contract pin synchronization
+	NodeRegistry *ethofs.Registry    // on-chain node registry for bootstrap peers
+	PinFollow    *pinfollow.Service  // mirrors followed peers' published pinsets
+	Accounting   *accounting.Service // signed serve accounting for reward audits
+
+	AutoNAT    *autonat.AutoNATService
+	AmbientNAT ambientnat.AutoNAT // ambient autodetection of our own reachability
+	PubSub     *pubsub.PubSub
+	PSRouter   *psrouter.PubsubValueStore
+	DHT        *dht.IpfsDHT
+	P2P        *p2p.P2P
+
+	ConnTracker *ConnTracker // records connection open times for diagnostics
 
 	proc goprocess.Process
 	ctx  context.Context
 
 	mode         mode
 	localModeSet bool
+
+	suspendLk       sync.Mutex
+	suspended       bool
+	suspendNotifiee *suspendNotifiee
 }
 
 // Mounts defines what the node's mount state is. This should
@@ -172,6 +209,10 @@ func (n *IpfsNode) startOnlineServices(ctx context.Context, routingOption Routin
 		return err
 	}
 
+	if cfg.Identify.AgentVersion != "" {
+		identify.ClientVersion = cfg.Identify.AgentVersion
+	}
+
 	var libp2pOpts []libp2p.Option
 	for _, s := range cfg.Swarm.AddrFilters {
 		f, err := mamask.NewMask(s)
@@ -228,6 +269,9 @@ func (n *IpfsNode) startOnlineServices(ctx context.Context, routingOption Routin
 	if !cfg.Swarm.DisableRelay {
 		addrsFactory = composeAddrsFactory(addrsFactory, filterRelayAddrs)
 	}
+	if cfg.Onion.Enabled && cfg.Onion.OnlyOnion {
+		addrsFactory = composeAddrsFactory(addrsFactory, onlyOnionAddrs)
+	}
 	libp2pOpts = append(libp2pOpts, libp2p.AddrsFactory(addrsFactory))
 
 	connm, err := constructConnMgr(cfg.Swarm.ConnMgr)
@@ -236,6 +280,10 @@ func (n *IpfsNode) startOnlineServices(ctx context.Context, routingOption Routin
 	}
 	libp2pOpts = append(libp2pOpts, libp2p.ConnectionManager(connm))
 
+	if g := gater.Gater(); g != nil {
+		libp2pOpts = append(libp2pOpts, libp2p.ConnectionGater(g))
+	}
+
 	libp2pOpts = append(libp2pOpts, makeSmuxTransportOption(mplex))
 
 	if !cfg.Swarm.DisableNatPortMap {
@@ -259,10 +307,47 @@ func (n *IpfsNode) startOnlineServices(ctx context.Context, routingOption Routin
 	// explicitly enable the default transports
 	libp2pOpts = append(libp2pOpts, libp2p.DefaultTransports)
 
+	if cfg.Swarm.Socks5Proxy != "" {
+		if err := setupSocks5Proxy(cfg.Swarm.Socks5Proxy, cfg.Swarm.Socks5ProxyTransports); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Swarm.UploadRateLimit > 0 || cfg.Swarm.DownloadRateLimit > 0 {
+		swarm.SetBandwidthLimits(cfg.Swarm.UploadRateLimit, cfg.Swarm.DownloadRateLimit)
+	}
+
+	if cfg.Routing.SlowQueryThreshold != "" {
+		d, err := time.ParseDuration(cfg.Routing.SlowQueryThreshold)
+		if err != nil {
+			return fmt.Errorf("Routing.SlowQueryThreshold: %s", err)
+		}
+		dht.SlowQueryThreshold = d
+	}
+
 	if cfg.Experimental.QUIC {
 		libp2pOpts = append(libp2pOpts, libp2p.Transport(quic.NewTransport))
 	}
 
+	if cfg.Onion.Enabled {
+		onionOpts := onion.Options{
+			SocksAddress:    cfg.Onion.SocksAddress,
+			ControlAddress:  cfg.Onion.ControlAddress,
+			ControlPassword: cfg.Onion.ControlPassword,
+			ServiceKey:      cfg.Onion.ServiceKey,
+		}
+		// persist generated service keys so the onion address is
+		// stable across restarts
+		onionOpts.OnNewServiceKey = func(key string) {
+			if err := n.Repo.SetConfigKey("Onion.ServiceKey", key); err != nil {
+				log.Errorf("failed to persist onion service key: %s", err)
+			}
+		}
+		libp2pOpts = append(libp2pOpts, libp2p.Transport(func(u *tptu.Upgrader) *onion.Transport {
+			return onion.New(u, onionOpts)
+		}))
+	}
+
 	// enable routing
 	libp2pOpts = append(libp2pOpts, libp2p.Routing(func(h p2phost.Host) (routing.PeerRouting, error) {
 		r, err := routingOption(ctx, h, n.Repo.Datastore(), n.RecordValidator)
@@ -282,6 +367,7 @@ func (n *IpfsNode) startOnlineServices(ctx context.Context, routingOption Routin
 	}
 
 	n.PeerHost = peerhost
+	n.ConnTracker = newConnTracker(peerhost.Network())
 
 	if err := n.startOnlineServicesWithHost(ctx, routingOption, pubsub, ipnsps); err != nil {
 		return err
@@ -305,6 +391,43 @@ func (n *IpfsNode) startOnlineServices(ctx context.Context, routingOption Routin
 		}
 	}
 
+	// refresh bootstrap candidates from the on-chain node registry
+	if cfg.EthoFS.NodeRegistryContract != "" {
+		reg, err := ethofs.NewRegistry(cfg.EthoFS)
+		if err != nil {
+			return err
+		}
+		n.NodeRegistry = reg
+		go reg.Run(ctx)
+	}
+
+	// mirror followed peers' published pinsets
+	pf, err := pinfollow.New(func(ctx context.Context, p string) (ipld.Node, error) {
+		pp, err := path.ParsePath(p)
+		if err != nil {
+			return nil, err
+		}
+		return Resolve(ctx, n.Namesys, n.Resolver, pp)
+	}, n.Pinning, n.DAG, n.Repo.Datastore())
+	if err != nil {
+		return err
+	}
+	n.PinFollow = pf
+	go pf.Run(ctx)
+
+	// keep connections to the configured peering peers alive
+	n.Peering = peering.NewPeeringService(n.PeerHost)
+	pis, err := dnsaddr.Peers(ctx, cfg.Peering.Peers)
+	if err != nil {
+		return fmt.Errorf("Peering.Peers: %s", err)
+	}
+	for _, pi := range pis {
+		n.Peering.AddPeer(pi)
+	}
+	if err := n.Peering.Start(); err != nil {
+		return err
+	}
+
 	return n.Bootstrap(DefaultBootstrapConfig)
 }
 
@@ -361,6 +484,14 @@ func (n *IpfsNode) startLateOnlineServices(ctx context.Context) error {
 
 	go n.Reprovider.Run(reproviderInterval)
 
+	if cfg.Reprovider.BatchRootProvides {
+		// announce only the roots of newly added content, in batches,
+		// instead of every block the moment it is written
+		bitswap.ProvideEnabled = false
+		n.RootProvider = rp.NewBatchProvider(ctx, n.Routing)
+		go n.RootProvider.Run(kBatchProvideFrequency)
+	}
+
 	return nil
 }
 
@@ -464,6 +595,83 @@ func setupDiscoveryOption(d config.Discovery) DiscoveryOption {
 	return nil
 }
 
+// Reachability reports the NAT status determined by ambient AutoNAT:
+// "public", "private" or "unknown".
+func (n *IpfsNode) Reachability() string {
+	if n.AmbientNAT == nil {
+		return "unknown"
+	}
+
+	switch n.AmbientNAT.Status() {
+	case ambientnat.NATStatusPublic:
+		return "public"
+	case ambientnat.NATStatusPrivate:
+		return "private"
+	default:
+		return "unknown"
+	}
+}
+
+// setupSocks5Proxy points the outbound dials of the selected
+// transports at a SOCKS5 proxy. An empty transport list enables the
+// proxy for every supported transport.
+func setupSocks5Proxy(addr string, transports []string) error {
+	dialer, err := proxy.SOCKS5("tcp", addr, nil, proxy.Direct)
+	if err != nil {
+		return fmt.Errorf("configuring SOCKS5 proxy: %s", err)
+	}
+
+	useTCP, useWS := len(transports) == 0, len(transports) == 0
+	for _, t := range transports {
+		switch t {
+		case "tcp":
+			useTCP = true
+		case "ws":
+			useWS = true
+		default:
+			return fmt.Errorf("Swarm.Socks5ProxyTransports: unknown transport %q (supported: tcp, ws)", t)
+		}
+	}
+
+	if useTCP {
+		tcp.ProxyDialer = dialer
+	}
+	if useWS {
+		websocket.ProxyDialer = dialer
+	}
+	return nil
+}
+
+// watchReachability emits an event whenever the ambient AutoNAT verdict
+// changes, so event plugins can react to the node becoming (un)dialable.
+func (n *IpfsNode) watchReachability(ctx context.Context) {
+	last := "unknown"
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cur := n.Reachability()
+			if cur == last {
+				continue
+			}
+			last = cur
+			log.Infof("node reachability is now %s", cur)
+
+			if events.Active() {
+				data := map[string]interface{}{"status": cur}
+				if addr, err := n.AmbientNAT.PublicAddr(); err == nil {
+					data["addr"] = addr.String()
+				}
+				events.Emit("net/reachability", data)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // HandlePeerFound attempts to connect to peer from `PeerInfo`, if it fails
 // logs a warning log.
 func (n *IpfsNode) HandlePeerFound(p pstore.PeerInfo) {
@@ -484,6 +692,10 @@ func (n *IpfsNode) startOnlineServicesWithHost(ctx context.Context, routingOptio
 	}
 
 	if cfg.Swarm.EnableAutoNATService {
+		if cfg.Swarm.AutoNATServiceThrottle > 0 {
+			autonat.AutoNATServiceThrottle = cfg.Swarm.AutoNATServiceThrottle
+		}
+
 		var opts []libp2p.Option
 		if cfg.Experimental.QUIC {
 			opts = append(opts, libp2p.DefaultTransports, libp2p.Transport(quic.NewTransport))
@@ -496,6 +708,20 @@ func (n *IpfsNode) startOnlineServicesWithHost(ctx context.Context, routingOptio
 		n.AutoNAT = svc
 	}
 
+	// always autodetect our own reachability when online, so 'ipfs id
+	// --reachability' and event plugins can report whether we are dialable
+	n.AmbientNAT = ambientnat.NewAutoNAT(ctx, n.PeerHost, nil)
+	go n.watchReachability(ctx)
+
+	// advertise configured extra protocols (network tags). The handler
+	// just closes the stream; registering it is what puts the protocol
+	// in the identify list, and the change is pushed to connected peers.
+	for _, p := range cfg.Identify.ExtraProtocols {
+		n.PeerHost.SetStreamHandler(protocol.ID(p), func(s inet.Stream) {
+			go inet.FullClose(s)
+		})
+	}
+
 	if enablePubsub || enableIpnsps {
 		var service *pubsub.PubSub
 
@@ -580,7 +806,20 @@ func (n *IpfsNode) startOnlineServicesWithHost(ctx context.Context, routingOptio
 
 	// setup exchange service
 	bitswapNetwork := bsnet.NewFromIpfsHost(n.PeerHost, n.Routing)
-	n.Exchange = bitswap.New(ctx, bitswapNetwork, n.Blockstore)
+	exchangeBS := bstore.Blockstore(n.Blockstore)
+	if size := cfg.Datastore.FetchCacheSize; size > 0 {
+		// keep fetched blocks in a bounded in-memory cache instead of
+		// persisting them; locally added blocks still go through the
+		// regular blockstore
+		exchangeBS, err = bstore.NewFetchCache(exchangeBS, size)
+		if err != nil {
+			return err
+		}
+		// the local read path has to see the cache too, or repeat
+		// reads of a fetched block would hit the network every time
+		n.FetchCache = bstore.FetchCacheReadView(exchangeBS, n.Blockstore)
+	}
+	n.Exchange = bitswap.New(ctx, bitswapNetwork, exchangeBS)
 
 	size, err := n.getCacheSize()
 	if err != nil {
@@ -699,6 +938,10 @@ func (n *IpfsNode) teardown() error {
 		closers = append(closers, n.Blocks)
 	}
 
+	if n.Peering != nil {
+		n.Peering.Stop()
+	}
+
 	if n.Bootstrapper != nil {
 		closers = append(closers, n.Bootstrapper)
 	}
@@ -764,6 +1007,9 @@ func (n *IpfsNode) Bootstrap(cfg BootstrapConfig) error {
 				log.Warning("failed to parse bootstrap peers from config")
 				return nil
 			}
+			if n.NodeRegistry != nil {
+				ps = mergePeerInfos(ps, n.NodeRegistry.Peers())
+			}
 			return ps
 		}
 	}
@@ -849,11 +1095,9 @@ func (n *IpfsNode) loadBootstrapPeers() ([]pstore.PeerInfo, error) {
 		return nil, err
 	}
 
-	parsed, err := cfg.BootstrapPeers()
-	if err != nil {
-		return nil, err
-	}
-	return toPeerInfos(parsed), nil
+	// dnsaddr entries are expanded here; bootstrap rounds re-resolve
+	// them periodically, so rotated records are picked up over time
+	return dnsaddr.Peers(n.Context(), cfg.Bootstrap)
 }
 
 func (n *IpfsNode) loadFilesRoot() error {
@@ -930,6 +1174,16 @@ func listenAddresses(cfg *config.Config) ([]ma.Multiaddr, error) {
 		listen = append(listen, maddr)
 	}
 
+	if cfg.Onion.Enabled && cfg.Onion.ListenPort > 0 {
+		// the service id part is ignored by the onion transport; tor
+		// assigns the real address when the service is published
+		maddr, err := ma.NewMultiaddr(fmt.Sprintf("/onion3/%s:%d", strings.Repeat("a", 56), cfg.Onion.ListenPort))
+		if err != nil {
+			return nil, err
+		}
+		listen = append(listen, maddr)
+	}
+
 	return listen, nil
 }
 
@@ -955,6 +1209,22 @@ func constructPeerHost(ctx context.Context, id peer.ID, ps pstore.Peerstore, opt
 	return libp2p.New(ctx, options...)
 }
 
+// onlyOnionAddrs drops every non-onion address from the advertised
+// set, so identify never leaks the node's IP addresses.
+func onlyOnionAddrs(addrs []ma.Multiaddr) []ma.Multiaddr {
+	var oaddrs []ma.Multiaddr
+	for _, addr := range addrs {
+		if _, err := addr.ValueForProtocol(onion.P_ONION3); err == nil {
+			oaddrs = append(oaddrs, addr)
+			continue
+		}
+		if _, err := addr.ValueForProtocol(ma.P_ONION); err == nil {
+			oaddrs = append(oaddrs, addr)
+		}
+	}
+	return oaddrs
+}
+
 func filterRelayAddrs(addrs []ma.Multiaddr) []ma.Multiaddr {
 	var raddrs []ma.Multiaddr
 	for _, addr := range addrs {
@@ -20,7 +20,9 @@ import (
 	"github.com/ipsn/go-ipfs/core"
 	coreiface "github.com/ipsn/go-ipfs/core/coreapi/interface"
 	"github.com/ipsn/go-ipfs/core/coreapi/interface/options"
+	"github.com/ipsn/go-ipfs/exchange/reprovide"
 	"github.com/ipsn/go-ipfs/namesys"
+	"github.com/ipsn/go-ipfs/peering"
 	"github.com/ipsn/go-ipfs/pin"
 	"github.com/ipsn/go-ipfs/repo"
 
@@ -38,6 +40,7 @@ import (
 	dag "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-merkledag"
 	offlineroute "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-routing/offline"
 	record "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-record"
+	metrics "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-metrics"
 	p2phost "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-host"
 )
 
@@ -61,14 +64,19 @@ type CoreAPI struct {
 	peerHost        p2phost.Host
 	recordValidator record.Validator
 	exchange        exchange.Interface
+	reporter        metrics.Reporter
+	peering         *peering.PeeringService
 
-	namesys namesys.NameSystem
-	routing routing.IpfsRouting
+	namesys      namesys.NameSystem
+	routing      routing.IpfsRouting
+	rootProvider *reprovide.BatchProvider
 
 	pubSub *pubsub.PubSub
 
 	checkPublishAllowed func() error
 	checkOnline         func(allowOffline bool) error
+	suspend             func() error
+	resume              func() error
 
 	// ONLY for re-applying options in WithOptions, DO NOT USE ANYWHERE ELSE
 	nd         *core.IpfsNode
@@ -168,7 +176,10 @@ func (api *CoreAPI) WithOptions(opts ...options.ApiOption) (coreiface.CoreAPI, e
 		namesys:         n.Namesys,
 		recordValidator: n.RecordValidator,
 		exchange:        n.Exchange,
+		reporter:        n.Reporter,
+		peering:         n.Peering,
 		routing:         n.Routing,
+		rootProvider:    n.RootProvider,
 
 		pubSub: n.PubSub,
 
@@ -183,6 +194,9 @@ func (api *CoreAPI) WithOptions(opts ...options.ApiOption) (coreiface.CoreAPI, e
 		return nil
 	}
 
+	subApi.suspend = n.Suspend
+	subApi.resume = n.Resume
+
 	subApi.checkPublishAllowed = func() error {
 		if n.Mounts.Ipns != nil && n.Mounts.Ipns.IsActive() {
 			return errors.New("cannot manually publish while IPNS is mounted")
@@ -216,6 +230,9 @@ func (api *CoreAPI) WithOptions(opts ...options.ApiOption) (coreiface.CoreAPI, e
 		subApi.blocks = bserv.New(api.blockstore, subApi.exchange)
 		subApi.dag = dag.NewDAGService(subApi.blocks)
 
+		subApi.rootProvider = nil
+		subApi.suspend = nil
+		subApi.resume = nil
 	}
 
 	return subApi, nil
@@ -98,6 +98,24 @@ func (api *DhtAPI) Provide(ctx context.Context, path coreiface.Path, opts ...cao
 	return nil
 }
 
+func (api *DhtAPI) GetValue(ctx context.Context, key string) ([]byte, error) {
+	err := api.checkOnline(false)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.routing.GetValue(ctx, key)
+}
+
+func (api *DhtAPI) PutValue(ctx context.Context, key string, value []byte) error {
+	err := api.checkOnline(false)
+	if err != nil {
+		return err
+	}
+
+	return api.routing.PutValue(ctx, key, value)
+}
+
 func provideKeys(ctx context.Context, r routing.IpfsRouting, cids []cid.Cid) error {
 	for _, c := range cids {
 		err := r.Provide(ctx, c, true)
@@ -23,4 +23,11 @@ type DhtAPI interface {
 
 	// Provide announces to the network that you are providing given values
 	Provide(context.Context, Path, ...options.DhtProvideOption) error
+
+	// GetValue retrieves the best value found in the DHT for the given key.
+	// Keys are namespaced paths like '/ipns/...' or '/pk/...'
+	GetValue(ctx context.Context, key string) ([]byte, error)
+
+	// PutValue writes the given value under key to the DHT
+	PutValue(ctx context.Context, key string, value []byte) error
 }
@@ -1,11 +1,19 @@
 package options
 
+import "time"
+
 type PinAddSettings struct {
 	Recursive bool
+	Provide   bool
+	Name      string
+	Labels    map[string]string
 }
 
 type PinLsSettings struct {
-	Type string
+	Type   string
+	Name   string
+	Before time.Time
+	After  time.Time
 }
 
 type PinUpdateSettings struct {
@@ -19,6 +27,7 @@ type PinUpdateOption func(*PinUpdateSettings) error
 func PinAddOptions(opts ...PinAddOption) (*PinAddSettings, error) {
 	options := &PinAddSettings{
 		Recursive: true,
+		Provide:   true,
 	}
 
 	for _, opt := range opts {
@@ -63,8 +72,11 @@ func PinUpdateOptions(opts ...PinUpdateOption) (*PinUpdateSettings, error) {
 
 type pinType struct{}
 
+type pinFilter struct{}
+
 type pinOpts struct {
-	Type pinType
+	Type   pinType
+	Filter pinFilter
 }
 
 var Pin pinOpts
@@ -93,6 +105,33 @@ func (pinType) Indirect() PinLsOption {
 	return Pin.pinType("indirect")
 }
 
+// Name is an option for Pin.Ls which only returns pins whose metadata
+// carries exactly the given name
+func (pinFilter) Name(name string) PinLsOption {
+	return func(settings *PinLsSettings) error {
+		settings.Name = name
+		return nil
+	}
+}
+
+// Before is an option for Pin.Ls which only returns pins created before
+// the given time. Pins without metadata are filtered out.
+func (pinFilter) Before(t time.Time) PinLsOption {
+	return func(settings *PinLsSettings) error {
+		settings.Before = t
+		return nil
+	}
+}
+
+// After is an option for Pin.Ls which only returns pins created after
+// the given time. Pins without metadata are filtered out.
+func (pinFilter) After(t time.Time) PinLsOption {
+	return func(settings *PinLsSettings) error {
+		settings.After = t
+		return nil
+	}
+}
+
 // Recursive is an option for Pin.Add which specifies whether to pin an entire
 // object tree or just one object. Default: true
 func (pinOpts) Recursive(recursive bool) PinAddOption {
@@ -102,6 +141,34 @@ func (pinOpts) Recursive(recursive bool) PinAddOption {
 	}
 }
 
+// Provide is an option for Pin.Add which specifies whether to announce
+// the pinned root when batched root announcements are enabled.
+// Default: true
+func (pinOpts) Provide(provide bool) PinAddOption {
+	return func(settings *PinAddSettings) error {
+		settings.Provide = provide
+		return nil
+	}
+}
+
+// Name is an option for Pin.Add which attaches a human readable name to
+// the created pin
+func (pinOpts) Name(name string) PinAddOption {
+	return func(settings *PinAddSettings) error {
+		settings.Name = name
+		return nil
+	}
+}
+
+// Labels is an option for Pin.Add which attaches arbitrary key/value
+// metadata to the created pin
+func (pinOpts) Labels(labels map[string]string) PinAddOption {
+	return func(settings *PinAddSettings) error {
+		settings.Labels = labels
+		return nil
+	}
+}
+
 // Type is an option for Pin.Ls which allows to specify which pin types should
 // be returned
 //
@@ -32,17 +32,40 @@ type UnixfsAddSettings struct {
 	OnlyHash bool
 	FsCache  bool
 	NoCopy   bool
+	Provide  bool
 
-	Wrap      bool
-	Hidden    bool
-	StdinName string
+	Wrap       bool
+	Hidden     bool
+	StdinName  string
+	DetectMime bool
 
 	Events   chan<- interface{}
 	Silent   bool
 	Progress bool
 }
 
+type UnixfsGetSettings struct {
+	Offset int64
+	Length int64
+}
+
 type UnixfsAddOption func(*UnixfsAddSettings) error
+type UnixfsGetOption func(*UnixfsGetSettings) error
+
+func UnixfsGetOptions(opts ...UnixfsGetOption) (*UnixfsGetSettings, error) {
+	options := &UnixfsGetSettings{
+		Offset: 0,
+		Length: -1,
+	}
+
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
 
 func UnixfsAddOptions(opts ...UnixfsAddOption) (*UnixfsAddSettings, cid.Prefix, error) {
 	options := &UnixfsAddSettings{
@@ -61,10 +84,12 @@ func UnixfsAddOptions(opts ...UnixfsAddOption) (*UnixfsAddSettings, cid.Prefix,
 		OnlyHash: false,
 		FsCache:  false,
 		NoCopy:   false,
+		Provide:  true,
 
-		Wrap:      false,
-		Hidden:    false,
-		StdinName: "",
+		Wrap:       false,
+		Hidden:     false,
+		StdinName:  "",
+		DetectMime: false,
 
 		Events:   nil,
 		Silent:   false,
@@ -201,6 +226,16 @@ func (unixfsOpts) Layout(layout Layout) UnixfsAddOption {
 	}
 }
 
+// Provide tells the adder whether to announce the added blocks to the
+// network. Disabling it speeds up bulk ingest at the cost of the content
+// not being discoverable until it is reprovided. Default: true
+func (unixfsOpts) Provide(provide bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.Provide = provide
+		return nil
+	}
+}
+
 // Pin tells the adder to pin the file root recursively after adding
 func (unixfsOpts) Pin(pin bool) UnixfsAddOption {
 	return func(settings *UnixfsAddSettings) error {
@@ -209,6 +244,27 @@ func (unixfsOpts) Pin(pin bool) UnixfsAddOption {
 	}
 }
 
+// Offset tells Get to skip the given number of bytes at the start of
+// the file. Only the blocks covering the requested range are fetched.
+func (unixfsOpts) Offset(offset int64) UnixfsGetOption {
+	return func(settings *UnixfsGetSettings) error {
+		if offset < 0 {
+			return fmt.Errorf("offset must not be negative")
+		}
+		settings.Offset = offset
+		return nil
+	}
+}
+
+// Length limits how many bytes Get will read from the file. A negative
+// length (the default) reads to the end of the file.
+func (unixfsOpts) Length(length int64) UnixfsGetOption {
+	return func(settings *UnixfsGetSettings) error {
+		settings.Length = length
+		return nil
+	}
+}
+
 // HashOnly will make the adder calculate data hash without storing it in the
 // blockstore or announcing it to the network
 func (unixfsOpts) HashOnly(hashOnly bool) UnixfsAddOption {
@@ -227,6 +283,15 @@ func (unixfsOpts) Wrap(wrap bool) UnixfsAddOption {
 	}
 }
 
+// DetectMime tells the adder to sniff each file's content type and wrap
+// the file in a unixfs metadata node recording it.
+func (unixfsOpts) DetectMime(enable bool) UnixfsAddOption {
+	return func(settings *UnixfsAddSettings) error {
+		settings.DetectMime = enable
+		return nil
+	}
+}
+
 // Hidden enables adding of hidden files (files prefixed with '.')
 func (unixfsOpts) Hidden(hidden bool) UnixfsAddOption {
 	return func(settings *UnixfsAddSettings) error {
@@ -13,6 +13,9 @@ type Pin interface {
 
 	// Type of the pin
 	Type() string
+
+	// Name is the human readable name attached to the pin, if any
+	Name() string
 }
 
 // PinStatus holds information about pin health
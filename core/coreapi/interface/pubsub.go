@@ -32,6 +32,11 @@ type PubSubMessage interface {
 	Topics() []string
 }
 
+// PubSubValidator decides whether a message received on a topic should be
+// accepted and propagated further. Validators must be fast; a slow validator
+// stalls message processing for its topic.
+type PubSubValidator func(ctx context.Context, msg PubSubMessage) bool
+
 // PubSubAPI specifies the interface to PubSub
 type PubSubAPI interface {
 	// Ls lists subscribed topics by name
@@ -45,4 +50,12 @@ type PubSubAPI interface {
 
 	// Subscribe to messages on a given topic
 	Subscribe(context.Context, string, ...options.PubSubSubscribeOption) (PubSubSubscription, error)
+
+	// RegisterTopicValidator registers a validator for messages received on
+	// the given topic. Only one validator can be registered per topic
+	RegisterTopicValidator(ctx context.Context, topic string, val PubSubValidator) error
+
+	// UnregisterTopicValidator removes the validator registered for the
+	// given topic
+	UnregisterTopicValidator(ctx context.Context, topic string) error
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	metrics "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-metrics"
 	net "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
 	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
@@ -15,8 +16,31 @@ import (
 var (
 	ErrNotConnected = errors.New("not connected")
 	ErrConnNotFound = errors.New("conn not found")
+
+	// ErrConnMgrUnsupported is returned by the connection manager methods when
+	// the node's connection manager does not support runtime adjustment (for
+	// example when Swarm.ConnMgr.Type is "none").
+	ErrConnMgrUnsupported = errors.New("connection manager does not support this operation")
 )
 
+// ConnMgrInfo describes the connection manager's configuration and status
+type ConnMgrInfo struct {
+	// LowWater is the number of connections a trim prunes down to
+	LowWater int
+
+	// HighWater is the number of connections that triggers a trim
+	HighWater int
+
+	// GracePeriod is how long new connections are exempt from trimming
+	GracePeriod time.Duration
+
+	// ConnCount is the current number of open connections
+	ConnCount int
+
+	// Protected lists the peers exempt from trimming
+	Protected []peer.ID
+}
+
 // ConnectionInfo contains information about a peer
 type ConnectionInfo interface {
 	// ID returns PeerID
@@ -33,6 +57,10 @@ type ConnectionInfo interface {
 
 	// Streams returns list of streams established with the peer
 	Streams() ([]protocol.ID, error)
+
+	// Bandwidth returns the bytes transferred to and from the peer along with
+	// the current transfer rates, as recorded by the bandwidth reporter
+	Bandwidth() (metrics.Stats, error)
 }
 
 // SwarmAPI specifies the interface to libp2p swarm
@@ -54,4 +82,38 @@ type SwarmAPI interface {
 
 	// ListenAddrs returns the list of all listening addresses
 	ListenAddrs(context.Context) ([]ma.Multiaddr, error)
+
+	// ConnMgrInfo returns the connection manager's configuration and status,
+	// including the list of protected peers
+	ConnMgrInfo(context.Context) (*ConnMgrInfo, error)
+
+	// Protect exempts a peer's connections from trimming
+	Protect(context.Context, peer.ID) error
+
+	// Unprotect makes a peer's connections subject to trimming again
+	Unprotect(context.Context, peer.ID) error
+
+	// SetWatermarks replaces the connection manager's low and high watermarks
+	// at runtime
+	SetWatermarks(ctx context.Context, low, high int) error
+
+	// TrimConnections requests an immediate connection trim
+	TrimConnections(context.Context) error
+
+	// AddPeering adds a peer to the peering list. The node keeps a
+	// connection to it alive from now on, reconnecting when it drops
+	AddPeering(context.Context, pstore.PeerInfo) error
+
+	// RemovePeering removes a peer from the peering list
+	RemovePeering(context.Context, peer.ID) error
+
+	// ListPeering returns the current peering list
+	ListPeering(context.Context) ([]pstore.PeerInfo, error)
+
+	// Suspend tears down all networking at runtime while keeping the node
+	// running; the API and gateway keep serving local content
+	Suspend(context.Context) error
+
+	// Resume re-establishes networking after a Suspend
+	Resume(context.Context) error
 }
@@ -25,11 +25,12 @@ type UnixfsAPI interface {
 	// TODO: a long useful comment on how to use this for many different scenarios
 	Add(context.Context, files.Node, ...options.UnixfsAddOption) (ResolvedPath, error)
 
-	// Get returns a read-only handle to a file tree referenced by a path
+	// Get returns a read-only handle to a file tree referenced by a path.
+	// Offset/Length options restrict the handle to a byte range of a file
 	//
 	// Note that some implementations of this API may apply the specified context
 	// to operations performed on the returned file
-	Get(context.Context, Path) (files.Node, error)
+	Get(context.Context, Path, ...options.UnixfsGetOption) (files.Node, error)
 
 	// Ls returns the list of links in a directory
 	Ls(context.Context, Path) ([]*ipld.Link, error)
@@ -9,6 +9,7 @@ import (
 	corerepo "github.com/ipsn/go-ipfs/core/corerepo"
 	bserv "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-blockservice"
 	merkledag "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-merkledag"
+	pin "github.com/ipsn/go-ipfs/pin"
 
 	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
 	offline "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-exchange-offline"
@@ -34,6 +35,20 @@ func (api *PinAPI) Add(ctx context.Context, p coreiface.Path, opts ...caopts.Pin
 		return err
 	}
 
+	if settings.Name != "" || len(settings.Labels) > 0 {
+		err = api.pinning.SetMeta(rp.Cid(), &pin.Meta{
+			Name:   settings.Name,
+			Labels: settings.Labels,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if settings.Provide && api.rootProvider != nil {
+		api.rootProvider.Enqueue(rp.Cid())
+	}
+
 	return api.pinning.Flush()
 }
 
@@ -49,7 +64,7 @@ func (api *PinAPI) Ls(ctx context.Context, opts ...caopts.PinLsOption) ([]coreif
 		return nil, fmt.Errorf("invalid type '%s', must be one of {direct, indirect, recursive, all}", settings.Type)
 	}
 
-	return api.pinLsAll(settings.Type, ctx)
+	return api.pinLsAll(settings, ctx)
 }
 
 func (api *PinAPI) Rm(ctx context.Context, p coreiface.Path) error {
@@ -162,6 +177,7 @@ func (api *PinAPI) Verify(ctx context.Context) (<-chan coreiface.PinStatus, erro
 
 type pinInfo struct {
 	pinType string
+	name    string
 	path    coreiface.ResolvedPath
 }
 
@@ -173,7 +189,12 @@ func (p *pinInfo) Type() string {
 	return p.pinType
 }
 
-func (api *PinAPI) pinLsAll(typeStr string, ctx context.Context) ([]coreiface.Pin, error) {
+func (p *pinInfo) Name() string {
+	return p.name
+}
+
+func (api *PinAPI) pinLsAll(settings *caopts.PinLsSettings, ctx context.Context) ([]coreiface.Pin, error) {
+	typeStr := settings.Type
 
 	keys := make(map[cid.Cid]*pinInfo)
 
@@ -203,8 +224,37 @@ func (api *PinAPI) pinLsAll(typeStr string, ctx context.Context) ([]coreiface.Pi
 		AddToResultKeys(api.pinning.RecursiveKeys(), "recursive")
 	}
 
+	filtering := settings.Name != "" || !settings.Before.IsZero() || !settings.After.IsZero()
+
 	out := make([]coreiface.Pin, 0, len(keys))
-	for _, v := range keys {
+	for c, v := range keys {
+		if v.pinType == "direct" || v.pinType == "recursive" {
+			meta, err := api.pinning.GetMeta(c)
+			if err != nil {
+				return nil, err
+			}
+			if meta != nil {
+				v.name = meta.Name
+			}
+			// only pins carrying metadata can match the filters
+			if filtering {
+				if meta == nil {
+					continue
+				}
+				if settings.Name != "" && meta.Name != settings.Name {
+					continue
+				}
+				if !settings.Before.IsZero() && !meta.CreatedAt.Before(settings.Before) {
+					continue
+				}
+				if !settings.After.IsZero() && !meta.CreatedAt.After(settings.After) {
+					continue
+				}
+			}
+		} else if filtering {
+			// indirect pins have no metadata of their own
+			continue
+		}
 		out = append(out, v)
 	}
 
@@ -98,6 +98,26 @@ func (api *PubSubAPI) Subscribe(ctx context.Context, topic string, opts ...caopt
 	return &pubSubSubscription{cancel, sub}, nil
 }
 
+func (api *PubSubAPI) RegisterTopicValidator(ctx context.Context, topic string, val coreiface.PubSubValidator) error {
+	_, err := api.checkNode()
+	if err != nil {
+		return err
+	}
+
+	return api.pubSub.RegisterTopicValidator(topic, func(ctx context.Context, msg *pubsub.Message) bool {
+		return val(ctx, &pubSubMessage{msg})
+	})
+}
+
+func (api *PubSubAPI) UnregisterTopicValidator(ctx context.Context, topic string) error {
+	_, err := api.checkNode()
+	if err != nil {
+		return err
+	}
+
+	return api.pubSub.UnregisterTopicValidator(topic)
+}
+
 func connectToPubSubPeers(ctx context.Context, r routing.IpfsRouting, ph p2phost.Host, cid cid.Cid) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -2,11 +2,14 @@ package coreapi
 
 import (
 	"context"
+	"errors"
 	"sort"
 	"time"
 
 	coreiface "github.com/ipsn/go-ipfs/core/coreapi/interface"
 
+	connmgr "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-connmgr"
+	metrics "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-metrics"
 	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
 	net "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
 	swarm "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-swarm"
@@ -23,6 +26,7 @@ type connInfo struct {
 	peerstore pstore.Peerstore
 	conn      net.Conn
 	dir       net.Direction
+	reporter  metrics.Reporter
 
 	addr  ma.Multiaddr
 	peer  peer.ID
@@ -76,6 +80,124 @@ func (api *SwarmAPI) Disconnect(ctx context.Context, addr ma.Multiaddr) error {
 	return nil
 }
 
+// connMgr returns the host's connection manager, provided it is the standard
+// implementation with runtime controls.
+func (api *SwarmAPI) connMgr() (*connmgr.BasicConnMgr, error) {
+	if api.peerHost == nil {
+		return nil, coreiface.ErrOffline
+	}
+
+	cm, ok := api.peerHost.ConnManager().(*connmgr.BasicConnMgr)
+	if !ok {
+		return nil, coreiface.ErrConnMgrUnsupported
+	}
+
+	return cm, nil
+}
+
+func (api *SwarmAPI) ConnMgrInfo(context.Context) (*coreiface.ConnMgrInfo, error) {
+	cm, err := api.connMgr()
+	if err != nil {
+		return nil, err
+	}
+
+	info := cm.GetInfo()
+	protected := cm.ListProtected()
+	sort.Slice(protected, func(i, j int) bool {
+		return protected[i] < protected[j]
+	})
+
+	return &coreiface.ConnMgrInfo{
+		LowWater:    info.LowWater,
+		HighWater:   info.HighWater,
+		GracePeriod: info.GracePeriod,
+		ConnCount:   info.ConnCount,
+		Protected:   protected,
+	}, nil
+}
+
+func (api *SwarmAPI) Protect(ctx context.Context, p peer.ID) error {
+	cm, err := api.connMgr()
+	if err != nil {
+		return err
+	}
+
+	cm.Protect(p)
+	return nil
+}
+
+func (api *SwarmAPI) Unprotect(ctx context.Context, p peer.ID) error {
+	cm, err := api.connMgr()
+	if err != nil {
+		return err
+	}
+
+	cm.Unprotect(p)
+	return nil
+}
+
+func (api *SwarmAPI) SetWatermarks(ctx context.Context, low, high int) error {
+	cm, err := api.connMgr()
+	if err != nil {
+		return err
+	}
+
+	cm.SetWatermarks(low, high)
+	return nil
+}
+
+func (api *SwarmAPI) TrimConnections(ctx context.Context) error {
+	cm, err := api.connMgr()
+	if err != nil {
+		return err
+	}
+
+	cm.TrimOpenConns(ctx)
+	return nil
+}
+
+func (api *SwarmAPI) AddPeering(ctx context.Context, pi pstore.PeerInfo) error {
+	if api.peering == nil {
+		return coreiface.ErrOffline
+	}
+
+	api.peering.AddPeer(pi)
+	return nil
+}
+
+func (api *SwarmAPI) RemovePeering(ctx context.Context, p peer.ID) error {
+	if api.peering == nil {
+		return coreiface.ErrOffline
+	}
+
+	api.peering.RemovePeer(p)
+	return nil
+}
+
+func (api *SwarmAPI) ListPeering(context.Context) ([]pstore.PeerInfo, error) {
+	if api.peering == nil {
+		return nil, coreiface.ErrOffline
+	}
+
+	return api.peering.ListPeers(), nil
+}
+
+func (api *SwarmAPI) Suspend(context.Context) error {
+	if api.suspend == nil {
+		return coreiface.ErrOffline
+	}
+
+	return api.suspend()
+}
+
+func (api *SwarmAPI) Resume(context.Context) error {
+	if api.resume == nil {
+		return coreiface.ErrOffline
+	}
+
+	return api.resume()
+}
+
 func (api *SwarmAPI) KnownAddrs(context.Context) (map[peer.ID][]ma.Multiaddr, error) {
 	if api.peerHost == nil {
 		return nil, coreiface.ErrOffline
@@ -127,6 +249,7 @@ func (api *SwarmAPI) Peers(context.Context) ([]coreiface.ConnectionInfo, error)
 			peerstore: api.peerstore,
 			conn:      c,
 			dir:       c.Stat().Direction,
+			reporter:  api.reporter,
 
 			addr: addr,
 			peer: pid,
@@ -162,6 +285,14 @@ func (ci *connInfo) Latency() (time.Duration, error) {
 	return ci.peerstore.LatencyEWMA(peer.ID(ci.ID())), nil
 }
 
+func (ci *connInfo) Bandwidth() (metrics.Stats, error) {
+	if ci.reporter == nil {
+		return metrics.Stats{}, errors.New("bandwidth reporting is disabled")
+	}
+
+	return ci.reporter.GetBandwidthForPeer(ci.peer), nil
+}
+
 func (ci *connInfo) Streams() ([]protocol.ID, error) {
 	streams := ci.conn.GetStreams()
 
@@ -3,8 +3,8 @@ package coreapi
 import (
 	"context"
 	"fmt"
+	"io"
 
-	"github.com/ipsn/go-ipfs/core"
 	"github.com/ipsn/go-ipfs/filestore"
 
 	coreiface "github.com/ipsn/go-ipfs/core/coreapi/interface"
@@ -12,7 +12,10 @@ import (
 	"github.com/ipsn/go-ipfs/core/coreunix"
 
 	blockservice "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-blockservice"
+	ds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore"
+	dssync "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore/sync"
 	bstore "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-blockstore"
+	offline "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-exchange-offline"
 	mfs "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-mfs"
 	files "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-files"
 	cidutil "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cidutil"
@@ -58,17 +61,21 @@ func (api *UnixfsAPI) Add(ctx context.Context, files files.Node, opts ...options
 	pinning := api.pinning
 
 	if settings.OnlyHash {
-		nilnode, err := core.NewNode(ctx, &core.BuildCfg{
-			//TODO: need this to be true or all files
-			// hashed will be stored in memory!
-			NilRepo: true,
-		})
-		if err != nil {
-			return nil, err
-		}
-		addblockstore = nilnode.Blockstore
-		exch = nilnode.Exchange
-		pinning = nilnode.Pinning
+		// hash-only adds never need the blocks again: discard them at
+		// the datastore layer instead of spinning up a nil-repo node
+		// that buffers everything in memory. The pinner is left alone;
+		// the adder never pins when OnlyHash is set.
+		addblockstore = bstore.NewGCBlockstore(
+			bstore.NewBlockstore(dssync.MutexWrap(ds.NewNullDatastore())),
+			bstore.NewGCLocker(),
+		)
+		exch = offline.Exchange(addblockstore)
+	}
+
+	if !settings.Provide {
+		// write the blocks without the exchange so nothing gets
+		// announced to the network while adding
+		exch = offline.Exchange(addblockstore)
 	}
 
 	bserv := blockservice.New(addblockstore, exch) // hash security 001
@@ -91,6 +98,7 @@ func (api *UnixfsAPI) Add(ctx context.Context, files files.Node, opts ...options
 	fileAdder.RawLeaves = settings.RawLeaves
 	fileAdder.NoCopy = settings.NoCopy
 	fileAdder.Name = settings.StdinName
+	fileAdder.DetectMime = settings.DetectMime
 	fileAdder.CidBuilder = prefix
 
 	switch settings.Layout {
@@ -126,10 +134,20 @@ func (api *UnixfsAPI) Add(ctx context.Context, files files.Node, opts ...options
 	if err != nil {
 		return nil, err
 	}
+
+	if settings.Provide && !settings.OnlyHash && api.rootProvider != nil {
+		api.rootProvider.Enqueue(nd.Cid())
+	}
+
 	return coreiface.IpfsPath(nd.Cid()), nil
 }
 
-func (api *UnixfsAPI) Get(ctx context.Context, p coreiface.Path) (files.Node, error) {
+func (api *UnixfsAPI) Get(ctx context.Context, p coreiface.Path, opts ...options.UnixfsGetOption) (files.Node, error) {
+	settings, err := options.UnixfsGetOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
 	ses := api.core().getSession(ctx)
 
 	nd, err := ses.ResolveNode(ctx, p)
@@ -137,7 +155,71 @@ func (api *UnixfsAPI) Get(ctx context.Context, p coreiface.Path) (files.Node, er
 		return nil, err
 	}
 
-	return newUnixfsFile(ctx, ses.dag, nd)
+	f, err := newUnixfsFile(ctx, ses.dag, nd)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Offset == 0 && settings.Length < 0 {
+		return f, nil
+	}
+
+	file, ok := f.(files.File)
+	if !ok {
+		return nil, coreiface.ErrNotFile
+	}
+
+	return sliceFile(file, settings.Offset, settings.Length)
+}
+
+// sliceFile restricts a file handle to the given byte range. Seeking
+// happens on the underlying dagreader, so only blocks covering the
+// range are ever fetched.
+func sliceFile(file files.File, offset int64, length int64) (files.File, error) {
+	size, err := file.Size()
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > size {
+		offset = size
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	remaining := size - offset
+	if length < 0 || length > remaining {
+		length = remaining
+	}
+
+	return &fileSlice{
+		File: file,
+		r:    io.LimitReader(file, length),
+		size: length,
+	}, nil
+}
+
+// fileSlice is a byte range of an underlying file, presented as a file
+// of its own. It reads through the range once; seeking it is not
+// supported.
+type fileSlice struct {
+	files.File
+
+	r    io.Reader
+	size int64
+}
+
+func (fs *fileSlice) Size() (int64, error) {
+	return fs.size, nil
+}
+
+func (fs *fileSlice) Read(p []byte) (int, error) {
+	return fs.r.Read(p)
+}
+
+func (fs *fileSlice) Seek(offset int64, whence int) (int64, error) {
+	return 0, fmt.Errorf("cannot seek in a file slice")
 }
 
 // Ls returns the contents of an IPFS or IPNS object(s) at path p, with the format:
@@ -695,6 +695,45 @@ func TestGetEmptyFile(t *testing.T) {
 	}
 }
 
+func TestGetRange(t *testing.T) {
+	ctx := context.Background()
+	_, api, err := makeAPI(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := api.Unixfs().Add(ctx, strFile(helloStr)())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := api.Unixfs().Get(ctx, p, options.Unixfs.Offset(6), options.Unixfs.Length(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size, err := r.Size(); err != nil || size != 5 {
+		t.Errorf("expected slice size 5, got %d (err: %v)", size, err)
+	}
+
+	buf, err := ioutil.ReadAll(r.(files.File))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != helloStr[6:11] {
+		t.Errorf("expected %q, got %q", helloStr[6:11], string(buf))
+	}
+
+	// a range past the end of the file just reads nothing
+	r, err = api.Unixfs().Get(ctx, p, options.Unixfs.Offset(int64(len(helloStr)+10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, err := r.Size(); err != nil || size != 0 {
+		t.Errorf("expected empty slice, got size %d (err: %v)", size, err)
+	}
+}
+
 func TestGetDir(t *testing.T) {
 	ctx := context.Background()
 	node, api, err := makeAPI(ctx)
@@ -3,6 +3,8 @@ package coredag
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
 )
@@ -19,9 +21,11 @@ type InputEncParsers map[string]FormatParsers
 // DefaultInputEncParsers is InputEncParser that is used everywhere
 var DefaultInputEncParsers = InputEncParsers{
 	"json":     defaultJSONParsers,
+	"dag-json": defaultJSONParsers,
 	"raw":      defaultRawParsers,
 	"cbor":     defaultCborParsers,
 	"protobuf": defaultProtobufParsers,
+	"dag-pb":   defaultProtobufParsers,
 }
 
 var defaultJSONParsers = FormatParsers{
@@ -74,12 +78,22 @@ func (iep InputEncParsers) AddParser(ienc, format string, f DagParser) {
 func (iep InputEncParsers) ParseInputs(ienc, format string, r io.Reader, mhType uint64, mhLen int) ([]ipld.Node, error) {
 	parsers, ok := iep[ienc]
 	if !ok {
-		return nil, fmt.Errorf("no input parser for %q", ienc)
+		encs := make([]string, 0, len(iep))
+		for e := range iep {
+			encs = append(encs, e)
+		}
+		sort.Strings(encs)
+		return nil, fmt.Errorf("unknown input encoding %q (supported: %s)", ienc, strings.Join(encs, ", "))
 	}
 
 	parser, ok := parsers[format]
 	if !ok {
-		return nil, fmt.Errorf("no parser for format %q using input type %q", format, ienc)
+		formats := make([]string, 0, len(parsers))
+		for f := range parsers {
+			formats = append(formats, f)
+		}
+		sort.Strings(formats)
+		return nil, fmt.Errorf("cannot parse %q input into format %q (supported formats for %q: %s)", ienc, format, ienc, strings.Join(formats, ", "))
 	}
 
 	return parser(r, mhType, mhLen)
@@ -18,6 +18,8 @@ import (
 	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
 	cmdsHttp "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds/http"
 	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+	opentracing "github.com/opentracing/opentracing-go"
+	ext "github.com/opentracing/opentracing-go/ext"
 )
 
 var (
@@ -113,7 +115,7 @@ func patchCORSVars(c *cmdsHttp.ServerConfig, addr net.Addr) {
 	c.SetAllowedOrigins(newOrigins...)
 }
 
-func commandsOption(cctx oldcmds.Context, command *cmds.Command) ServeOption {
+func commandsOption(cctx oldcmds.Context, command *cmds.Command, lcfg *config.APIListener) ServeOption {
 	return func(n *core.IpfsNode, l net.Listener, mux *http.ServeMux) (*http.ServeMux, error) {
 
 		cfg := cmdsHttp.NewServerConfig()
@@ -129,22 +131,125 @@ func commandsOption(cctx oldcmds.Context, command *cmds.Command) ServeOption {
 		addCORSDefaults(cfg)
 		patchCORSVars(cfg, l.Addr())
 
-		cmdHandler := cmdsHttp.NewHandler(&cctx, command, cfg)
-		mux.Handle(APIPath+"/", cmdHandler)
+		if err := ValidateCORSPolicies(rcfg.API.CORSPolicies); err != nil {
+			return nil, err
+		}
+
+		var cmdHandler http.Handler = cmdsHttp.NewHandler(&cctx, command, cfg)
+		if len(rcfg.API.CORSPolicies) > 0 {
+			cmdHandler = corsPolicyWrapper(rcfg.API.CORSPolicies, cmdHandler)
+		}
+		if lcfg != nil && lcfg.Scope != "" && lcfg.Scope != config.APIScopeAdmin {
+			cmdHandler = scopeWrapper(lcfg.Scope, cmdHandler)
+		}
+		if rcfg.APIAuth.Enabled || (lcfg != nil && lcfg.RequireAuth) {
+			cmdHandler = authWrapper(rcfg.APIAuth, cmdHandler)
+		}
+		if rcfg.AuditLog.Enabled {
+			logger, err := newAuditLogger(rcfg.AuditLog)
+			if err != nil {
+				return nil, err
+			}
+			cmdHandler = auditWrapper(logger, cmdHandler)
+		}
+
+		mux.Handle(APIPath+"/", tracingWrapper(cmdHandler))
 		return mux, nil
 	}
 }
 
+// tracingWrapper starts a span for each API request, continuing any
+// trace context propagated by the client via opentracing HTTP headers
+func tracingWrapper(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracer := opentracing.GlobalTracer()
+
+		var opts []opentracing.StartSpanOption
+		if wireCtx, err := tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header)); err == nil {
+			opts = append(opts, ext.RPCServerOption(wireCtx))
+		}
+
+		span := tracer.StartSpan("api."+strings.TrimPrefix(r.URL.Path, APIPath+"/"), opts...)
+		defer span.Finish()
+		ext.HTTPMethod.Set(span, r.Method)
+		ext.HTTPUrl.Set(span, r.URL.String())
+
+		h.ServeHTTP(w, r.WithContext(opentracing.ContextWithSpan(r.Context(), span)))
+	})
+}
+
 // CommandsOption constructs a ServerOption for hooking the commands into the
 // HTTP server.
 func CommandsOption(cctx oldcmds.Context) ServeOption {
-	return commandsOption(cctx, corecommands.Root)
+	return commandsOption(cctx, corecommands.Root, nil)
 }
 
 // CommandsROOption constructs a ServerOption for hooking the read-only commands
 // into the HTTP server.
 func CommandsROOption(cctx oldcmds.Context) ServeOption {
-	return commandsOption(cctx, corecommands.RootRO)
+	return commandsOption(cctx, corecommands.RootRO, nil)
+}
+
+// CommandsListenerOption is like CommandsOption, but limits the served
+// commands to the listener's configured scope and, when RequireAuth is
+// set, demands an APIAuth token even if APIAuth.Enabled is off.
+func CommandsListenerOption(cctx oldcmds.Context, lcfg config.APIListener) ServeOption {
+	return commandsOption(cctx, corecommands.Root, &lcfg)
+}
+
+// CommandsGatewayOption hooks the read-only commands into the gateway
+// listener. A non-empty allowlist restricts the served commands to the
+// listed paths; an empty one keeps the whole read-only set. Entries
+// are always resolved against the read-only root, so the gateway port
+// can never expose mutating commands.
+func CommandsGatewayOption(cctx oldcmds.Context, allowlist []string) ServeOption {
+	return func(n *core.IpfsNode, l net.Listener, mux *http.ServeMux) (*http.ServeMux, error) {
+		root := corecommands.RootRO
+		if len(allowlist) > 0 {
+			var err error
+			root, err = restrictedRoot(corecommands.RootRO, allowlist)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return commandsOption(cctx, root, nil)(n, l, mux)
+	}
+}
+
+// restrictedRoot builds a command tree containing only the allowed
+// paths, grafting each one from root and creating bare intermediate
+// commands as needed.
+func restrictedRoot(root *cmds.Command, allowed []string) (*cmds.Command, error) {
+	out := &cmds.Command{}
+	*out = *root
+	out.Subcommands = map[string]*cmds.Command{}
+
+	for _, pth := range allowed {
+		split := strings.Split(strings.Trim(pth, "/"), "/")
+		if _, err := root.Get(split); err != nil {
+			return nil, fmt.Errorf("Gateway.APICommands: %q is not a read-only command", pth)
+		}
+
+		src := root
+		cur := out
+		for i, name := range split {
+			src = src.Subcommands[name]
+
+			sub, ok := cur.Subcommands[name]
+			if !ok {
+				if i == len(split)-1 {
+					sub = src
+				} else {
+					sub = &cmds.Command{Subcommands: map[string]*cmds.Command{}}
+				}
+				cur.Subcommands[name] = sub
+			}
+			cur = sub
+		}
+	}
+
+	return out, nil
 }
 
 // CheckVersionOption returns a ServeOption that checks whether the client ipfs version matches. Does nothing when the user agent string does not contain `/go-ipfs/`
@@ -0,0 +1,30 @@
+package corehttp
+
+import (
+	"testing"
+
+	corecommands "github.com/ipsn/go-ipfs/core/commands"
+)
+
+func TestRestrictedRoot(t *testing.T) {
+	root, err := restrictedRoot(corecommands.RootRO, []string{"cat", "name/resolve"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := root.Get([]string{"cat"}); err != nil {
+		t.Error("cat should be served:", err)
+	}
+	if _, err := root.Get([]string{"name", "resolve"}); err != nil {
+		t.Error("name/resolve should be served:", err)
+	}
+	if _, err := root.Get([]string{"ls"}); err == nil {
+		t.Error("ls is not in the allowlist and should not be served")
+	}
+}
+
+func TestRestrictedRootRejectsUnknown(t *testing.T) {
+	if _, err := restrictedRoot(corecommands.RootRO, []string{"pin/add"}); err == nil {
+		t.Error("expected an error for a command outside the read-only root")
+	}
+}
@@ -0,0 +1,145 @@
+package corehttp
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+)
+
+// ValidateCORSPolicies checks the per-route CORS configuration before
+// the API starts serving, so a bad policy fails the daemon instead of
+// silently exposing (or hiding) routes.
+func ValidateCORSPolicies(policies []config.APICORSPolicy) error {
+	seen := make(map[string]struct{}, len(policies))
+	for _, p := range policies {
+		if !strings.HasPrefix(p.PathPrefix, "/") {
+			return fmt.Errorf("CORS policy path prefix %q must start with /", p.PathPrefix)
+		}
+		if _, dup := seen[p.PathPrefix]; dup {
+			return fmt.Errorf("duplicate CORS policy for path prefix %q", p.PathPrefix)
+		}
+		seen[p.PathPrefix] = struct{}{}
+
+		if len(p.AllowOrigins) == 0 {
+			return fmt.Errorf("CORS policy for %q allows no origins", p.PathPrefix)
+		}
+		for _, o := range p.AllowOrigins {
+			if o == "*" && p.AllowCredentials {
+				return fmt.Errorf("CORS policy for %q cannot combine a wildcard origin with credentials", p.PathPrefix)
+			}
+		}
+	}
+	return nil
+}
+
+// corsPolicyWrapper applies per-route CORS policies: requests under a
+// configured path prefix get that policy's headers instead of the
+// global API.HTTPHeaders CORS configuration. The longest matching
+// prefix wins.
+func corsPolicyWrapper(policies []config.APICORSPolicy, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy := matchCORSPolicy(policies, r.URL.Path)
+		origin := r.Header.Get("Origin")
+		if policy == nil || origin == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		allowed := originAllowed(policy, origin)
+
+		// answer preflights directly
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if !allowed {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			setCORSHeaders(w.Header(), policy, origin)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		cw := &corsOverrideWriter{ResponseWriter: w, policy: policy, origin: origin, allowed: allowed}
+		h.ServeHTTP(cw, r)
+	})
+}
+
+func matchCORSPolicy(policies []config.APICORSPolicy, path string) *config.APICORSPolicy {
+	var match *config.APICORSPolicy
+	for i, p := range policies {
+		if !strings.HasPrefix(path, p.PathPrefix) {
+			continue
+		}
+		if match == nil || len(p.PathPrefix) > len(match.PathPrefix) {
+			match = &policies[i]
+		}
+	}
+	return match
+}
+
+func originAllowed(policy *config.APICORSPolicy, origin string) bool {
+	for _, o := range policy.AllowOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func setCORSHeaders(hdr http.Header, policy *config.APICORSPolicy, origin string) {
+	hdr.Set("Access-Control-Allow-Origin", origin)
+	hdr.Add("Vary", "Origin")
+
+	methods := policy.AllowMethods
+	if len(methods) == 0 {
+		methods = []string{"GET", "POST", "PUT"}
+	}
+	hdr.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+	if policy.AllowCredentials {
+		hdr.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// corsOverrideWriter replaces whatever CORS headers the inner handler
+// set with the matched policy's before the response is written.
+type corsOverrideWriter struct {
+	http.ResponseWriter
+	policy  *config.APICORSPolicy
+	origin  string
+	allowed bool
+
+	wrote bool
+}
+
+func (w *corsOverrideWriter) applyPolicy() {
+	if w.wrote {
+		return
+	}
+	w.wrote = true
+
+	hdr := w.Header()
+	hdr.Del("Access-Control-Allow-Origin")
+	hdr.Del("Access-Control-Allow-Methods")
+	hdr.Del("Access-Control-Allow-Credentials")
+	if w.allowed {
+		setCORSHeaders(hdr, w.policy, w.origin)
+	}
+}
+
+func (w *corsOverrideWriter) WriteHeader(status int) {
+	w.applyPolicy()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *corsOverrideWriter) Write(b []byte) (int, error) {
+	w.applyPolicy()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *corsOverrideWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
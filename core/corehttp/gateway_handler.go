@@ -2,9 +2,11 @@ package corehttp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
@@ -13,9 +15,11 @@ import (
 	"strings"
 	"time"
 
+	car "github.com/ipsn/go-ipfs/car"
 	"github.com/ipsn/go-ipfs/core"
 	coreiface "github.com/ipsn/go-ipfs/core/coreapi/interface"
 	"github.com/ipsn/go-ipfs/dagutils"
+	"github.com/ipsn/go-ipfs/plugin/events"
 
 	"github.com/dustin/go-humanize"
 	chunker "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-chunker"
@@ -68,6 +72,17 @@ func (i *gatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), time.Hour)
 	defer cancel()
 
+	if events.Active() {
+		start := time.Now()
+		defer func() {
+			events.Emit("gateway/request", map[string]interface{}{
+				"method":   r.Method,
+				"path":     r.URL.Path,
+				"duration": time.Since(start).String(),
+			})
+		}()
+	}
+
 	defer func() {
 		if r := recover(); r != nil {
 			log.Error("A panic occurred in the gateway handler!")
@@ -165,6 +180,14 @@ func (i *gatewayHandler) getOrHeadHandler(ctx context.Context, w http.ResponseWr
 		return
 	}
 
+	// Programmatic consumers can request the raw IPLD node instead of the
+	// unixfs interpretation by asking for a dag encoding in the Accept
+	// header.
+	if format := ipldResponseFormat(r); format != "" {
+		i.serveIpldNode(ctx, w, r, resolvedPath, format)
+		return
+	}
+
 	dr, err := i.api.Unixfs().Get(ctx, resolvedPath)
 	if err != nil {
 		webError(w, "ipfs cat "+escapedURLPath, err, http.StatusNotFound)
@@ -252,6 +275,13 @@ func (i *gatewayHandler) getOrHeadHandler(ctx context.Context, w http.ResponseWr
 		} else {
 			name = getFilename(urlPath)
 		}
+
+		// files added with a metadata node recording their content type
+		// get served with it, instead of whatever ServeContent sniffs
+		if mime := i.storedMimeType(ctx, resolvedPath); mime != "" {
+			w.Header().Set("Content-Type", mime)
+		}
+
 		i.serveFile(w, r, name, modtime, f)
 		return
 
@@ -378,6 +408,28 @@ func (s *sizeSeeker) Seek(offset int64, whence int) (int64, error) {
 	return s.sizeReadSeeker.Seek(offset, whence)
 }
 
+// storedMimeType returns the content type recorded in the unixfs metadata
+// node at p, if there is one.
+func (i *gatewayHandler) storedMimeType(ctx context.Context, p coreiface.ResolvedPath) string {
+	nd, err := i.api.ResolveNode(ctx, p)
+	if err != nil {
+		return ""
+	}
+	pbnd, ok := nd.(*dag.ProtoNode)
+	if !ok {
+		return ""
+	}
+	fsn, err := ft.FSNodeFromBytes(pbnd.Data())
+	if err != nil || fsn.Type() != ft.TMetadata {
+		return ""
+	}
+	md, err := ft.MetadataFromBytes(pbnd.Data())
+	if err != nil {
+		return ""
+	}
+	return md.MimeType
+}
+
 func (i *gatewayHandler) serveFile(w http.ResponseWriter, req *http.Request, name string, modtime time.Time, content io.ReadSeeker) {
 	if sp, ok := content.(sizeReadSeeker); ok {
 		content = &sizeSeeker{
@@ -388,23 +440,66 @@ func (i *gatewayHandler) serveFile(w http.ResponseWriter, req *http.Request, nam
 	http.ServeContent(w, req, name, modtime, content)
 }
 
+// carContentType is the content type a writable-gateway POST uses to
+// push a prepared dag as a CARv1 stream.
+const carContentType = "application/vnd.ipld.car"
+
+// maxCarUploadSize bounds car uploads on the writable gateway.
+const maxCarUploadSize = 1 << 30 // 1 GiB
+
 func (i *gatewayHandler) postHandler(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	uploadSize, err := i.checkUploadQuota(ctx, w, r)
+	if err != nil {
+		return
+	}
+
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct == carContentType {
+		i.postCarHandler(w, r, uploadSize)
+		return
+	}
+
 	p, err := i.api.Unixfs().Add(ctx, files.NewReaderFile(r.Body))
 	if err != nil {
 		internalWebError(w, err)
 		return
 	}
+	i.node.EthoFS.ChargeQuota(uploadSize)
 
 	i.addUserHeaders(w) // ok, _now_ write user's headers.
 	w.Header().Set("IPFS-Hash", p.Cid().String())
 	http.Redirect(w, r, p.String(), http.StatusCreated)
 }
 
+// postCarHandler imports a POSTed car stream into the blockstore and
+// answers with the roots listed in its header, one per line. The
+// roots are not pinned; the uploader (or a follow-up pin request)
+// decides what should stick around.
+func (i *gatewayHandler) postCarHandler(w http.ResponseWriter, r *http.Request, uploadSize uint64) {
+	roots, err := car.Import(i.node.Blocks, http.MaxBytesReader(w, r.Body, maxCarUploadSize))
+	if err != nil {
+		webError(w, "WritableGateway: failed to import car", err, http.StatusBadRequest)
+		return
+	}
+	i.node.EthoFS.ChargeQuota(uploadSize)
+
+	i.addUserHeaders(w) // ok, _now_ write user's headers.
+	w.Header().Set("IPFS-Hash", roots[0].String())
+	w.WriteHeader(http.StatusCreated)
+	for _, c := range roots {
+		fmt.Fprintf(w, "%s\n", c)
+	}
+}
+
 func (i *gatewayHandler) putHandler(w http.ResponseWriter, r *http.Request) {
 	// TODO(cryptix): move me to ServeHTTP and pass into all handlers
 	ctx, cancel := context.WithCancel(i.node.Context())
 	defer cancel()
 
+	uploadSize, err := i.checkUploadQuota(ctx, w, r)
+	if err != nil {
+		return
+	}
+
 	rootPath, err := path.ParsePath(r.URL.Path)
 	if err != nil {
 		webError(w, "putHandler: IPFS path not valid", err, http.StatusBadRequest)
@@ -502,6 +597,8 @@ func (i *gatewayHandler) putHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	i.node.EthoFS.ChargeQuota(uploadSize)
+
 	i.addUserHeaders(w) // ok, _now_ write user's headers.
 	w.Header().Set("IPFS-Hash", newcid.String())
 	http.Redirect(w, r, gopath.Join(ipfsPathPrefix, newcid.String(), newPath), http.StatusCreated)
@@ -584,6 +681,74 @@ func (i *gatewayHandler) deleteHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, gopath.Join(ipfsPathPrefix+ncid.String(), path.Join(components[:len(components)-1])), http.StatusCreated)
 }
 
+// response formats the gateway can encode IPLD nodes in, requested
+// via the Accept header
+const (
+	dagJSONResponseFormat = "application/vnd.ipld.dag-json"
+	dagCBORResponseFormat = "application/vnd.ipld.dag-cbor"
+)
+
+// ipldResponseFormat returns the IPLD encoding requested through the Accept
+// header, or "" when the client wants the usual unixfs response.
+func ipldResponseFormat(r *http.Request) string {
+	for _, header := range r.Header["Accept"] {
+		for _, accept := range strings.Split(header, ",") {
+			mediatype, _, err := mime.ParseMediaType(accept)
+			if err != nil {
+				continue
+			}
+			if mediatype == dagJSONResponseFormat || mediatype == dagCBORResponseFormat {
+				return mediatype
+			}
+		}
+	}
+	return ""
+}
+
+// serveIpldNode writes the resolved node in the requested dag encoding,
+// using the same per-codec marshalling 'ipfs dag get' relies on.
+func (i *gatewayHandler) serveIpldNode(ctx context.Context, w http.ResponseWriter, r *http.Request, resolvedPath coreiface.ResolvedPath, format string) {
+	nd, err := i.api.ResolveNode(ctx, resolvedPath)
+	if err != nil {
+		webError(w, "ipfs dag get "+r.URL.EscapedPath(), err, http.StatusNotFound)
+		return
+	}
+
+	var data []byte
+	switch format {
+	case dagJSONResponseFormat:
+		// every node type knows how to marshal itself to JSON; this is
+		// what the api port returns for 'ipfs dag get'
+		data, err = json.Marshal(nd)
+		if err != nil {
+			internalWebError(w, err)
+			return
+		}
+	case dagCBORResponseFormat:
+		if nd.Cid().Type() != cid.DagCBOR {
+			err := fmt.Errorf("%s nodes cannot be returned as dag-cbor", cid.CodecToStr[nd.Cid().Type()])
+			webErrorWithCode(w, "unsupported response format", err, http.StatusNotAcceptable)
+			return
+		}
+		data = nd.RawData()
+	}
+
+	etag := "\"" + resolvedPath.Cid().String() + "\""
+	if r.Header.Get("If-None-Match") == etag || r.Header.Get("If-None-Match") == "W/"+etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	i.addUserHeaders(w)
+	w.Header().Set("Content-Type", format)
+	w.Header().Set("X-IPFS-Path", r.URL.Path)
+	w.Header().Set("Etag", etag)
+	if strings.HasPrefix(r.URL.Path, ipfsPathPrefix) {
+		w.Header().Set("Cache-Control", "public, max-age=29030400, immutable")
+	}
+	w.Write(data)
+}
+
 func (i *gatewayHandler) addUserHeaders(w http.ResponseWriter) {
 	for k, v := range i.config.Headers {
 		w.Header()[k] = v
@@ -623,3 +788,18 @@ func getFilename(s string) string {
 	}
 	return gopath.Base(s)
 }
+
+// checkUploadQuota admits a writable-gateway upload against the
+// hosting contract quota, writing the error response itself when the
+// upload is rejected. It returns the size to charge on success.
+func (i *gatewayHandler) checkUploadQuota(ctx context.Context, w http.ResponseWriter, r *http.Request) (uint64, error) {
+	var size uint64
+	if r.ContentLength > 0 {
+		size = uint64(r.ContentLength)
+	}
+	if err := i.node.EthoFS.CheckQuota(ctx, size); err != nil {
+		webError(w, "WritableGateway: upload rejected", err, http.StatusInsufficientStorage)
+		return 0, err
+	}
+	return size, nil
+}
@@ -0,0 +1,123 @@
+package corehttp
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+
+	core "github.com/ipsn/go-ipfs/core"
+
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+)
+
+// healthCheck is the status of a single subsystem in a health report.
+type healthCheck struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// healthReport is the machine-readable body served by the health
+// endpoints.
+type healthReport struct {
+	Status string                 `json:"status"` // "ok" or "unhealthy"
+	Checks map[string]healthCheck `json:"checks,omitempty"`
+}
+
+// HealthOption adds /health/live and /health/ready endpoints.
+//
+// Liveness only proves the HTTP server is responding. Readiness checks
+// the subsystems an orchestrator cares about before routing traffic to
+// the node: repo accessible, swarm listening, at least one bootstrap
+// peer connected and the DHT routing table populated, and returns 503
+// with per-check detail when any of them fail.
+func HealthOption() ServeOption {
+	return func(n *core.IpfsNode, _ net.Listener, mux *http.ServeMux) (*http.ServeMux, error) {
+		mux.HandleFunc("/health/live", func(w http.ResponseWriter, r *http.Request) {
+			serveHealth(w, http.StatusOK, &healthReport{Status: "ok"})
+		})
+		mux.HandleFunc("/health/ready", func(w http.ResponseWriter, r *http.Request) {
+			report := checkReadiness(n)
+
+			status := http.StatusOK
+			if report.Status != "ok" {
+				status = http.StatusServiceUnavailable
+			}
+			serveHealth(w, status, report)
+		})
+		return mux, nil
+	}
+}
+
+func serveHealth(w http.ResponseWriter, status int, report *healthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+func checkReadiness(n *core.IpfsNode) *healthReport {
+	report := &healthReport{
+		Status: "ok",
+		Checks: make(map[string]healthCheck),
+	}
+	fail := func(name, detail string) {
+		report.Status = "unhealthy"
+		report.Checks[name] = healthCheck{Detail: detail}
+	}
+	pass := func(name string) {
+		report.Checks[name] = healthCheck{OK: true}
+	}
+
+	cfg, err := n.Repo.Config()
+	if err != nil {
+		fail("repo", err.Error())
+	} else {
+		pass("repo")
+	}
+
+	if !n.OnlineMode() {
+		fail("swarm", "node is offline")
+		return report
+	}
+
+	if len(n.PeerHost.Network().ListenAddresses()) == 0 {
+		fail("swarm", "not listening on any address")
+	} else {
+		pass("swarm")
+	}
+
+	if cfg != nil {
+		if bootstrapConnected(n, cfg) {
+			pass("bootstrap")
+		} else {
+			fail("bootstrap", "no bootstrap peers connected")
+		}
+	}
+
+	if n.DHT != nil {
+		if n.DHT.RoutingTable().Size() > 0 {
+			pass("dht")
+		} else {
+			fail("dht", "routing table is empty")
+		}
+	}
+
+	return report
+}
+
+// bootstrapConnected reports whether at least one configured bootstrap
+// peer is currently connected. Nodes with no bootstrap peers configured
+// are considered connected.
+func bootstrapConnected(n *core.IpfsNode, cfg *config.Config) bool {
+	bpeers, err := cfg.BootstrapPeers()
+	if err != nil || len(bpeers) == 0 {
+		return true
+	}
+
+	for _, bp := range bpeers {
+		if n.PeerHost.Network().Connectedness(bp.ID()) == inet.Connected {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 
 	core "github.com/ipsn/go-ipfs/core"
 
+	bitswap "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-bitswap"
 	prometheus "github.com/prometheus/client_golang/prometheus"
 	promhttp "github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -97,6 +98,38 @@ var (
 	peersTotalMetric = prometheus.NewDesc(
 		prometheus.BuildFQName("ipfs", "p2p", "peers_total"),
 		"Number of connected peers", []string{"transport"}, nil)
+
+	bitswapWantlistMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("ipfs", "bitswap", "wantlist_total"),
+		"Current size of the bitswap wantlist", nil, nil)
+	bitswapPeersMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("ipfs", "bitswap", "peers_total"),
+		"Number of bitswap partners", nil, nil)
+	bitswapBlocksReceivedMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("ipfs", "bitswap", "blocks_received_total"),
+		"Total number of blocks received over bitswap", nil, nil)
+	bitswapBlocksSentMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("ipfs", "bitswap", "blocks_sent_total"),
+		"Total number of blocks sent over bitswap", nil, nil)
+	bitswapDataReceivedMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("ipfs", "bitswap", "data_received_bytes_total"),
+		"Total number of data bytes received over bitswap", nil, nil)
+	bitswapDataSentMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("ipfs", "bitswap", "data_sent_bytes_total"),
+		"Total number of data bytes sent over bitswap", nil, nil)
+	bitswapDupBlocksMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("ipfs", "bitswap", "dup_blocks_received_total"),
+		"Total number of duplicate blocks received over bitswap", nil, nil)
+	bitswapProvideQueueMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("ipfs", "bitswap", "provide_queue_total"),
+		"Current number of blocks queued for providing", nil, nil)
+
+	pinsRecursiveMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("ipfs", "pin", "recursive_total"),
+		"Number of recursively pinned keys", nil, nil)
+	pinsDirectMetric = prometheus.NewDesc(
+		prometheus.BuildFQName("ipfs", "pin", "direct_total"),
+		"Number of directly pinned keys", nil, nil)
 )
 
 type IpfsNodeCollector struct {
@@ -105,6 +138,16 @@ type IpfsNodeCollector struct {
 
 func (_ IpfsNodeCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- peersTotalMetric
+	ch <- bitswapWantlistMetric
+	ch <- bitswapPeersMetric
+	ch <- bitswapBlocksReceivedMetric
+	ch <- bitswapBlocksSentMetric
+	ch <- bitswapDataReceivedMetric
+	ch <- bitswapDataSentMetric
+	ch <- bitswapDupBlocksMetric
+	ch <- bitswapProvideQueueMetric
+	ch <- pinsRecursiveMetric
+	ch <- pinsDirectMetric
 }
 
 func (c IpfsNodeCollector) Collect(ch chan<- prometheus.Metric) {
@@ -116,6 +159,40 @@ func (c IpfsNodeCollector) Collect(ch chan<- prometheus.Metric) {
 			tr,
 		)
 	}
+
+	c.collectBitswap(ch)
+	c.collectPins(ch)
+}
+
+func (c IpfsNodeCollector) collectBitswap(ch chan<- prometheus.Metric) {
+	bs, ok := c.Node.Exchange.(*bitswap.Bitswap)
+	if !ok {
+		return
+	}
+
+	st, err := bs.Stat()
+	if err != nil {
+		log.Errorf("failed to collect bitswap metrics: %s", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(bitswapWantlistMetric, prometheus.GaugeValue, float64(len(st.Wantlist)))
+	ch <- prometheus.MustNewConstMetric(bitswapPeersMetric, prometheus.GaugeValue, float64(len(st.Peers)))
+	ch <- prometheus.MustNewConstMetric(bitswapBlocksReceivedMetric, prometheus.CounterValue, float64(st.BlocksReceived))
+	ch <- prometheus.MustNewConstMetric(bitswapBlocksSentMetric, prometheus.CounterValue, float64(st.BlocksSent))
+	ch <- prometheus.MustNewConstMetric(bitswapDataReceivedMetric, prometheus.CounterValue, float64(st.DataReceived))
+	ch <- prometheus.MustNewConstMetric(bitswapDataSentMetric, prometheus.CounterValue, float64(st.DataSent))
+	ch <- prometheus.MustNewConstMetric(bitswapDupBlocksMetric, prometheus.CounterValue, float64(st.DupBlksReceived))
+	ch <- prometheus.MustNewConstMetric(bitswapProvideQueueMetric, prometheus.GaugeValue, float64(st.ProvideBufLen))
+}
+
+func (c IpfsNodeCollector) collectPins(ch chan<- prometheus.Metric) {
+	if c.Node.Pinning == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(pinsRecursiveMetric, prometheus.GaugeValue, float64(len(c.Node.Pinning.RecursiveKeys())))
+	ch <- prometheus.MustNewConstMetric(pinsDirectMetric, prometheus.GaugeValue, float64(len(c.Node.Pinning.DirectKeys())))
 }
 
 func (c IpfsNodeCollector) PeersTotalValues() map[string]float64 {
@@ -2,6 +2,7 @@ package corehttp
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httputil"
@@ -11,12 +12,19 @@ import (
 	core "github.com/ipsn/go-ipfs/core"
 
 	p2phttp "github.com/ipsn/go-ipfs/gxlibs/github.com/hsanjuan/go-libp2p-http"
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
 	protocol "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-protocol"
 )
 
 // ProxyOption is an endpoint for proxying a HTTP request to another ipfs peer
 func ProxyOption() ServeOption {
 	return func(ipfsNode *core.IpfsNode, _ net.Listener, mux *http.ServeMux) (*http.ServeMux, error) {
+		cfg, err := ipfsNode.Repo.Config()
+		if err != nil {
+			return nil, err
+		}
+		pcfg := cfg.P2PProxy
+
 		mux.HandleFunc("/p2p/", func(w http.ResponseWriter, request *http.Request) {
 			// parse request
 			parsedRequest, err := parseRequest(request)
@@ -25,23 +33,108 @@ func ProxyOption() ServeOption {
 				return
 			}
 
-			request.Host = "" // Let URL's Host take precedence.
 			request.URL.Path = parsedRequest.httpPath
-			target, err := url.Parse(fmt.Sprintf("libp2p://%s", parsedRequest.target))
-			if err != nil {
-				handleError(w, "failed to parse url", err, 400)
-				return
+			serveProxy(ipfsNode, &pcfg, w, request, parsedRequest.target, parsedRequest.name)
+		})
+
+		// hostname/path based routing rules proxy matching requests
+		// to a fixed peer
+		for _, rule := range pcfg.Rules {
+			rule := rule
+
+			name := protocol.ID("/http")
+			if rule.Protocol != "" {
+				name = protocol.ID(rule.Protocol)
 			}
 
-			rt := p2phttp.NewTransport(ipfsNode.PeerHost, p2phttp.ProtocolOption(parsedRequest.name))
-			proxy := httputil.NewSingleHostReverseProxy(target)
-			proxy.Transport = rt
-			proxy.ServeHTTP(w, request)
-		})
+			prefix := rule.PathPrefix
+			if prefix == "" {
+				prefix = "/"
+			}
+
+			mux.HandleFunc(rule.Host+prefix, func(w http.ResponseWriter, request *http.Request) {
+				serveProxy(ipfsNode, &pcfg, w, request, rule.Peer, name)
+			})
+		}
+
 		return mux, nil
 	}
 }
 
+// serveProxy checks authentication and size limits, applies header
+// rewrites and proxies the request to the target peer
+func serveProxy(ipfsNode *core.IpfsNode, pcfg *config.P2PProxy, w http.ResponseWriter, request *http.Request, target string, name protocol.ID) {
+	if pcfg.AuthSecret != "" {
+		if request.Header.Get("Authorization") != "Bearer "+pcfg.AuthSecret {
+			handleError(w, "proxy access denied", fmt.Errorf("missing or invalid authorization"), 401)
+			return
+		}
+		request.Header.Del("Authorization")
+	}
+
+	if pcfg.MaxRequestBodySize > 0 {
+		if request.ContentLength > pcfg.MaxRequestBodySize {
+			handleError(w, "proxy request rejected", fmt.Errorf("request body exceeds %d bytes", pcfg.MaxRequestBodySize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		request.Body = http.MaxBytesReader(w, request.Body, pcfg.MaxRequestBodySize)
+	}
+
+	for _, h := range pcfg.RemoveHeaders {
+		request.Header.Del(h)
+	}
+	for h, v := range pcfg.SetHeaders {
+		request.Header[http.CanonicalHeaderKey(h)] = v
+	}
+
+	request.Host = "" // Let URL's Host take precedence.
+	targetURL, err := url.Parse(fmt.Sprintf("libp2p://%s", target))
+	if err != nil {
+		handleError(w, "failed to parse url", err, 400)
+		return
+	}
+
+	rt := p2phttp.NewTransport(ipfsNode.PeerHost, p2phttp.ProtocolOption(name))
+	proxy := httputil.NewSingleHostReverseProxy(targetURL)
+	proxy.Transport = rt
+	if pcfg.MaxResponseBodySize > 0 {
+		proxy.ModifyResponse = func(resp *http.Response) error {
+			if resp.ContentLength > pcfg.MaxResponseBodySize {
+				return fmt.Errorf("response body exceeds %d bytes", pcfg.MaxResponseBodySize)
+			}
+			resp.Body = limitReadCloser(resp.Body, pcfg.MaxResponseBodySize)
+			return nil
+		}
+	}
+	proxy.ServeHTTP(w, request)
+}
+
+// limitReadCloser bounds rc, erroring out once limit bytes were read
+func limitReadCloser(rc io.ReadCloser, limit int64) io.ReadCloser {
+	return &limitedReadCloser{rc: rc, n: limit}
+}
+
+type limitedReadCloser struct {
+	rc io.ReadCloser
+	n  int64
+}
+
+func (l *limitedReadCloser) Read(b []byte) (int, error) {
+	if l.n <= 0 {
+		return 0, fmt.Errorf("response body size limit exceeded")
+	}
+	if int64(len(b)) > l.n {
+		b = b[:l.n]
+	}
+	n, err := l.rc.Read(b)
+	l.n -= int64(n)
+	return n, err
+}
+
+func (l *limitedReadCloser) Close() error {
+	return l.rc.Close()
+}
+
 type proxyRequest struct {
 	target   string
 	name     protocol.ID
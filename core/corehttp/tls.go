@@ -0,0 +1,122 @@
+package corehttp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+)
+
+const (
+	apiCertFile = "api.crt"
+	apiKeyFile  = "api.key"
+
+	// apiCertValidity is the lifetime of repo-generated API certificates.
+	apiCertValidity = 10 * 365 * 24 * time.Hour
+)
+
+// APICertPath returns the location of the repo-generated API
+// certificate.
+func APICertPath(repoPath string) string {
+	return filepath.Join(repoPath, apiCertFile)
+}
+
+// LoadAPICert returns the certificate the API server should present.
+// Explicitly configured certificate files take precedence; otherwise a
+// self-signed certificate is loaded from the repo, generated first if
+// necessary.
+func LoadAPICert(repoPath string, cfg config.APITLS) (tls.Certificate, error) {
+	if cfg.CertFile != "" {
+		return tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	}
+
+	certPath := APICertPath(repoPath)
+	keyPath := filepath.Join(repoPath, apiKeyFile)
+
+	if _, err := os.Stat(certPath); os.IsNotExist(err) {
+		if err := generateAPICert(certPath, keyPath); err != nil {
+			return tls.Certificate{}, fmt.Errorf("generating API certificate: %s", err)
+		}
+	}
+
+	return tls.LoadX509KeyPair(certPath, keyPath)
+}
+
+// CertFingerprint returns the sha256 fingerprint of the certificate in
+// the form reported by 'ipfs id'.
+func CertFingerprint(cert tls.Certificate) string {
+	if len(cert.Certificate) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(cert.Certificate[0])
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// generateAPICert writes a fresh self-signed certificate and key for
+// the local API endpoint.
+func generateAPICert(certPath, keyPath string) error {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "ipfs-api"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(apiCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		return err
+	}
+
+	certOut, err := os.OpenFile(certPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if err := pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		certOut.Close()
+		return err
+	}
+	if err := certOut.Close(); err != nil {
+		return err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		return err
+	}
+	keyOut, err := os.OpenFile(keyPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := pem.Encode(keyOut, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}); err != nil {
+		keyOut.Close()
+		return err
+	}
+	return keyOut.Close()
+}
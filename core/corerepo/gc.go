@@ -14,10 +14,39 @@ import (
 	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
 	mfs "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-mfs"
 	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	prometheus "github.com/prometheus/client_golang/prometheus"
 )
 
 var log = logging.Logger("corerepo")
 
+var (
+	gcRunsMetric = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ipfs",
+		Subsystem: "gc",
+		Name:      "runs_total",
+		Help:      "Total number of garbage collection runs.",
+	})
+
+	gcBlocksRemovedMetric = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ipfs",
+		Subsystem: "gc",
+		Name:      "blocks_removed_total",
+		Help:      "Total number of blocks removed by garbage collection.",
+	})
+
+	gcDurationMetric = prometheus.NewSummary(prometheus.SummaryOpts{
+		Namespace:  "ipfs",
+		Subsystem:  "gc",
+		Name:       "duration_seconds",
+		Help:       "Garbage collection run durations in seconds.",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	})
+)
+
+func init() {
+	prometheus.MustRegister(gcRunsMetric, gcBlocksRemovedMetric, gcDurationMetric)
+}
+
 var ErrMaxStorageExceeded = errors.New("maximum storage limit exceeded. Try to unpin some files")
 
 type GC struct {
@@ -95,6 +124,11 @@ func GarbageCollect(n *core.IpfsNode, ctx context.Context) error {
 // given callback for each object removed.  It also collects all errors into a
 // MultiError which is returned after the gc is completed.
 func CollectResult(ctx context.Context, gcOut <-chan gc.Result, cb func(cid.Cid)) error {
+	gcRunsMetric.Inc()
+	defer func(start time.Time) {
+		gcDurationMetric.Observe(time.Since(start).Seconds())
+	}(time.Now())
+
 	var errors []error
 loop:
 	for {
@@ -105,8 +139,11 @@ loop:
 			}
 			if res.Error != nil {
 				errors = append(errors, res.Error)
-			} else if res.KeyRemoved.Defined() && cb != nil {
-				cb(res.KeyRemoved)
+			} else if res.KeyRemoved.Defined() {
+				gcBlocksRemovedMetric.Inc()
+				if cb != nil {
+					cb(res.KeyRemoved)
+				}
 			}
 		case <-ctx.Done():
 			errors = append(errors, ctx.Err())
@@ -146,6 +183,13 @@ func (e *MultiError) Error() string {
 }
 
 func GarbageCollectAsync(n *core.IpfsNode, ctx context.Context) <-chan gc.Result {
+	return GarbageCollectAsyncWithOpts(n, ctx, gc.Opts{})
+}
+
+// GarbageCollectAsyncWithOpts is like GarbageCollectAsync but bounds the run
+// with the given budgets, allowing large repos to reclaim space incrementally
+// instead of in one long stop-the-world sweep.
+func GarbageCollectAsyncWithOpts(n *core.IpfsNode, ctx context.Context, opts gc.Opts) <-chan gc.Result {
 	roots, err := BestEffortRoots(n.FilesRoot)
 	if err != nil {
 		out := make(chan gc.Result)
@@ -154,7 +198,21 @@ func GarbageCollectAsync(n *core.IpfsNode, ctx context.Context) <-chan gc.Result
 		return out
 	}
 
-	return gc.GC(ctx, n.Blockstore, n.Repo.Datastore(), n.Pinning, roots)
+	return gc.GCWithOpts(ctx, n.Blockstore, n.Repo.Datastore(), n.Pinning, roots, opts)
+}
+
+// gcTimeBudgetPerSlackGB bounds watermark-triggered GC runs: every gigabyte
+// of slack between the watermark and StorageMax buys this much sweep time.
+const gcTimeBudgetPerSlackGB = time.Minute
+
+// incrementalOpts builds the budgets for a watermark-triggered GC run. It is
+// a package-level helper because the GC methods shadow the gc package with
+// their receiver.
+func incrementalOpts(targetFree uint64, slackGB uint64) gc.Opts {
+	return gc.Opts{
+		TargetFreeBytes: targetFree,
+		MaxDuration:     time.Duration(slackGB) * gcTimeBudgetPerSlackGB,
+	}
 }
 
 func PeriodicGC(ctx context.Context, node *core.IpfsNode) error {
@@ -213,11 +271,14 @@ func (gc *GC) maybeGC(ctx context.Context, offset uint64) error {
 			log.Warningf("pre-GC: %s", ErrMaxStorageExceeded)
 		}
 
-		// Do GC here
+		// Do an incremental GC here: free just enough to get back under
+		// the watermark and bound the run with the slack-derived time
+		// budget so background collection doesn't block adds for long
 		log.Info("Watermark exceeded. Starting repo GC...")
 		defer log.EventBegin(ctx, "repoGC").Done()
 
-		if err := GarbageCollect(gc.Node, ctx); err != nil {
+		gcOutChan := GarbageCollectAsyncWithOpts(gc.Node, ctx, incrementalOpts(storage+offset-gc.StorageGC, gc.SlackGB))
+		if err := CollectResult(ctx, gcOutChan, nil); err != nil {
 			return err
 		}
 		log.Infof("Repo GC done. See `ipfs repo stat` to see how much space got freed.\n")
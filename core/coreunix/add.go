@@ -24,6 +24,7 @@ import (
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-unixfs/importer/balanced"
 	ihelper "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-unixfs/importer/helpers"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-unixfs/importer/trickle"
+	uio "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-unixfs/io"
 	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
 	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
 	dag "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-merkledag"
@@ -34,6 +35,10 @@ var log = logging.Logger("coreunix")
 // how many bytes of progress to wait before sending a progress update message
 const progressReaderIncrement = 1024 * 256
 
+// how many directory entries are imported concurrently when the
+// source supports it
+const maxAsyncFiles = 4
+
 var liveCacheSize = uint64(256 << 10)
 
 type Link struct {
@@ -81,6 +86,7 @@ type Adder struct {
 	RawLeaves  bool
 	Silent     bool
 	Wrap       bool
+	DetectMime bool
 	Name       string
 	NoCopy     bool
 	Chunker    string
@@ -113,16 +119,23 @@ func (adder *Adder) SetMfsRoot(r *mfs.Root) {
 
 // Constructs a node from reader's data, and adds it. Doesn't pin.
 func (adder *Adder) add(reader io.Reader) (ipld.Node, error) {
+	// Make sure all added nodes are written when done.
+	defer adder.bufferedDS.Commit()
+
+	return adder.addTo(reader, adder.bufferedDS)
+}
+
+// addTo is the core of `add`, building the dag through the given
+// DAGService. Concurrent file imports each get their own buffered
+// DAGService, so they never share `adder.bufferedDS`.
+func (adder *Adder) addTo(reader io.Reader, ds ipld.DAGService) (ipld.Node, error) {
 	chnk, err := chunker.FromString(reader, adder.Chunker)
 	if err != nil {
 		return nil, err
 	}
 
-	// Make sure all added nodes are written when done.
-	defer adder.bufferedDS.Commit()
-
 	params := ihelper.DagBuilderParams{
-		Dagserv:    adder.bufferedDS,
+		Dagserv:    ds,
 		RawLeaves:  adder.RawLeaves,
 		Maxlinks:   ihelper.DefaultLinksPerBlock,
 		NoCopy:     adder.NoCopy,
@@ -510,6 +523,13 @@ func (adder *Adder) addFile(path string, file files.File) error {
 		return err
 	}
 
+	if adder.DetectMime {
+		dagnode, err = adder.wrapWithMime(dagnode)
+		if err != nil {
+			return err
+		}
+	}
+
 	addFileInfo, ok := file.(files.FileInfo)
 	if ok {
 		if addFileInfo.AbsPath() == os.Stdin.Name() && adder.Name != "" {
@@ -521,6 +541,39 @@ func (adder *Adder) addFile(path string, file files.File) error {
 	return adder.addNode(dagnode, path)
 }
 
+// wrapWithMime wraps a freshly built file dag in a unixfs metadata node
+// recording the sniffed content type, so that stat and the gateway can
+// recover it later. The file's blocks must already be committed.
+func (adder *Adder) wrapWithMime(node ipld.Node) (ipld.Node, error) {
+	dr, err := uio.NewDagReader(adder.ctx, node, adder.dagService)
+	if err != nil {
+		return nil, err
+	}
+	defer dr.Close()
+
+	mime, err := DetectMimeType(dr)
+	if err != nil {
+		return nil, err
+	}
+
+	mdata, err := unixfs.BytesForMetadata(&unixfs.Metadata{MimeType: mime})
+	if err != nil {
+		return nil, err
+	}
+
+	mdnode := new(dag.ProtoNode)
+	mdnode.SetCidBuilder(adder.CidBuilder)
+	mdnode.SetData(mdata)
+	if err := mdnode.AddNodeLink("file", node); err != nil {
+		return nil, err
+	}
+
+	if err := adder.dagService.Add(adder.ctx, mdnode); err != nil {
+		return nil, err
+	}
+	return mdnode, nil
+}
+
 func (adder *Adder) addDir(path string, dir files.Directory) error {
 	log.Infof("adding directory: %s", path)
 
@@ -537,6 +590,24 @@ func (adder *Adder) addDir(path string, dir files.Directory) error {
 		return err
 	}
 
+	// Plain file entries have their dags built concurrently (bounded
+	// by maxAsyncFiles); the finished nodes are patched into the mfs
+	// root in directory order by flush.
+	var pending []*asyncFileAdd
+	flush := func() error {
+		for _, af := range pending {
+			<-af.done
+			if af.err != nil {
+				return af.err
+			}
+			if err := adder.addNode(af.node, af.path); err != nil {
+				return err
+			}
+		}
+		pending = nil
+		return nil
+	}
+
 	it := dir.Entries()
 	for it.Next() {
 		fpath := gopath.Join(path, it.Name())
@@ -546,15 +617,83 @@ func (adder *Adder) addDir(path string, dir files.Directory) error {
 			log.Infof("%s is hidden, skipping", fpath)
 			continue
 		}
+
+		if f, ok := it.Node().(files.File); ok && adder.canAddAsync(f) {
+			if err := adder.maybePauseForGC(); err != nil {
+				return err
+			}
+			adder.liveNodes++
+			pending = append(pending, adder.addFileAsync(fpath, f))
+			if len(pending) >= maxAsyncFiles {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// entries that can't go through the async path (directories,
+		// symlinks, streamed files) keep their ordering relative to
+		// the pending files
+		if err := flush(); err != nil {
+			return err
+		}
 		err = adder.addFileNode(fpath, it.Node())
 		if err != nil {
 			return err
 		}
 	}
+	if err := flush(); err != nil {
+		return err
+	}
 
 	return it.Err()
 }
 
+// canAddAsync reports whether a directory entry may be imported out
+// of band: its content has to be independently readable (multipart
+// bodies are consumed as a single stream) and per-file progress
+// reporting has to be off, since interleaved progress events would
+// garble the client's accounting.
+func (adder *Adder) canAddAsync(f files.File) bool {
+	if adder.Progress {
+		return false
+	}
+	_, ok := f.(files.FileInfo)
+	return ok
+}
+
+// asyncFileAdd is a file whose dag is being built in the background.
+type asyncFileAdd struct {
+	path string
+	node ipld.Node
+	err  error
+	done chan struct{}
+}
+
+// addFileAsync builds the file's dag on a separate goroutine, writing
+// the blocks through a private buffered DAGService. The caller is
+// responsible for patching the resulting node into the mfs root (in
+// order) once done is closed.
+func (adder *Adder) addFileAsync(path string, file files.File) *asyncFileAdd {
+	af := &asyncFileAdd{path: path, done: make(chan struct{})}
+	go func() {
+		defer close(af.done)
+		defer file.Close()
+
+		bds := ipld.NewBufferedDAG(adder.ctx, adder.dagService)
+		nd, err := adder.addTo(file, bds)
+		if err == nil {
+			err = bds.Commit()
+		}
+		if err == nil && adder.DetectMime {
+			nd, err = adder.wrapWithMime(nd)
+		}
+		af.node, af.err = nd, err
+	}()
+	return af
+}
+
 func (adder *Adder) maybePauseForGC() error {
 	if adder.unlocker != nil && adder.gcLocker.GCRequested() {
 		err := adder.PinRoot()
@@ -3,6 +3,7 @@ package coreunix
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
@@ -50,6 +51,53 @@ func TestAddRecursive(t *testing.T) {
 	}
 }
 
+func BenchmarkAddManyFiles(b *testing.B) {
+	r := &repo.Mock{
+		C: config.Config{
+			Identity: config.Identity{
+				PeerID: testPeerID, // required by offline node
+			},
+		},
+		D: syncds.MutexWrap(datastore.NewMapDatastore()),
+	}
+	node, err := core.NewNode(context.Background(), &core.BuildCfg{Repo: r})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	const numFiles = 64
+	const fileSize = 1 << 16
+
+	bufs := make([][]byte, numFiles)
+	rnd := rand.New(rand.NewSource(42))
+	for i := range bufs {
+		bufs[i] = make([]byte, fileSize)
+		rnd.Read(bufs[i])
+	}
+
+	b.SetBytes(numFiles * fileSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		entries := make(map[string]files.Node, numFiles)
+		for j := range bufs {
+			entries[fmt.Sprintf("file-%03d", j)] = files.NewBytesFile(bufs[j])
+		}
+		dir := files.NewMapDirectory(entries)
+		b.StartTimer()
+
+		adder, err := NewAdder(context.Background(), node.Pinning, node.Blockstore, node.DAG)
+		if err != nil {
+			b.Fatal(err)
+		}
+		adder.Pin = false
+
+		if _, err := adder.AddAllAndPin(dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestAddGCLive(t *testing.T) {
 	r := &repo.Mock{
 		C: config.Config{
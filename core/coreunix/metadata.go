@@ -1,12 +1,35 @@
 package coreunix
 
 import (
+	"io"
+	"net/http"
+
 	core "github.com/ipsn/go-ipfs/core"
 	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
 	ft "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-unixfs"
 	dag "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-merkledag"
 )
 
+// mimeSniffLen is how many leading bytes content type detection looks at,
+// matching what net/http sniffs.
+const mimeSniffLen = 512
+
+// DetectMimeType sniffs the content type of the data in r from its first
+// bytes, the same way the http package does. It only fails when reading
+// fails; unrecognizable data comes back as application/octet-stream.
+func DetectMimeType(r io.Reader) (string, error) {
+	buf := make([]byte, mimeSniffLen)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// AddMetadataTo wraps the node at skey in a new unixfs metadata node
+// carrying m, and returns the key of the wrapper. Readers resolve the
+// wrapper transparently, so the original content stays reachable under
+// the new key.
 func AddMetadataTo(n *core.IpfsNode, skey string, m *ft.Metadata) (string, error) {
 	c, err := cid.Decode(skey)
 	if err != nil {
@@ -37,6 +60,7 @@ func AddMetadataTo(n *core.IpfsNode, skey string, m *ft.Metadata) (string, error
 	return mdnode.Cid().String(), nil
 }
 
+// Metadata reads the metadata stored in the unixfs metadata node at skey.
 func Metadata(n *core.IpfsNode, skey string) (*ft.Metadata, error) {
 	c, err := cid.Decode(skey)
 	if err != nil {
@@ -11,6 +11,7 @@ import (
 	resolver "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-path/resolver"
 	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
 	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	opentracing "github.com/opentracing/opentracing-go"
 )
 
 // ErrNoNamesys is an explicit error for when an IPFS node doesn't
@@ -65,6 +66,10 @@ func ResolveIPNS(ctx context.Context, nsys namesys.NameSystem, p path.Path) (pat
 // entries (e.g. /ipns/<node-key>) and then going through the /ipfs/
 // entries and returning the final node.
 func Resolve(ctx context.Context, nsys namesys.NameSystem, r *resolver.Resolver, p path.Path) (ipld.Node, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "core.Resolve")
+	span.SetTag("path", p.String())
+	defer span.Finish()
+
 	p, err := ResolveIPNS(ctx, nsys, p)
 	if err != nil {
 		return nil, err
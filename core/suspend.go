@@ -0,0 +1,85 @@
+package core
+
+import (
+	"errors"
+
+	net "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+)
+
+// Suspend tears down the node's networking at runtime without stopping the
+// daemon: open connections are closed, new ones are refused and the
+// bootstrapper and reprovider are paused. The API and gateway keep serving
+// local content. Resume re-establishes networking.
+func (n *IpfsNode) Suspend() error {
+	n.suspendLk.Lock()
+	defer n.suspendLk.Unlock()
+
+	if n.PeerHost == nil {
+		return errors.New("cannot suspend: node is not online")
+	}
+	if n.suspended {
+		return errors.New("networking is already suspended")
+	}
+	n.suspended = true
+
+	// refuse connections opened while suspended; removed again on Resume
+	n.suspendNotifiee = &suspendNotifiee{}
+	n.PeerHost.Network().Notify(n.suspendNotifiee)
+
+	if n.Bootstrapper != nil {
+		n.Bootstrapper.Close()
+		n.Bootstrapper = nil
+	}
+	if n.Reprovider != nil {
+		n.Reprovider.Pause()
+	}
+
+	for _, c := range n.PeerHost.Network().Conns() {
+		c.Close()
+	}
+
+	return nil
+}
+
+// Resume re-establishes networking after a Suspend: the reprovider is
+// unpaused and bootstrapping is restarted with the default config.
+func (n *IpfsNode) Resume() error {
+	n.suspendLk.Lock()
+	defer n.suspendLk.Unlock()
+
+	if !n.suspended {
+		return errors.New("networking is not suspended")
+	}
+	n.suspended = false
+
+	n.PeerHost.Network().StopNotify(n.suspendNotifiee)
+	n.suspendNotifiee = nil
+
+	if n.Reprovider != nil {
+		n.Reprovider.Resume()
+	}
+
+	return n.Bootstrap(DefaultBootstrapConfig)
+}
+
+// Suspended reports whether networking is currently suspended.
+func (n *IpfsNode) Suspended() bool {
+	n.suspendLk.Lock()
+	defer n.suspendLk.Unlock()
+	return n.suspended
+}
+
+// suspendNotifiee closes every connection opened while the node is
+// suspended. It is only registered between Suspend and Resume.
+type suspendNotifiee struct{}
+
+func (nn *suspendNotifiee) Connected(_ net.Network, c net.Conn) {
+	c.Close()
+}
+
+func (nn *suspendNotifiee) Disconnected(net.Network, net.Conn)    {}
+func (nn *suspendNotifiee) OpenedStream(net.Network, net.Stream)  {}
+func (nn *suspendNotifiee) ClosedStream(net.Network, net.Stream)  {}
+func (nn *suspendNotifiee) Listen(net.Network, ma.Multiaddr)      {}
+func (nn *suspendNotifiee) ListenClose(net.Network, ma.Multiaddr) {}
@@ -0,0 +1,150 @@
+// Package dnsaddr expands /dnsaddr multiaddrs into the concrete peer
+// addresses published in their TXT records. Records may point at further
+// dnsaddrs, so lookups recurse up to a fixed depth. Results are cached
+// with a TTL, so callers that re-resolve periodically (like the
+// bootstrapper) pick up rotated records without hitting DNS every time.
+package dnsaddr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	iaddr "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-addr"
+	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+	pstore "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peerstore"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+	madns "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr-dns"
+)
+
+var log = logging.Logger("dnsaddr")
+
+const (
+	// maxRecursion bounds how many levels of dnsaddr indirection are
+	// followed before giving up.
+	maxRecursion = 4
+
+	// cacheTTL is how long resolved records are reused before they are
+	// looked up again.
+	cacheTTL = 5 * time.Minute
+)
+
+type cacheEntry struct {
+	addrs   []ma.Multiaddr
+	expires time.Time
+}
+
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]cacheEntry)
+)
+
+func isDnsaddr(addr ma.Multiaddr) bool {
+	protos := addr.Protocols()
+	return len(protos) > 0 && protos[0].Code == madns.DnsaddrProtocol.Code
+}
+
+// Resolve expands addr into the addresses its TXT records publish. Addresses
+// that are not dnsaddrs are returned unchanged. When a refresh fails, the
+// last known records are served instead so a DNS hiccup does not drop peers.
+func Resolve(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, error) {
+	if !isDnsaddr(addr) {
+		return []ma.Multiaddr{addr}, nil
+	}
+
+	key := addr.String()
+	cacheMu.Lock()
+	e, cached := cache[key]
+	cacheMu.Unlock()
+	if cached && time.Now().Before(e.expires) {
+		return e.addrs, nil
+	}
+
+	out, err := resolveRec(ctx, addr, maxRecursion)
+	if err != nil {
+		if cached {
+			log.Warningf("refreshing dnsaddr %s failed: %s; using cached records", key, err)
+			return e.addrs, nil
+		}
+		return nil, err
+	}
+
+	cacheMu.Lock()
+	cache[key] = cacheEntry{addrs: out, expires: time.Now().Add(cacheTTL)}
+	cacheMu.Unlock()
+	return out, nil
+}
+
+func resolveRec(ctx context.Context, addr ma.Multiaddr, depth int) ([]ma.Multiaddr, error) {
+	if !isDnsaddr(addr) {
+		return []ma.Multiaddr{addr}, nil
+	}
+	if depth == 0 {
+		return nil, fmt.Errorf("resolving %s: dnsaddr recursion limit reached", addr)
+	}
+
+	records, err := madns.Resolve(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []ma.Multiaddr
+	for _, rec := range records {
+		sub, err := resolveRec(ctx, rec, depth-1)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sub...)
+	}
+	return out, nil
+}
+
+// Peers expands a list of peer address strings (multiaddr + peerid, or
+// /dnsaddr) into PeerInfos, merging the addresses of each peer id. Resolved
+// dnsaddr records without a peer id are skipped with a warning; plain
+// addresses without one are an error, as before.
+func Peers(ctx context.Context, addrs []string) ([]pstore.PeerInfo, error) {
+	merged := make(map[peer.ID][]ma.Multiaddr, len(addrs))
+	var order []peer.ID
+
+	for _, s := range addrs {
+		addr, err := ma.NewMultiaddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid peer address %s: %s", s, err)
+		}
+		fromDnsaddr := isDnsaddr(addr)
+
+		resolved, err := Resolve(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range resolved {
+			ia, err := iaddr.ParseMultiaddr(a)
+			if err != nil {
+				if fromDnsaddr {
+					log.Warningf("skipping dnsaddr record %s: %s", a, err)
+					continue
+				}
+				return nil, err
+			}
+
+			id := ia.ID()
+			if _, ok := merged[id]; !ok {
+				order = append(order, id)
+				merged[id] = nil
+			}
+			if t := ia.Transport(); t != nil {
+				merged[id] = append(merged[id], t)
+			}
+		}
+	}
+
+	out := make([]pstore.PeerInfo, 0, len(order))
+	for _, id := range order {
+		out = append(out, pstore.PeerInfo{ID: id, Addrs: merged[id]})
+	}
+	return out, nil
+}
@@ -0,0 +1,100 @@
+package ethofs
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	keccak "github.com/gxed/hashland/keccakpg"
+)
+
+// wordSize is the width of an ABI-encoded word.
+const wordSize = 32
+
+// methodID returns the 4-byte selector for a solidity method signature.
+func methodID(signature string) []byte {
+	h := keccak.New256()
+	h.Write([]byte(signature))
+	return h.Sum(nil)[:4]
+}
+
+// decodeStringArray decodes an ABI-encoded dynamic string array, the
+// return type of the hosting contract's active-upload query.
+func decodeStringArray(data []byte) ([]string, error) {
+	arrOff, err := decodeOffset(data, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := decodeUint(data, arrOff)
+	if err != nil {
+		return nil, err
+	}
+
+	base := arrOff + wordSize
+	out := make([]string, 0, length)
+	for i := 0; i < length; i++ {
+		elemOff, err := decodeOffset(data, base+i*wordSize)
+		if err != nil {
+			return nil, err
+		}
+		s, err := decodeString(data, base+elemOff)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+func decodeOffset(data []byte, at int) (int, error) {
+	return decodeUint(data, at)
+}
+
+func decodeUint(data []byte, at int) (int, error) {
+	if at < 0 || at+wordSize > len(data) {
+		return 0, fmt.Errorf("truncated abi data at offset %d", at)
+	}
+	v := new(big.Int).SetBytes(data[at : at+wordSize])
+	if !v.IsInt64() || v.Int64() > int64(len(data)) {
+		return 0, fmt.Errorf("abi value out of range at offset %d", at)
+	}
+	return int(v.Int64()), nil
+}
+
+func decodeString(data []byte, at int) (string, error) {
+	length, err := decodeUint(data, at)
+	if err != nil {
+		return "", err
+	}
+	start := at + wordSize
+	if start+length > len(data) {
+		return "", fmt.Errorf("truncated abi string at offset %d", at)
+	}
+	return string(data[start : start+length]), nil
+}
+
+// encodeAddress ABI-encodes a 0x-prefixed address as a call argument.
+func encodeAddress(addr string) ([]byte, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(addr, "0x"))
+	if err != nil || len(b) != 20 {
+		return nil, fmt.Errorf("invalid address %q", addr)
+	}
+	word := make([]byte, wordSize)
+	copy(word[wordSize-len(b):], b)
+	return word, nil
+}
+
+// decodeWordUint decodes a single uint256 return word. Values beyond
+// the uint64 range are clamped to the maximum.
+func decodeWordUint(data []byte, at int) (uint64, error) {
+	if at < 0 || at+wordSize > len(data) {
+		return 0, fmt.Errorf("truncated abi data at offset %d", at)
+	}
+	v := new(big.Int).SetBytes(data[at : at+wordSize])
+	if !v.IsUint64() {
+		return ^uint64(0), nil
+	}
+	return v.Uint64(), nil
+}
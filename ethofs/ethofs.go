@@ -0,0 +1,282 @@
+// Package ethofs keeps the node's pin set in sync with the Ether-1
+// ethoFS hosting contract: content hashes with an active hosting
+// contract are pinned, and hashes whose contracts expired are unpinned
+// again. The set of contract-managed pins is tracked in the repo
+// datastore so expiries during downtime are still honored.
+package ethofs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	pin "github.com/ipsn/go-ipfs/pin"
+
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	ds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore"
+	dsquery "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore/query"
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
+	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+)
+
+var log = logging.Logger("ethofs")
+
+const (
+	// defaultSyncInterval is used when no interval is configured.
+	defaultSyncInterval = 10 * time.Minute
+
+	// activeUploadsSig is the hosting contract method returning the
+	// content hashes with active contracts.
+	activeUploadsSig = "getActiveUploadHashes()"
+
+	// managedPinPrefix is the datastore namespace recording which pins
+	// are managed by the contract sync.
+	managedPinPrefix = "/ethofs/pin"
+)
+
+// Status is a snapshot of the sync state, reported by
+// 'ipfs ethofs status'.
+type Status struct {
+	Enabled         bool
+	RPCEndpoint     string
+	ContractAddress string
+	SyncInterval    string
+
+	LastSync    string // RFC3339, empty until the first sync
+	LastError   string
+	SyncCount   int
+	ManagedPins int
+}
+
+// Service reconciles the local pin set with the hosting contract.
+type Service struct {
+	rpc      *rpcClient
+	contract string
+	interval time.Duration
+
+	pinner pin.Pinner
+	dag    ipld.DAGService
+	ds     ds.Datastore
+
+	mu        sync.Mutex
+	managed   map[string]cid.Cid
+	lastSync  time.Time
+	lastError string
+	syncCount int
+
+	// upload quota enforcement state
+	enforceQuota   bool
+	account        string
+	quotaMu        sync.Mutex
+	quotaRemaining uint64
+	quotaPending   uint64
+	quotaFetched   time.Time
+}
+
+// New creates a contract sync service from the persisted configuration.
+func New(cfg config.EthoFS, pinner pin.Pinner, dag ipld.DAGService, dstore ds.Datastore) (*Service, error) {
+	if cfg.RPCEndpoint == "" {
+		return nil, fmt.Errorf("ethofs: no RPC endpoint configured")
+	}
+	if cfg.ContractAddress == "" {
+		return nil, fmt.Errorf("ethofs: no contract address configured")
+	}
+
+	if cfg.EnforceQuota && cfg.UploaderAccount == "" {
+		return nil, fmt.Errorf("ethofs: quota enforcement requires an uploader account")
+	}
+
+	interval := defaultSyncInterval
+	if cfg.SyncInterval != "" {
+		d, err := time.ParseDuration(cfg.SyncInterval)
+		if err != nil {
+			return nil, fmt.Errorf("ethofs: invalid sync interval: %s", err)
+		}
+		interval = d
+	}
+
+	s := &Service{
+		rpc:      newRPCClient(cfg.RPCEndpoint),
+		contract: cfg.ContractAddress,
+		interval: interval,
+		pinner:   pinner,
+		dag:      dag,
+		ds:       dstore,
+		managed:  make(map[string]cid.Cid),
+
+		enforceQuota: cfg.EnforceQuota,
+		account:      cfg.UploaderAccount,
+	}
+
+	if err := s.loadManaged(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Run reconciles pins until the context is cancelled. The first sync
+// happens immediately.
+func (s *Service) Run(ctx context.Context) {
+	for {
+		if err := s.sync(ctx); err != nil {
+			log.Errorf("contract sync failed: %s", err)
+		}
+
+		select {
+		case <-time.After(s.interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Status returns a snapshot of the sync state.
+func (s *Service) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := Status{
+		Enabled:         true,
+		RPCEndpoint:     s.rpc.endpoint,
+		ContractAddress: s.contract,
+		SyncInterval:    s.interval.String(),
+		LastError:       s.lastError,
+		SyncCount:       s.syncCount,
+		ManagedPins:     len(s.managed),
+	}
+	if !s.lastSync.IsZero() {
+		st.LastSync = s.lastSync.Format(time.RFC3339)
+	}
+	return st
+}
+
+// sync fetches the active hash list and pins/unpins to match it.
+func (s *Service) sync(ctx context.Context) error {
+	hashes, err := s.activeHashes(ctx)
+
+	s.mu.Lock()
+	if err != nil {
+		s.lastError = err.Error()
+		s.mu.Unlock()
+		return err
+	}
+	s.lastError = ""
+	s.lastSync = time.Now()
+	s.syncCount++
+	s.mu.Unlock()
+
+	want := make(map[string]cid.Cid, len(hashes))
+	for _, h := range hashes {
+		c, err := cid.Decode(h)
+		if err != nil {
+			log.Warningf("contract lists invalid content hash %q: %s", h, err)
+			continue
+		}
+		want[c.String()] = c
+	}
+
+	// unpin expired contracts first to free space for new ones
+	s.mu.Lock()
+	var expired []cid.Cid
+	for key, c := range s.managed {
+		if _, ok := want[key]; !ok {
+			expired = append(expired, c)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, c := range expired {
+		if err := s.unpin(ctx, c); err != nil {
+			log.Errorf("unpinning expired %s: %s", c, err)
+		}
+	}
+
+	for key, c := range want {
+		s.mu.Lock()
+		_, have := s.managed[key]
+		s.mu.Unlock()
+		if have {
+			continue
+		}
+		if err := s.pin(ctx, c); err != nil {
+			log.Errorf("pinning %s: %s", c, err)
+		}
+	}
+
+	return s.pinner.Flush()
+}
+
+func (s *Service) activeHashes(ctx context.Context) ([]string, error) {
+	data, err := s.rpc.ethCall(ctx, s.contract, methodID(activeUploadsSig))
+	if err != nil {
+		return nil, err
+	}
+	return decodeStringArray(data)
+}
+
+func (s *Service) pin(ctx context.Context, c cid.Cid) error {
+	nd, err := s.dag.Get(ctx, c)
+	if err != nil {
+		return err
+	}
+	if err := s.pinner.Pin(ctx, nd, true); err != nil {
+		return err
+	}
+	if err := s.ds.Put(managedPinKey(c), []byte(c.String())); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.managed[c.String()] = c
+	s.mu.Unlock()
+
+	log.Infof("pinned %s for active hosting contract", c)
+	return nil
+}
+
+func (s *Service) unpin(ctx context.Context, c cid.Cid) error {
+	err := s.pinner.Unpin(ctx, c, true)
+	if err != nil && err != pin.ErrNotPinned {
+		return err
+	}
+	if err := s.ds.Delete(managedPinKey(c)); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.managed, c.String())
+	s.mu.Unlock()
+
+	log.Infof("unpinned %s, hosting contract expired", c)
+	return nil
+}
+
+// loadManaged restores the contract-managed pin set from the repo
+// datastore.
+func (s *Service) loadManaged() error {
+	res, err := s.ds.Query(dsquery.Query{Prefix: managedPinPrefix})
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	for e := range res.Next() {
+		if e.Error != nil {
+			return e.Error
+		}
+		c, err := cid.Decode(string(e.Value))
+		if err != nil {
+			log.Warningf("dropping invalid managed pin record %s", e.Key)
+			continue
+		}
+		s.managed[c.String()] = c
+	}
+	return nil
+}
+
+func managedPinKey(c cid.Cid) ds.Key {
+	return ds.NewKey(managedPinPrefix + "/" + strings.Replace(c.String(), "/", "-", -1))
+}
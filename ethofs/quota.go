@@ -0,0 +1,94 @@
+package ethofs
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// remainingStorageSig is the hosting contract method returning the
+	// unused contract-purchased storage for an uploader account.
+	remainingStorageSig = "getRemainingStorage(address)"
+
+	// quotaCacheTTL bounds how long a fetched quota is reused before
+	// the contract is queried again.
+	quotaCacheTTL = time.Minute
+)
+
+// QuotaError is returned when an upload would exceed the storage
+// remaining on the uploader's hosting contract.
+type QuotaError struct {
+	Account   string
+	Requested uint64 // zero when the upload size was unknown
+	Remaining uint64
+}
+
+func (e *QuotaError) Error() string {
+	if e.Requested == 0 {
+		return fmt.Sprintf("no contract storage remaining for account %s", e.Account)
+	}
+	return fmt.Sprintf("upload of %d bytes exceeds remaining contract storage (%d bytes) for account %s",
+		e.Requested, e.Remaining, e.Account)
+}
+
+// CheckQuota admits an upload of size bytes against the remaining
+// contract-purchased storage of the configured uploader account. A
+// zero size means the upload size is unknown; such uploads are only
+// rejected once the quota is fully exhausted. CheckQuota is a no-op
+// on a nil service or when enforcement is disabled.
+func (s *Service) CheckQuota(ctx context.Context, size uint64) error {
+	if s == nil || !s.enforceQuota {
+		return nil
+	}
+
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+
+	if time.Since(s.quotaFetched) > quotaCacheTTL {
+		remaining, err := s.remainingStorage(ctx)
+		if err != nil {
+			return fmt.Errorf("ethofs: querying remaining storage: %s", err)
+		}
+		s.quotaRemaining = remaining
+		s.quotaPending = 0
+		s.quotaFetched = time.Now()
+	}
+
+	remaining := s.quotaRemaining
+	if s.quotaPending < remaining {
+		remaining -= s.quotaPending
+	} else {
+		remaining = 0
+	}
+
+	if remaining == 0 || size > remaining {
+		return &QuotaError{Account: s.account, Requested: size, Remaining: remaining}
+	}
+	return nil
+}
+
+// ChargeQuota records size bytes as used against the cached quota, so
+// admissions between contract refreshes account for uploads already
+// accepted.
+func (s *Service) ChargeQuota(size uint64) {
+	if s == nil || !s.enforceQuota {
+		return
+	}
+
+	s.quotaMu.Lock()
+	s.quotaPending += size
+	s.quotaMu.Unlock()
+}
+
+func (s *Service) remainingStorage(ctx context.Context) (uint64, error) {
+	arg, err := encodeAddress(s.account)
+	if err != nil {
+		return 0, err
+	}
+	data, err := s.rpc.ethCall(ctx, s.contract, append(methodID(remainingStorageSig), arg...))
+	if err != nil {
+		return 0, err
+	}
+	return decodeWordUint(data, 0)
+}
@@ -0,0 +1,123 @@
+package ethofs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+	pstore "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peerstore"
+)
+
+const (
+	// defaultRegistryRefresh is used when no refresh interval is
+	// configured.
+	defaultRegistryRefresh = 30 * time.Minute
+
+	// nodeAddressesSig is the registry contract method returning the
+	// multiaddrs of registered network nodes.
+	nodeAddressesSig = "getNodeAddresses()"
+)
+
+// Registry periodically fetches the multiaddrs of registered network
+// nodes from the on-chain node registry. The fetched peers augment
+// the static bootstrap list, so new nodes do not depend on a
+// hardcoded bootstrap set.
+type Registry struct {
+	rpc      *rpcClient
+	contract string
+	interval time.Duration
+
+	mu    sync.Mutex
+	peers []pstore.PeerInfo
+}
+
+// NewRegistry creates a registry poller from the persisted
+// configuration.
+func NewRegistry(cfg config.EthoFS) (*Registry, error) {
+	if cfg.RPCEndpoint == "" {
+		return nil, fmt.Errorf("ethofs: no RPC endpoint configured")
+	}
+	if cfg.NodeRegistryContract == "" {
+		return nil, fmt.Errorf("ethofs: no node registry contract configured")
+	}
+
+	interval := defaultRegistryRefresh
+	if cfg.RegistryRefresh != "" {
+		d, err := time.ParseDuration(cfg.RegistryRefresh)
+		if err != nil {
+			return nil, fmt.Errorf("ethofs: invalid registry refresh interval: %s", err)
+		}
+		interval = d
+	}
+
+	return &Registry{
+		rpc:      newRPCClient(cfg.RPCEndpoint),
+		contract: cfg.NodeRegistryContract,
+		interval: interval,
+	}, nil
+}
+
+// Run refreshes the registry until the context is cancelled. The
+// first refresh happens immediately.
+func (r *Registry) Run(ctx context.Context) {
+	for {
+		if err := r.refresh(ctx); err != nil {
+			log.Errorf("node registry refresh failed: %s", err)
+		}
+
+		select {
+		case <-time.After(r.interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Peers returns the registered nodes from the last successful
+// refresh, or nil before the first one completes.
+func (r *Registry) Peers() []pstore.PeerInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.peers
+}
+
+func (r *Registry) refresh(ctx context.Context) error {
+	data, err := r.rpc.ethCall(ctx, r.contract, methodID(nodeAddressesSig))
+	if err != nil {
+		return err
+	}
+	addrs, err := decodeStringArray(data)
+	if err != nil {
+		return err
+	}
+
+	pinfos := make(map[peer.ID]*pstore.PeerInfo)
+	for _, addr := range addrs {
+		bp, err := config.ParseBootstrapPeer(addr)
+		if err != nil {
+			log.Warningf("registry lists invalid node address %q: %s", addr, err)
+			continue
+		}
+		pinfo, ok := pinfos[bp.ID()]
+		if !ok {
+			pinfo = &pstore.PeerInfo{ID: bp.ID()}
+			pinfos[bp.ID()] = pinfo
+		}
+		pinfo.Addrs = append(pinfo.Addrs, bp.Transport())
+	}
+
+	peers := make([]pstore.PeerInfo, 0, len(pinfos))
+	for _, pinfo := range pinfos {
+		peers = append(peers, *pinfo)
+	}
+
+	r.mu.Lock()
+	r.peers = peers
+	r.mu.Unlock()
+
+	log.Debugf("node registry refreshed, %d peers", len(peers))
+	return nil
+}
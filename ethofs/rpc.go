@@ -0,0 +1,106 @@
+package ethofs
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// rpcTimeout bounds a single JSON-RPC request.
+const rpcTimeout = 30 * time.Second
+
+// rpcClient is a minimal Ethereum JSON-RPC client, just enough to read
+// the hosting contract.
+type rpcClient struct {
+	endpoint string
+	hc       *http.Client
+}
+
+func newRPCClient(endpoint string) *rpcClient {
+	return &rpcClient{
+		endpoint: endpoint,
+		hc:       &http.Client{Timeout: rpcTimeout},
+	}
+}
+
+type rpcRequest struct {
+	Version string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      int           `json:"id"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+func (c *rpcClient) call(ctx context.Context, method string, params ...interface{}) (json.RawMessage, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(rpcRequest{
+		Version: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var out rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decoding rpc response: %s", err)
+	}
+	if out.Error != nil {
+		return nil, out.Error
+	}
+	return out.Result, nil
+}
+
+// ethCall performs a read-only contract call and returns the decoded
+// return data.
+func (c *rpcClient) ethCall(ctx context.Context, to string, data []byte) ([]byte, error) {
+	arg := map[string]string{
+		"to":   to,
+		"data": "0x" + hex.EncodeToString(data),
+	}
+
+	raw, err := c.call(ctx, "eth_call", arg, "latest")
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err != nil {
+		return nil, fmt.Errorf("unexpected eth_call result: %s", err)
+	}
+	return hex.DecodeString(strings.TrimPrefix(encoded, "0x"))
+}
@@ -0,0 +1,77 @@
+package reprovide
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	backoff "github.com/cenkalti/backoff"
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	routing "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-routing"
+)
+
+// BatchProvider collects root cids of newly added content and announces
+// them to the routing system in periodic batches, instead of providing
+// every block the moment it is written. It is used when the per-block
+// announcements of the exchange are disabled for bulk ingest.
+type BatchProvider struct {
+	ctx  context.Context
+	rsys routing.ContentRouting
+
+	lk      sync.Mutex
+	pending *cid.Set
+}
+
+// NewBatchProvider creates a new BatchProvider announcing through the
+// given routing system.
+func NewBatchProvider(ctx context.Context, rsys routing.ContentRouting) *BatchProvider {
+	return &BatchProvider{
+		ctx:     ctx,
+		rsys:    rsys,
+		pending: cid.NewSet(),
+	}
+}
+
+// Enqueue schedules the given cid for the next announcement batch.
+// Enqueueing the same cid twice before the batch runs is a no-op.
+func (bp *BatchProvider) Enqueue(c cid.Cid) {
+	bp.lk.Lock()
+	bp.pending.Add(c)
+	bp.lk.Unlock()
+}
+
+// Run announces the pending keys with 'tick' interval until the context
+// is cancelled.
+func (bp *BatchProvider) Run(tick time.Duration) {
+	for {
+		select {
+		case <-bp.ctx.Done():
+			return
+		case <-time.After(tick):
+		}
+		bp.providePending()
+	}
+}
+
+func (bp *BatchProvider) providePending() {
+	bp.lk.Lock()
+	keys := bp.pending.Keys()
+	bp.pending = cid.NewSet()
+	bp.lk.Unlock()
+
+	for _, c := range keys {
+		op := func() error {
+			err := bp.rsys.Provide(bp.ctx, c, true)
+			if err != nil {
+				log.Debugf("Failed to provide key: %s", err)
+			}
+			return err
+		}
+
+		err := backoff.Retry(op, backoff.NewExponentialBackOff())
+		if err != nil {
+			log.Debugf("Providing failed after number of retries: %s", err)
+			return
+		}
+	}
+}
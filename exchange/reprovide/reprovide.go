@@ -3,6 +3,7 @@ package reprovide
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	backoff "github.com/cenkalti/backoff"
@@ -10,10 +11,39 @@ import (
 	routing "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-routing"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-verifcid"
 	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	prometheus "github.com/prometheus/client_golang/prometheus"
 )
 
 var log = logging.Logger("reprovider")
 
+var (
+	reprovideRunsMetric = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ipfs",
+		Subsystem: "reprovider",
+		Name:      "runs_total",
+		Help:      "Total number of reprovide runs.",
+	})
+
+	reprovideKeysMetric = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "ipfs",
+		Subsystem: "reprovider",
+		Name:      "keys_provided_total",
+		Help:      "Total number of keys announced by the reprovider.",
+	})
+
+	reprovideDurationMetric = prometheus.NewSummary(prometheus.SummaryOpts{
+		Namespace:  "ipfs",
+		Subsystem:  "reprovider",
+		Name:       "duration_seconds",
+		Help:       "Reprovide run durations in seconds.",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	})
+)
+
+func init() {
+	prometheus.MustRegister(reprovideRunsMetric, reprovideKeysMetric, reprovideDurationMetric)
+}
+
 //KeyChanFunc is function streaming CIDs to pass to content routing
 type KeyChanFunc func(context.Context) (<-chan cid.Cid, error)
 type doneFunc func(error)
@@ -21,6 +51,7 @@ type doneFunc func(error)
 type Reprovider struct {
 	ctx     context.Context
 	trigger chan doneFunc
+	paused  int32
 
 	// The routing system to provide values through
 	rsys routing.ContentRouting
@@ -77,8 +108,27 @@ func (rp *Reprovider) Run(tick time.Duration) {
 	}
 }
 
+// Pause makes reprovide rounds no-ops until Resume is called. Used when
+// networking is suspended at runtime.
+func (rp *Reprovider) Pause() {
+	atomic.StoreInt32(&rp.paused, 1)
+}
+
+// Resume re-enables reprovide rounds after a Pause.
+func (rp *Reprovider) Resume() {
+	atomic.StoreInt32(&rp.paused, 0)
+}
+
 // Reprovide registers all keys given by rp.keyProvider to libp2p content routing
 func (rp *Reprovider) Reprovide() error {
+	if atomic.LoadInt32(&rp.paused) != 0 {
+		return nil
+	}
+	reprovideRunsMetric.Inc()
+	defer func(start time.Time) {
+		reprovideDurationMetric.Observe(time.Since(start).Seconds())
+	}(time.Now())
+
 	keychan, err := rp.keyProvider(rp.ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get key chan: %s", err)
@@ -104,6 +154,8 @@ func (rp *Reprovider) Reprovide() error {
 			log.Debugf("Providing failed after number of retries: %s", err)
 			return err
 		}
+
+		reprovideKeysMetric.Inc()
 	}
 	return nil
 }
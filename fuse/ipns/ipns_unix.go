@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	core "github.com/ipsn/go-ipfs/core"
 	namesys "github.com/ipsn/go-ipfs/namesys"
@@ -89,6 +90,21 @@ func ipnsPubFunc(ipfs *core.IpfsNode, k ci.PrivKey) mfs.PubFunc {
 	}
 }
 
+// republishDebounce reads Mounts.IpnsRepublishDebounce from the config.
+// Zero means "use the mfs default".
+func republishDebounce(ipfs *core.IpfsNode) time.Duration {
+	cfg, err := ipfs.Repo.Config()
+	if err != nil || cfg.Mounts.IpnsRepublishDebounce == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(cfg.Mounts.IpnsRepublishDebounce)
+	if err != nil || d <= 0 {
+		log.Errorf("ignoring invalid Mounts.IpnsRepublishDebounce: %q", cfg.Mounts.IpnsRepublishDebounce)
+		return 0
+	}
+	return d
+}
+
 func loadRoot(ctx context.Context, rt *keyRoot, ipfs *core.IpfsNode, name string) (fs.Node, error) {
 	p, err := path.ParsePath("/ipns/" + name)
 	if err != nil {
@@ -116,6 +132,16 @@ func loadRoot(ctx context.Context, rt *keyRoot, ipfs *core.IpfsNode, name string
 		return nil, err
 	}
 
+	// writes republish the record once things settle; the debounce can
+	// be raised to batch bursts of writes into a single publish
+	if debounce := republishDebounce(ipfs); debounce > 0 {
+		long := 3 * time.Second
+		if long < debounce {
+			long = debounce
+		}
+		root.SetRepublishTimeouts(debounce, long)
+	}
+
 	rt.root = root
 
 	return &Directory{dir: root.GetDirectory()}, nil
@@ -24,6 +24,7 @@ import (
 	flags "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-flags"
 	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
 	metrics "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-metrics-interface"
+	opentracing "github.com/opentracing/opentracing-go"
 	process "github.com/ipsn/go-ipfs/gxlibs/github.com/jbenet/goprocess"
 	procctx "github.com/ipsn/go-ipfs/gxlibs/github.com/jbenet/goprocess/context"
 	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
@@ -50,6 +51,11 @@ var (
 	provideKeysBufferSize = 2048
 	provideWorkerMax      = 512
 
+	// ProvideEnabled toggles announcing newly received blocks to the
+	// content routing system. Set it to false when providing is handled
+	// elsewhere (e.g. batched root announcements).
+	ProvideEnabled = true
+
 	// the 1<<18+15 is to observe old file chunks that are 1<<18 + 14 in size
 	metricsBuckets = []float64{1 << 6, 1 << 10, 1 << 14, 1 << 18, 1<<18 + 15, 1 << 22}
 )
@@ -159,6 +165,10 @@ type Bitswap struct {
 	counterLk sync.Mutex
 	counters  *counters
 
+	// blockSentListener, if set, is notified of every block sent to a
+	// peer. Guarded by counterLk.
+	blockSentListener func(p peer.ID, blk blocks.Block)
+
 	// Metrics interface metrics
 	dupMetric metrics.Histogram
 	allMetric metrics.Histogram
@@ -185,6 +195,10 @@ type blockRequest struct {
 // GetBlock attempts to retrieve a particular block from peers within the
 // deadline enforced by the context.
 func (bs *Bitswap) GetBlock(parent context.Context, k cid.Cid) (blocks.Block, error) {
+	span, parent := opentracing.StartSpanFromContext(parent, "Bitswap.GetBlock")
+	span.SetTag("cid", k.String())
+	defer span.Finish()
+
 	return getBlock(parent, k, bs.GetBlocks)
 }
 
@@ -337,6 +351,10 @@ func (bs *Bitswap) receiveBlockFrom(blk blocks.Block, from peer.ID) error {
 
 	bs.engine.AddBlock(blk)
 
+	if !ProvideEnabled {
+		return nil
+	}
+
 	select {
 	case bs.newBlocks <- blk.Cid():
 		// send block off to be reprovided
@@ -459,3 +477,11 @@ func (bs *Bitswap) GetWantlist() []cid.Cid {
 func (bs *Bitswap) IsOnline() bool {
 	return true
 }
+
+// SetBlockSentListener registers a callback invoked for every block
+// sent to a peer, used for serve accounting.
+func (bs *Bitswap) SetBlockSentListener(f func(p peer.ID, blk blocks.Block)) {
+	bs.counterLk.Lock()
+	bs.blockSentListener = f
+	bs.counterLk.Unlock()
+}
@@ -79,6 +79,9 @@ func (bs *Bitswap) taskWorker(ctx context.Context, id int) {
 				for _, block := range envelope.Message.Blocks() {
 					bs.counters.blocksSent++
 					bs.counters.dataSent += uint64(len(block.RawData()))
+					if bs.blockSentListener != nil {
+						bs.blockSentListener(envelope.Peer, block)
+					}
 				}
 				bs.counterLk.Unlock()
 			case <-ctx.Done():
@@ -1,10 +1,13 @@
 package fslock
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path"
+	"runtime"
 	"strings"
 	"syscall"
 
@@ -20,9 +23,93 @@ func errPerm(path string) error {
 	return fmt.Errorf("failed to take lock at %s: permission denied", path)
 }
 
-// Lock creates the lock.
+// lockInfo records which process took the lock, so a lock left behind
+// by a crashed process can be recognized as stale.
+type lockInfo struct {
+	PID  int
+	Host string
+}
+
+func infoPath(confdir, lockFile string) string {
+	return path.Join(confdir, lockFile+".info")
+}
+
+// Lock creates the lock and records the owning PID and hostname next
+// to it.
 func Lock(confdir, lockFile string) (io.Closer, error) {
-	return lock.Lock(path.Join(confdir, lockFile))
+	lk, err := lock.Lock(path.Join(confdir, lockFile))
+	if err != nil {
+		return nil, err
+	}
+	writeLockInfo(confdir, lockFile)
+	return &infoClearingLock{Closer: lk, infoPath: infoPath(confdir, lockFile)}, nil
+}
+
+// writeLockInfo is best effort; a missing info file only disables
+// stale lock detection.
+func writeLockInfo(confdir, lockFile string) {
+	host, _ := os.Hostname()
+	data, err := json.Marshal(&lockInfo{PID: os.Getpid(), Host: host})
+	if err != nil {
+		return
+	}
+	if err := ioutil.WriteFile(infoPath(confdir, lockFile), data, 0644); err != nil {
+		log.Debugf("failed to write lock info: %s", err)
+	}
+}
+
+type infoClearingLock struct {
+	io.Closer
+	infoPath string
+}
+
+func (l *infoClearingLock) Close() error {
+	os.Remove(l.infoPath)
+	return l.Closer.Close()
+}
+
+// Stale reports whether the lock is held by a process on this host
+// that no longer exists. Without an ownership record it errs on the
+// safe side and reports false.
+func Stale(confdir, lockFile string) bool {
+	data, err := ioutil.ReadFile(infoPath(confdir, lockFile))
+	if err != nil {
+		return false
+	}
+
+	var info lockInfo
+	if json.Unmarshal(data, &info) != nil || info.PID == 0 {
+		return false
+	}
+	if host, _ := os.Hostname(); info.Host != "" && info.Host != host {
+		// possibly a shared filesystem, never take over
+		return false
+	}
+	if info.PID == os.Getpid() {
+		return false
+	}
+
+	proc, err := os.FindProcess(info.PID)
+	if err != nil {
+		return true
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess only succeeds for live processes on windows
+		return false
+	}
+	// on unix FindProcess always succeeds; probe with signal 0
+	return proc.Signal(syscall.Signal(0)) != nil
+}
+
+// Clear removes the lock and its ownership record.
+func Clear(confdir, lockFile string) error {
+	if err := os.Remove(infoPath(confdir, lockFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(path.Join(confdir, lockFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
 // Locked checks if there is a lock already set.
@@ -104,10 +104,25 @@ func NewBlockstore(d ds.Batching) Blockstore {
 	}
 }
 
+// NewWriteThrough returns a Blockstore that writes blocks without
+// checking the datastore for them first, trading possible duplicate
+// writes for one less lookup per Put. Useful when writes are known to
+// be mostly new data.
+func NewWriteThrough(d ds.Batching) Blockstore {
+	var dsb ds.Batching
+	dd := dsns.Wrap(d, BlockPrefix)
+	dsb = dd
+	return &blockstore{
+		datastore:    dsb,
+		writeThrough: true,
+	}
+}
+
 type blockstore struct {
 	datastore ds.Batching
 
-	rehash bool
+	rehash       bool
+	writeThrough bool
 }
 
 func (bs *blockstore) HashOnRead(enabled bool) {
@@ -145,10 +160,12 @@ func (bs *blockstore) Get(k cid.Cid) (blocks.Block, error) {
 func (bs *blockstore) Put(block blocks.Block) error {
 	k := dshelp.CidToDsKey(block.Cid())
 
-	// Has is cheaper than Put, so see if we already have it
-	exists, err := bs.datastore.Has(k)
-	if err == nil && exists {
-		return nil // already stored.
+	if !bs.writeThrough {
+		// Has is cheaper than Put, so see if we already have it
+		exists, err := bs.datastore.Has(k)
+		if err == nil && exists {
+			return nil // already stored.
+		}
 	}
 	return bs.datastore.Put(k, block.RawData())
 }
@@ -160,9 +177,11 @@ func (bs *blockstore) PutMany(blocks []blocks.Block) error {
 	}
 	for _, b := range blocks {
 		k := dshelp.CidToDsKey(b.Cid())
-		exists, err := bs.datastore.Has(k)
-		if err == nil && exists {
-			continue
+		if !bs.writeThrough {
+			exists, err := bs.datastore.Has(k)
+			if err == nil && exists {
+				continue
+			}
 		}
 
 		err = t.Put(k, b.RawData())
@@ -0,0 +1,116 @@
+package blockstore
+
+import (
+	"context"
+
+	lru "github.com/hashicorp/golang-lru"
+	blocks "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-block-format"
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+)
+
+// fetchcache keeps written blocks in a fixed-size in-memory LRU instead
+// of the underlying store, unless the store already holds them. It is
+// meant to back the write side of an exchange on nodes that only serve
+// fetched content ephemerally (caching gateways): locally added blocks
+// stay persistent, everything arriving over the network is evicted with
+// the cache.
+type fetchcache struct {
+	cache      *lru.Cache
+	blockstore Blockstore
+}
+
+// NewFetchCache wraps the given blockstore so writes of blocks it does
+// not already hold land in an in-memory LRU of at most size blocks.
+// Reads consult the cache first, then the wrapped store.
+func NewFetchCache(bs Blockstore, size int) (Blockstore, error) {
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &fetchcache{cache: cache, blockstore: bs}, nil
+}
+
+func (f *fetchcache) DeleteBlock(k cid.Cid) error {
+	if f.cache.Contains(k.KeyString()) {
+		f.cache.Remove(k.KeyString())
+		return nil
+	}
+	return f.blockstore.DeleteBlock(k)
+}
+
+func (f *fetchcache) Has(k cid.Cid) (bool, error) {
+	if f.cache.Contains(k.KeyString()) {
+		return true, nil
+	}
+	return f.blockstore.Has(k)
+}
+
+func (f *fetchcache) Get(k cid.Cid) (blocks.Block, error) {
+	if v, ok := f.cache.Get(k.KeyString()); ok {
+		return v.(blocks.Block), nil
+	}
+	return f.blockstore.Get(k)
+}
+
+func (f *fetchcache) GetSize(k cid.Cid) (int, error) {
+	if v, ok := f.cache.Get(k.KeyString()); ok {
+		return len(v.(blocks.Block).RawData()), nil
+	}
+	return f.blockstore.GetSize(k)
+}
+
+func (f *fetchcache) Put(b blocks.Block) error {
+	exists, err := f.blockstore.Has(b.Cid())
+	if err == nil && exists {
+		return nil // already persistent, nothing to cache
+	}
+	f.cache.Add(b.Cid().KeyString(), b)
+	return nil
+}
+
+func (f *fetchcache) PutMany(bs []blocks.Block) error {
+	for _, b := range bs {
+		if err := f.Put(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllKeysChan only lists the persistent blocks: cached blocks come and
+// go with the LRU and should not be announced to the network.
+func (f *fetchcache) AllKeysChan(ctx context.Context) (<-chan cid.Cid, error) {
+	return f.blockstore.AllKeysChan(ctx)
+}
+
+func (f *fetchcache) HashOnRead(enabled bool) {
+	f.blockstore.HashOnRead(enabled)
+}
+
+// fetchcacheReadView routes reads through a fetch cache and everything
+// else to the persistent store.
+type fetchcacheReadView struct {
+	Blockstore // the persistent store: writes, deletes, listing
+	cache      Blockstore
+}
+
+// FetchCacheReadView gives the local read path sight of a fetch cache
+// without routing writes into it: Get, GetSize and Has consult the
+// cache (which itself falls back to the persistent store), while
+// writes and deletes go straight to the persistent store, so locally
+// added blocks stay persistent.
+func FetchCacheReadView(cache, persistent Blockstore) Blockstore {
+	return &fetchcacheReadView{Blockstore: persistent, cache: cache}
+}
+
+func (r *fetchcacheReadView) Has(k cid.Cid) (bool, error) {
+	return r.cache.Has(k)
+}
+
+func (r *fetchcacheReadView) Get(k cid.Cid) (blocks.Block, error) {
+	return r.cache.Get(k)
+}
+
+func (r *fetchcacheReadView) GetSize(k cid.Cid) (int, error) {
+	return r.cache.GetSize(k)
+}
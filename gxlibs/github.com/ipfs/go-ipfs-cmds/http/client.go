@@ -54,8 +54,16 @@ func ClientWithAPIPrefix(apiPrefix string) ClientOpt {
 	}
 }
 
+// ClientWithHTTPClient sets the underlying http client used to talk to
+// the server, e.g. one configured for TLS.
+func ClientWithHTTPClient(hc *http.Client) ClientOpt {
+	return func(c *client) {
+		c.httpClient = hc
+	}
+}
+
 func NewClient(address string, opts ...ClientOpt) Client {
-	if !strings.HasPrefix(address, "http://") {
+	if !strings.HasPrefix(address, "http://") && !strings.HasPrefix(address, "https://") {
 		address = "http://" + address
 	}
 
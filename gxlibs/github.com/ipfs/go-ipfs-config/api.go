@@ -1,5 +1,38 @@
 package config
 
 type API struct {
-	HTTPHeaders map[string][]string // HTTP headers to return with the API.
+	HTTPHeaders  map[string][]string // HTTP headers to return with the API.
+	TLS          APITLS              // serve the API over HTTPS
+	CORSPolicies []APICORSPolicy     // per-route CORS policies overriding HTTPHeaders
+	Listeners    []APIListener       // extra API listeners with per-listener restrictions
+}
+
+// APIListener is an additional commands API listener served next to
+// the Addresses.API ones, restricted to a scope and optionally
+// requiring token authentication regardless of APIAuth.Enabled. It
+// lets an unrestricted localhost socket coexist with, say, a
+// read-only TCP listener exposed to a wider network.
+type APIListener struct {
+	Address     string // multiaddr to listen on
+	Scope       string // widest scope served; one of the APIScope constants, "" means admin
+	RequireAuth bool   // reject requests without a valid APIAuth token
+}
+
+// APICORSPolicy scopes CORS headers to a route prefix, so browser
+// origins can be granted access to some API routes without exposing
+// the whole command surface. The longest matching PathPrefix wins.
+type APICORSPolicy struct {
+	PathPrefix       string   // absolute path prefix, e.g. "/api/v0/name/resolve"
+	AllowOrigins     []string // allowed Origin values, "*" matches any
+	AllowMethods     []string // defaults to GET, POST, PUT
+	AllowCredentials bool
+}
+
+// APITLS configures HTTPS on the commands API. When Enabled and no
+// certificate files are given, a self-signed certificate is generated
+// under the repo and its fingerprint is reported by 'ipfs id'.
+type APITLS struct {
+	Enabled  bool
+	CertFile string // PEM certificate; repo-generated if empty
+	KeyFile  string // PEM private key; repo-generated if empty
 }
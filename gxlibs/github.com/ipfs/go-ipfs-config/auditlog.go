@@ -0,0 +1,11 @@
+package config
+
+// AuditLog configures the optional API audit log. Every command
+// executed against the daemon is recorded with its caller, arguments,
+// status and duration.
+type AuditLog struct {
+	Enabled    bool
+	File       string // write entries to this file instead of stderr
+	MaxSizeMB  int    // rotate File once it exceeds this size, 0 disables rotation
+	MaxBackups int    // number of rotated files to keep
+}
@@ -0,0 +1,24 @@
+package config
+
+// Scopes understood by the API auth middleware.
+const (
+	APIScopeReadOnly = "read-only"
+	APIScopePinOnly  = "pin-only"
+	APIScopeAdmin    = "admin"
+)
+
+// APIToken is a single authorized API credential. Only a hash of the
+// token is stored; the secret itself is shown once on creation.
+type APIToken struct {
+	Name      string // human-readable label, unique within the config
+	TokenHash string // hex-encoded sha256 of the bearer token
+	Scope     string // one of the APIScope constants
+}
+
+// APIAuth configures bearer-token authentication on the commands API.
+// When enabled, requests without a token matching one of Tokens are
+// rejected.
+type APIAuth struct {
+	Enabled bool
+	Tokens  []APIToken
+}
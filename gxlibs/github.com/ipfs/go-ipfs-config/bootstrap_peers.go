@@ -18,14 +18,18 @@ import (
 // import dependency issue. TODO: move this into a config/default/ package.
 var DefaultBootstrapAddresses = []string{
 	"/dns4/wss1.ethofs.com/tcp/4001/ipfs/QmTFUcUuMSN7KLytjtqnHCjixqd4ig3PrSbdQ2mW9Q8qeY",
-        "/dns4/wss.ethofs.com/tcp/4001/ipfs/QmV856mLWnTDaj5LQvS3dCa3qjz4DNC9cKQJNSrwtqcHzT",
-        "/dns4/wss2.ethofs.com/tcp/4001/ipfs/QmdEs4acs8eiAgYr7TFaF43k1BMtGZdF5HkSz9CH4YTVnL",
-        "/dns4/wss3.ethofs.com/tcp/4001/ipfs/QmSfEKCzPWA6MmG2ZLK4Vqnq6oB6rvrLyUpHdNqng5nQ4t",
-        "/dns4/wss4.ethofs.com/tcp/4001/ipfs/QmeG81bELkgLBZFYZc53ioxtvRS8iNVzPqxUBKSuah2rcQ",
-        "/dns4/wss5.ethofs.com/tcp/4001/ipfs/QmRwQ49Zknc2dQbywrhT8ArMDS9JdmnEyGGy4mZ1wDkgaX",
-        "/dns4/wss6.ethofs.com/tcp/4001/ipfs/QmTZsBNb7dfJJmwuAdXBjKZ7ZH6XbpestZdURWGJVyAmj2"
+	"/dns4/wss.ethofs.com/tcp/4001/ipfs/QmV856mLWnTDaj5LQvS3dCa3qjz4DNC9cKQJNSrwtqcHzT",
+	"/dns4/wss2.ethofs.com/tcp/4001/ipfs/QmdEs4acs8eiAgYr7TFaF43k1BMtGZdF5HkSz9CH4YTVnL",
+	"/dns4/wss3.ethofs.com/tcp/4001/ipfs/QmSfEKCzPWA6MmG2ZLK4Vqnq6oB6rvrLyUpHdNqng5nQ4t",
+	"/dns4/wss4.ethofs.com/tcp/4001/ipfs/QmeG81bELkgLBZFYZc53ioxtvRS8iNVzPqxUBKSuah2rcQ",
+	"/dns4/wss5.ethofs.com/tcp/4001/ipfs/QmRwQ49Zknc2dQbywrhT8ArMDS9JdmnEyGGy4mZ1wDkgaX",
+	"/dns4/wss6.ethofs.com/tcp/4001/ipfs/QmTZsBNb7dfJJmwuAdXBjKZ7ZH6XbpestZdURWGJVyAmj2",
 }
 
+// EthofsBootstrapAddresses are the bootstrap nodes operated by ethoFS.
+// They are currently also the default set.
+var EthofsBootstrapAddresses = DefaultBootstrapAddresses
+
 // BootstrapPeer is a peer used to bootstrap the network.
 type BootstrapPeer iaddr.IPFSAddr
 
@@ -22,13 +22,30 @@ type Config struct {
 	Routing   Routing   // local node's routing settings
 	Ipns      Ipns      // Ipns settings
 	Bootstrap []string  // local nodes's bootstrap peer addresses
-	Gateway   Gateway   // local node's gateway server options
-	API       API       // local node's API settings
-	Swarm     SwarmConfig
-	Pubsub    PubsubConfig
+	Peering   Peering   // peers to keep permanent connections with
+
+	// BootstrapSets holds named alternate bootstrap lists that can be
+	// activated with 'ipfs bootstrap set use'
+	BootstrapSets map[string][]string `json:",omitempty"`
+
+	Gateway Gateway // local node's gateway server options
+	API     API     // local node's API settings
+	Swarm   SwarmConfig
+	Pubsub  PubsubConfig
 
 	Reprovider   Reprovider
 	Experimental Experiments
+	P2PProxy     P2PProxy  // experimental p2p HTTP proxy options
+	P2P          P2PConfig // persisted p2p forwards and listeners
+	Tracing      Tracing   // trace export options
+	Logging      Logging   // log output options
+	AuditLog     AuditLog  // API command audit log options
+	APIAuth      APIAuth   // API bearer-token authentication options
+	Identify     Identify  // identify service metadata
+	Onion        Onion     // Tor onion transport options
+	Keystore     Keystore  // IPNS key storage options
+	Plugins      Plugins   // external plugin loading options
+	EthoFS       EthoFS    // hosting contract pin synchronization options
 }
 
 const (
@@ -23,6 +23,17 @@ type Datastore struct {
 
 	HashOnRead      bool
 	BloomFilterSize int
+
+	// WriteThrough skips the existence check before writing blocks,
+	// trading possible duplicate writes for one less datastore lookup
+	// per block. Useful for archive nodes ingesting mostly-new data.
+	WriteThrough bool `json:",omitempty"`
+
+	// FetchCacheSize, when set, keeps blocks fetched from the network
+	// in a fixed-size in-memory LRU (of that many blocks) instead of
+	// persisting them. Locally added blocks are stored as usual; use
+	// this for gateway-style ephemeral serving.
+	FetchCacheSize int `json:",omitempty"`
 }
 
 // DataStorePath returns the default data store path given a configuration root
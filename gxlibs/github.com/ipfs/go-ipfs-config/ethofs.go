@@ -0,0 +1,23 @@
+package config
+
+// EthoFS configures synchronization of pins with the Ether-1 ethoFS
+// hosting contract. When enabled, content hashes with active hosting
+// contracts are pinned and hashes whose contracts expired are unpinned.
+type EthoFS struct {
+	Enabled         bool
+	RPCEndpoint     string // Ether-1 JSON-RPC endpoint
+	ContractAddress string // hosting contract address (0x...)
+	SyncInterval    string // how often pins are reconciled, e.g. "10m"
+
+	// NodeRegistryContract optionally names an on-chain registry of
+	// network nodes used to augment the static bootstrap list. It is
+	// honored even when pin synchronization is disabled.
+	NodeRegistryContract string // node registry contract address (0x...)
+	RegistryRefresh      string // how often the registry is refreshed, e.g. "30m"
+
+	// EnforceQuota rejects uploads through the writable paths (add,
+	// writable gateway, dag put) that exceed the storage remaining on
+	// the uploader account's hosting contract.
+	EnforceQuota    bool
+	UploaderAccount string // Ether-1 account whose purchased storage backs uploads
+}
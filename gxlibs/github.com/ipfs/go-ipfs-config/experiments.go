@@ -7,4 +7,10 @@ type Experiments struct {
 	Libp2pStreamMounting bool
 	P2pHttpProxy         bool
 	QUIC                 bool
+
+	// IpnsPubsub distributes IPNS records over per-name pubsub topics in
+	// addition to the DHT, so record updates propagate in seconds. The
+	// same subsystem can also be enabled with the daemon's
+	// --enable-namesys-pubsub flag.
+	IpnsPubsub bool
 }
@@ -6,5 +6,9 @@ type Gateway struct {
 	RootRedirect string
 	Writable     bool
 	PathPrefixes []string
-	APICommands  []string
+
+	// APICommands restricts the read-only API served on the gateway
+	// port to the listed command paths (e.g. "cat", "name/resolve").
+	// An empty list serves the whole read-only command set.
+	APICommands []string
 }
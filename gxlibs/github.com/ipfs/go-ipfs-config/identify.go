@@ -0,0 +1,14 @@
+package config
+
+// Identify configures the metadata the identify service advertises to
+// connected peers.
+type Identify struct {
+	// AgentVersion overrides the agent string sent to peers. An empty
+	// value keeps the default go-ipfs version string.
+	AgentVersion string
+
+	// ExtraProtocols are advertised in the identify protocol list on
+	// top of the handlers the node actually registers. Useful as a
+	// network tag, e.g. "/ethofs/1.0.0".
+	ExtraProtocols []string
+}
@@ -17,6 +17,12 @@ func Init(out io.Writer, nBitsForKeypair int) (*Config, error) {
 		return nil, err
 	}
 
+	return InitWithIdentity(identity)
+}
+
+// InitWithIdentity builds the default config around an already
+// generated identity.
+func InitWithIdentity(identity Identity) (*Config, error) {
 	bootstrapPeers, err := DefaultBootstrapPeers()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,16 @@
+package config
+
+// Keystore configures where and how IPNS keys are stored.
+type Keystore struct {
+	// Type selects the backend: "fs" (plaintext key files, the
+	// default) or "encrypted-fs" (password protected key files).
+	Type string
+
+	// Path overrides the default "keystore" directory inside the
+	// repo. Relative paths are resolved against the repo root.
+	Path string
+
+	// PasswordFile is read for the encrypted-fs password when the
+	// IPFS_KEYSTORE_PASSWORD environment variable is not set.
+	PasswordFile string
+}
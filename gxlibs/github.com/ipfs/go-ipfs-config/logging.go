@@ -0,0 +1,13 @@
+package config
+
+// Logging configures daemon log output. The zero value leaves the
+// environment-driven go-log defaults untouched.
+type Logging struct {
+	Format     string // "color", "nocolor" or "json"
+	File       string // log to this file instead of stderr
+	MaxSizeMB  int    // rotate File once it exceeds this size, 0 disables rotation
+	MaxBackups int    // number of rotated files to keep
+
+	Level  string            // default level applied to all subsystems
+	Levels map[string]string // per-subsystem log levels
+}
@@ -5,4 +5,9 @@ type Mounts struct {
 	IPFS           string
 	IPNS           string
 	FuseAllowOther bool
+
+	// IpnsRepublishDebounce is how long the writable /ipns mount waits
+	// after the last write before republishing the IPNS record, as a
+	// duration string (e.g. "5s"). Empty uses the default (300ms).
+	IpnsRepublishDebounce string `json:",omitempty"`
 }
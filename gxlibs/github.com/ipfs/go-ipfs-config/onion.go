@@ -0,0 +1,35 @@
+package config
+
+// Onion configures the Tor onion transport.
+type Onion struct {
+	// Enabled turns the transport on. It needs a running tor daemon
+	// on this machine.
+	Enabled bool
+
+	// SocksAddress is tor's socks endpoint used for outbound onion
+	// dials, e.g. "127.0.0.1:9050".
+	SocksAddress string
+
+	// ControlAddress is tor's control endpoint used to publish hidden
+	// service listeners, e.g. "127.0.0.1:9051". Empty makes the
+	// transport dial-only.
+	ControlAddress string
+
+	// ControlPassword authenticates to the control endpoint; empty
+	// uses null authentication.
+	ControlPassword string
+
+	// ListenPort, when non-zero, publishes a hidden service on this
+	// virtual port at startup.
+	ListenPort int
+
+	// ServiceKey is the hidden service's private key blob as handed
+	// out by tor ("ED25519-V3:..."). It is filled in automatically
+	// the first time a service is published, keeping the onion
+	// address stable across restarts.
+	ServiceKey string
+
+	// OnlyOnion suppresses advertisement of all non-onion addresses,
+	// so peers never learn the node's IP from identify.
+	OnlyOnion bool
+}
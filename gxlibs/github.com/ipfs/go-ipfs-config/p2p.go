@@ -0,0 +1,27 @@
+package config
+
+// P2PForward describes a persisted 'ipfs p2p forward'.
+type P2PForward struct {
+	Protocol      string
+	ListenAddress string
+	TargetAddress string
+	UDP           bool
+	Keepalive     bool
+}
+
+// P2PListener describes a persisted 'ipfs p2p listen'.
+type P2PListener struct {
+	Protocol      string
+	TargetAddress string
+	ReportPeerID  bool
+	AllowPeers    []string
+	DenyPeers     []string
+	Token         string
+}
+
+// P2PConfig contains persisted p2p tunnel configuration, re-established
+// when the daemon starts.
+type P2PConfig struct {
+	Forwards  []P2PForward
+	Listeners []P2PListener
+}
@@ -0,0 +1,8 @@
+package config
+
+// Peering configures the peering service, which keeps connections to the
+// listed peers alive, reconnecting with backoff when they drop.
+type Peering struct {
+	// Peers to stay connected to, as multiaddrs ending in /ipfs/<peer-id>.
+	Peers []string
+}
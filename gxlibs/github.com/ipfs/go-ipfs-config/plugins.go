@@ -0,0 +1,14 @@
+package config
+
+// Plugins configures loading of dynamically loaded (external) plugins.
+type Plugins struct {
+	// RequireSignatures refuses to load external plugins that are not
+	// signed by one of the trusted publishers.
+	RequireSignatures bool
+
+	// TrustedPublishers holds base64-encoded marshaled libp2p public
+	// keys whose signatures are accepted for external plugins. A
+	// plugin file <name>.so is verified against a detached signature
+	// in <name>.so.sig.
+	TrustedPublishers []string
+}
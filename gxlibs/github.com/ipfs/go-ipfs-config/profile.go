@@ -164,6 +164,57 @@ fetching may be degraded.
 			return nil
 		},
 	},
+	"ethofs-gn": {
+		Description: `Configures the node as an ethoFS gateway node.
+Applies the server profile, exposes the HTTP gateway on all
+interfaces and tunes the connection manager and reprovider for
+serving gateway traffic.`,
+
+		Transform: func(c *Config) error {
+			if err := applyEthofsServerBase(c); err != nil {
+				return err
+			}
+			c.Addresses.Gateway = Strings{"/ip4/0.0.0.0/tcp/8080"}
+			c.Gateway.Writable = false
+
+			c.Swarm.ConnMgr.LowWater = 100
+			c.Swarm.ConnMgr.HighWater = 400
+			c.Reprovider.Strategy = "pinned"
+			return nil
+		},
+	},
+	"ethofs-mn": {
+		Description: `Configures the node as an ethoFS masternode.
+Applies the server profile and raises the connection manager limits
+to match the masternode resource requirements. All stored objects
+are reprovided to the network.`,
+
+		Transform: func(c *Config) error {
+			if err := applyEthofsServerBase(c); err != nil {
+				return err
+			}
+			c.Swarm.ConnMgr.LowWater = 300
+			c.Swarm.ConnMgr.HighWater = 900
+			c.Reprovider.Strategy = "all"
+			return nil
+		},
+	},
+	"ethofs-sn": {
+		Description: `Configures the node as an ethoFS service node.
+Applies the server profile with connection manager limits sized for
+the service node resource requirements. Only pinned content is
+reprovided.`,
+
+		Transform: func(c *Config) error {
+			if err := applyEthofsServerBase(c); err != nil {
+				return err
+			}
+			c.Swarm.ConnMgr.LowWater = 150
+			c.Swarm.ConnMgr.HighWater = 500
+			c.Reprovider.Strategy = "pinned"
+			return nil
+		},
+	},
 	"randomports": {
 		Description: `Use a random port number for swarm.`,
 
@@ -181,6 +232,21 @@ fetching may be degraded.
 	},
 }
 
+// applyEthofsServerBase holds the settings shared by all ethoFS node
+// tiers: the server profile plus a common reprovide interval and
+// connection manager grace period.
+func applyEthofsServerBase(c *Config) error {
+	c.Addresses.NoAnnounce = appendSingle(c.Addresses.NoAnnounce, defaultServerFilters)
+	c.Swarm.AddrFilters = appendSingle(c.Swarm.AddrFilters, defaultServerFilters)
+	c.Discovery.MDNS.Enabled = false
+	c.Swarm.DisableNatPortMap = true
+
+	c.Reprovider.Interval = "12h"
+	c.Swarm.ConnMgr.Type = "basic"
+	c.Swarm.ConnMgr.GracePeriod = (time.Second * 20).String()
+	return nil
+}
+
 func getAvailablePort() (port int, err error) {
 	ln, err := net.Listen("tcp", "[::]:0")
 	if err != nil {
@@ -0,0 +1,23 @@
+package config
+
+// P2PProxyRule routes proxied HTTP requests matching a hostname and/or
+// path prefix to a fixed target peer.
+type P2PProxyRule struct {
+	Host       string // hostname to match, empty matches any host
+	PathPrefix string // path prefix to match, empty matches "/"
+	Peer       string // base58 peer ID of the target peer
+	Protocol   string // libp2p protocol name, defaults to "/http"
+}
+
+// P2PProxy contains options for the experimental p2p HTTP proxy.
+type P2PProxy struct {
+	Rules []P2PProxyRule // hostname/path based routing rules
+
+	MaxRequestBodySize  int64 // max proxied request body in bytes, 0 is unlimited
+	MaxResponseBodySize int64 // max proxied response body in bytes, 0 is unlimited
+
+	SetHeaders    map[string][]string // headers set on proxied requests
+	RemoveHeaders []string            // headers stripped from proxied requests
+
+	AuthSecret string // required 'Authorization: Bearer' token, empty disables auth
+}
@@ -3,4 +3,10 @@ package config
 type Reprovider struct {
 	Interval string // Time period to reprovide locally stored objects to the network
 	Strategy string // Which keys to announce
+
+	// BatchRootProvides disables the per-block provider announcements
+	// made while blocks are written and instead announces only the roots
+	// of newly added content, in periodic batches. Recommended for nodes
+	// doing bulk ingest.
+	BatchRootProvides bool `json:",omitempty"`
 }
@@ -4,4 +4,9 @@ package config
 type Routing struct {
 	// Type sets default daemon routing mode.
 	Type string
+
+	// SlowQueryThreshold is a duration string; DHT lookups slower than
+	// this are logged with the peers involved. Empty uses the built-in
+	// default, "0" disables the slow-query log.
+	SlowQueryThreshold string
 }
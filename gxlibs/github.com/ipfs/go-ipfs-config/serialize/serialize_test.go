@@ -1,7 +1,9 @@
 package fsrepo
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 
@@ -9,11 +11,16 @@ import (
 )
 
 func TestConfig(t *testing.T) {
-	const filename = ".ipfsconfig"
+	tmp, err := ioutil.TempDir("", "ipfs-config-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, ".ipfsconfig")
 	cfgWritten := new(config.Config)
 	cfgWritten.Identity.PeerID = "faketest"
 
-	err := WriteConfigFile(filename, cfgWritten)
+	err = WriteConfigFile(filename, cfgWritten)
 	if err != nil {
 		t.Fatal(err)
 	}
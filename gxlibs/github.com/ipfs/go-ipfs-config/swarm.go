@@ -12,6 +12,23 @@ type SwarmConfig struct {
 	EnableAutoRelay bool
 	// if true, then an AutoNATService will be instantiated to facilitate autorelay
 	EnableAutoNATService bool
+	// maximum number of dial-back requests the AutoNATService will serve
+	// per peer and reset interval; 0 uses the service default
+	AutoNATServiceThrottle int
+
+	// address ("host:port") of a SOCKS5 proxy used for outbound
+	// connections; name resolution of proxied dials happens on the
+	// proxy. Empty disables proxying.
+	Socks5Proxy string
+	// transports that dial through the proxy: "tcp", "ws". An empty
+	// list enables the proxy for all supported transports.
+	Socks5ProxyTransports []string
+
+	// node-wide bandwidth caps in bytes per second, enforced on all
+	// swarm streams regardless of protocol; 0 means unlimited. Can be
+	// changed at runtime with 'ipfs swarm throttle'.
+	UploadRateLimit   int64
+	DownloadRateLimit int64
 
 	ConnMgr ConnMgr
 }
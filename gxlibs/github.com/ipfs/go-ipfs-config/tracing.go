@@ -0,0 +1,9 @@
+package config
+
+// Tracing contains options for exporting traces to an external
+// collector.
+type Tracing struct {
+	Enabled     bool   // export spans to the collector when true
+	Endpoint    string // HTTP endpoint of the trace collector
+	ServiceName string // service name reported with spans, defaults to "go-ipfs"
+}
@@ -32,11 +32,14 @@ func NewBatch(ctx context.Context, na NodeAdder, opts ...BatchOption) *Batch {
 	for _, o := range opts {
 		o(&bopts)
 	}
+	if bopts.parallelCommits <= 0 {
+		bopts.parallelCommits = ParallelBatchCommits
+	}
 	return &Batch{
 		na:            na,
 		ctx:           ctx,
 		cancel:        cancel,
-		commitResults: make(chan error, ParallelBatchCommits),
+		commitResults: make(chan error, bopts.parallelCommits),
 		opts:          bopts,
 	}
 }
@@ -78,7 +81,7 @@ func (t *Batch) asyncCommit() {
 	if numBlocks == 0 {
 		return
 	}
-	if t.activeCommits >= ParallelBatchCommits {
+	if t.activeCommits >= t.opts.parallelCommits {
 		select {
 		case err := <-t.commitResults:
 			t.activeCommits--
@@ -193,8 +196,9 @@ loop:
 type BatchOption func(o *batchOptions)
 
 type batchOptions struct {
-	maxSize  int
-	maxNodes int
+	maxSize         int
+	maxNodes        int
+	parallelCommits int
 }
 
 var defaultBatchOptions = batchOptions{
@@ -220,6 +224,15 @@ func MaxNodesBatchOption(num int) BatchOption {
 	}
 }
 
+// ParallelCommitsBatchOption sets the number of batch commits a Batch
+// keeps in flight before blocking, overriding ParallelBatchCommits
+// for this Batch only.
+func ParallelCommitsBatchOption(num int) BatchOption {
+	return func(o *batchOptions) {
+		o.parallelCommits = num
+	}
+}
+
 // BufferedDAG implements DAGService using a Batch NodeAdder to wrap add
 // operations in the given DAGService. It will trigger Commit() before any
 // non-Add operations, but otherwise calling Commit() is left to the user.
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	logging "github.com/whyrusleeping/go-logging"
+)
+
+// jsonBackend emits one JSON object per log record
+type jsonBackend struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+type jsonEntry struct {
+	Time      string `json:"ts"`
+	Level     string `json:"level"`
+	Subsystem string `json:"subsystem"`
+	Message   string `json:"msg"`
+}
+
+func (b *jsonBackend) Log(level logging.Level, calldepth int, rec *logging.Record) error {
+	buf, err := json.Marshal(jsonEntry{
+		Time:      rec.Time.Format(time.RFC3339Nano),
+		Level:     level.String(),
+		Subsystem: rec.Module,
+		Message:   rec.Message(),
+	})
+	if err != nil {
+		return err
+	}
+	buf = append(buf, '\n')
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, err = b.w.Write(buf)
+	return err
+}
+
+// SetupJSONLogging routes all log output to w as one JSON object per
+// line
+func SetupJSONLogging(w io.Writer) {
+	logging.SetBackend(&jsonBackend{w: w})
+}
+
+// SetupTextLogging routes all log output to w using the named format
+// from LogFormats, falling back to the default format
+func SetupTextLogging(w io.Writer, format string) {
+	lfmt := LogFormats[format]
+	if lfmt == "" {
+		lfmt = LogFormats[defaultLogFormat]
+	}
+
+	logging.SetBackend(logging.NewLogBackend(w, "", 0))
+	logging.SetFormatter(logging.MustStringFormatter(lfmt))
+}
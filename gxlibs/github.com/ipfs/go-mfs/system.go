@@ -133,6 +133,17 @@ func (kr *Root) Flush() error {
 // may have unintended racy side effects.
 // A better implemented mfs system (one that does smarter internal caching and
 // refcounting) shouldnt need this method.
+// SetRepublishTimeouts adjusts how long the republisher waits after the
+// last change before publishing (short), and the most it will wait while
+// changes keep arriving (long). It should be called before the root is
+// modified. It is a no-op for roots without a publish function.
+func (kr *Root) SetRepublishTimeouts(short, long time.Duration) {
+	if kr.repub != nil {
+		kr.repub.TimeoutShort = short
+		kr.repub.TimeoutLong = long
+	}
+}
+
 func (kr *Root) FlushMemFree(ctx context.Context) error {
 	dir := kr.GetDirectory()
 
@@ -41,6 +41,10 @@ type DagBuilderHelper struct {
 	// is not reused to construct another DAG, but a new one (with a
 	// zero `offset`) is created.
 	offset uint64
+
+	// Worker pipeline building leaf nodes in parallel (started lazily
+	// by `NewLeafDataNode`).
+	pipeline *leafPipeline
 }
 
 // DagBuilderParams wraps configuration options to create a DagBuilderHelper
@@ -107,6 +111,9 @@ func (db *DagBuilderHelper) prepareNext() {
 
 // Done returns whether or not we're done consuming the incoming data.
 func (db *DagBuilderHelper) Done() bool {
+	if db.pipeline != nil {
+		return db.pipeline.done()
+	}
 	// ensure we have an accurate perspective on data
 	// as `done` this may be called before `next`.
 	db.prepareNext() // idempotent
@@ -253,43 +260,75 @@ func (db *DagBuilderHelper) FillNodeLayer(node *UnixfsNode) error {
 
 // GetNextDataNode builds a UnixFsNode with the data obtained from the
 // Splitter, given the constraints (BlockSizeLimit, RawLeaves) specified
-// when creating the DagBuilderHelper.
+// when creating the DagBuilderHelper. Like `NewLeafDataNode` the leaves
+// are built by the worker pipeline, but with this layout's own leaf
+// format so the resulting dag stays byte-identical to a sequential
+// build.
 func (db *DagBuilderHelper) GetNextDataNode() (*UnixfsNode, error) {
-	data, err := db.Next()
-	if err != nil {
-		return nil, err
+	if db.pipeline == nil {
+		db.startLeafPipeline(func(data []byte, _ uint64) (interface{}, error) {
+			return db.NewLeaf(data)
+		})
 	}
 
-	if data == nil { // we're done!
+	r := db.pipeline.take()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.eof { // we're done!
 		return nil, nil
 	}
 
-	return db.NewLeaf(data)
+	return r.node.(*UnixfsNode), nil
 }
 
 // NewLeafDataNode is a variation of `GetNextDataNode` that returns
 // an `ipld.Node` instead. It builds the `node` with the data obtained
 // from the Splitter and returns it with the `dataSize` (that will be
-// used to keep track of the DAG file size). The size of the data is
-// computed here because after that it will be hidden by `NewLeafNode`
-// inside a generic `ipld.Node` representation.
+// used to keep track of the DAG file size). The leaf nodes are built
+// by a bounded worker pipeline (started on the first call) so the
+// hashing of consecutive chunks runs in parallel; results are
+// returned in file order.
 func (db *DagBuilderHelper) NewLeafDataNode() (node ipld.Node, dataSize uint64, err error) {
-	fileData, err := db.Next()
-	if err != nil {
-		return nil, 0, err
+	if db.pipeline == nil {
+		db.startLeafPipeline(func(data []byte, offset uint64) (interface{}, error) {
+			return db.buildLeafNode(data, offset)
+		})
+	}
+
+	r := db.pipeline.take()
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+	if r.eof {
+		// mirror the sequential behavior for a nil chunk
+		node, err = db.NewLeafNode(nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		return db.ProcessFileStore(node, 0), 0, nil
 	}
-	dataSize = uint64(len(fileData))
 
-	// Create a new leaf node containing the file chunk data.
-	node, err = db.NewLeafNode(fileData)
+	return r.node.(ipld.Node), r.size, nil
+}
+
+// buildLeafNode is the concurrency safe core of `NewLeafDataNode`,
+// run by the pipeline workers: it only reads the builder
+// configuration and tracks the filestore offset explicitly.
+func (db *DagBuilderHelper) buildLeafNode(data []byte, offset uint64) (ipld.Node, error) {
+	node, err := db.NewLeafNode(data)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	// Convert this leaf to a `FilestoreNode` if needed.
-	node = db.ProcessFileStore(node, dataSize)
+	node = db.processFileStoreAt(node, uint64(len(data)), offset)
 
-	return node, dataSize, nil
+	// Force the (cached) cid computation here, in the worker, so the
+	// hashing runs in parallel instead of when the node is added to
+	// the DAGService.
+	_ = node.Cid()
+
+	return node, nil
 }
 
 // ProcessFileStore generates, if Filestore is being used, the
@@ -301,24 +340,30 @@ func (db *DagBuilderHelper) NewLeafDataNode() (node ipld.Node, dataSize uint64,
 // `NewLeafDataNode` is called, both work in tandem but the
 // offset is more related to this function).
 func (db *DagBuilderHelper) ProcessFileStore(node ipld.Node, dataSize uint64) ipld.Node {
+	fn := db.processFileStoreAt(node, dataSize, db.offset)
+	if fn != node {
+		// Update `offset` with the size of the data generated by `db.Next`.
+		db.offset += dataSize
+	}
+	return fn
+}
+
+// processFileStoreAt is the explicit-offset variant of
+// `ProcessFileStore`, safe to call from the pipeline workers.
+func (db *DagBuilderHelper) processFileStoreAt(node ipld.Node, dataSize uint64, offset uint64) ipld.Node {
 	// Check if Filestore is being used.
 	if db.fullPath != "" {
 		// Check if the node is actually a raw node (needed for
 		// Filestore support).
 		if _, ok := node.(*dag.RawNode); ok {
-			fn := &pi.FilestoreNode{
+			return &pi.FilestoreNode{
 				Node: node,
 				PosInfo: &pi.PosInfo{
-					Offset:   db.offset,
+					Offset:   offset,
 					FullPath: db.fullPath,
 					Stat:     db.stat,
 				},
 			}
-
-			// Update `offset` with the size of the data generated by `db.Next`.
-			db.offset += dataSize
-
-			return fn
 		}
 	}
 
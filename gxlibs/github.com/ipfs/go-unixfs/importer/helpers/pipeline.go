@@ -0,0 +1,130 @@
+package helpers
+
+import (
+	"io"
+	"runtime"
+)
+
+// maxLeafWorkers bounds the number of goroutines building (and
+// hashing) leaf nodes for a single file.
+const maxLeafWorkers = 8
+
+// leafBuildFunc turns one chunk of file data into a leaf. Both layouts
+// provide their own builder -- they produce different leaf formats --
+// so it must be safe to call from several workers at once.
+type leafBuildFunc func(data []byte, offset uint64) (interface{}, error)
+
+// leafJob is one chunk of file data waiting to be turned into a leaf
+// node by a pipeline worker.
+type leafJob struct {
+	data   []byte
+	offset uint64
+	out    chan leafResult
+}
+
+// leafResult is a built leaf node, or the error / end-of-file marker
+// that stopped the pipeline.
+type leafResult struct {
+	node interface{}
+	size uint64
+	err  error
+	eof  bool
+}
+
+// leafPipeline fans chunks from the splitter out to a bounded set of
+// workers that build and hash the leaf nodes, and hands the results
+// back in file order. Chunking stays sequential -- content defined
+// chunkers depend on it -- but the per-chunk hashing, which dominates
+// import cost, runs in parallel.
+type leafPipeline struct {
+	queue  chan chan leafResult
+	peeked *leafResult
+}
+
+// startLeafPipeline spawns the chunk reader and the leaf workers for
+// this builder. A chunk already buffered by an earlier Done() call is
+// fed through the pipeline first.
+func (db *DagBuilderHelper) startLeafPipeline(build leafBuildFunc) {
+	workers := runtime.NumCPU()
+	if workers > maxLeafWorkers {
+		workers = maxLeafWorkers
+	}
+
+	jobs := make(chan leafJob, workers)
+	lp := &leafPipeline{queue: make(chan chan leafResult, workers*2)}
+	db.pipeline = lp
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range jobs {
+				node, err := build(job.data, job.offset)
+				job.out <- leafResult{node: node, size: uint64(len(job.data)), err: err}
+			}
+		}()
+	}
+
+	first := db.nextData
+	firstErr := db.recvdErr
+	db.nextData = nil
+	db.recvdErr = nil
+
+	go func() {
+		defer close(lp.queue)
+		defer close(jobs)
+
+		data, err := first, firstErr
+		primed := data != nil || err != nil
+		var offset uint64
+		for {
+			if !primed {
+				data, err = db.spl.NextBytes()
+			}
+			primed = false
+
+			if err == io.EOF || (err == nil && data == nil) {
+				return
+			}
+
+			out := make(chan leafResult, 1)
+			lp.queue <- out
+			if err != nil {
+				out <- leafResult{err: err}
+				return
+			}
+
+			jobs <- leafJob{data: data, offset: offset, out: out}
+			offset += uint64(len(data))
+		}
+	}()
+}
+
+// peek blocks until the next in-order result is available and caches
+// it for take().
+func (lp *leafPipeline) peek() *leafResult {
+	if lp.peeked == nil {
+		out, ok := <-lp.queue
+		if !ok {
+			lp.peeked = &leafResult{eof: true}
+		} else {
+			r := <-out
+			lp.peeked = &r
+		}
+	}
+	return lp.peeked
+}
+
+// done mirrors DagBuilderHelper.Done: it only reports true once the
+// data is exhausted; a pending error is reported by the next take().
+func (lp *leafPipeline) done() bool {
+	r := lp.peek()
+	return r.err == nil && r.eof
+}
+
+// take returns the next in-order result.
+func (lp *leafPipeline) take() leafResult {
+	r := *lp.peek()
+	if !r.eof {
+		lp.peeked = nil
+	}
+	return r
+}
@@ -136,6 +136,30 @@ func BenchmarkTrickleReadFull(b *testing.B) {
 	runReadBench(b, nd, ds)
 }
 
+func BenchmarkBalancedWriteSmallBlock(b *testing.B) {
+	runWriteBench(b, 4096)
+}
+
+func BenchmarkBalancedWriteFull(b *testing.B) {
+	runWriteBench(b, chunker.DefaultBlockSize)
+}
+
+func runWriteBench(b *testing.B, blksize int64) {
+	nbytes := int64(10000000)
+	buf := make([]byte, nbytes)
+	u.NewTimeSeededRand().Read(buf)
+
+	b.SetBytes(nbytes)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ds := mdtest.Mock()
+		_, err := BuildDagFromReader(ds, chunker.NewSizeSplitter(bytes.NewReader(buf), blksize))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func runReadBench(b *testing.B, nd ipld.Node, ds ipld.DAGService) {
 	for i := 0; i < b.N; i++ {
 		ctx, cancel := context.WithCancel(context.Background())
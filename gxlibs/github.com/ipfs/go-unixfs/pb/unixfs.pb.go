@@ -73,6 +73,8 @@ type Data struct {
 	Blocksizes           []uint64       `protobuf:"varint,4,rep,name=blocksizes" json:"blocksizes,omitempty"`
 	HashType             *uint64        `protobuf:"varint,5,opt,name=hashType" json:"hashType,omitempty"`
 	Fanout               *uint64        `protobuf:"varint,6,opt,name=fanout" json:"fanout,omitempty"`
+	Mode                 *uint32        `protobuf:"varint,7,opt,name=mode" json:"mode,omitempty"`
+	Mtime                *int64         `protobuf:"varint,8,opt,name=mtime" json:"mtime,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
 	XXX_unrecognized     []byte         `json:"-"`
 	XXX_sizecache        int32          `json:"-"`
@@ -144,8 +146,23 @@ func (m *Data) GetFanout() uint64 {
 	return 0
 }
 
+func (m *Data) GetMode() uint32 {
+	if m != nil && m.Mode != nil {
+		return *m.Mode
+	}
+	return 0
+}
+
+func (m *Data) GetMtime() int64 {
+	if m != nil && m.Mtime != nil {
+		return *m.Mtime
+	}
+	return 0
+}
+
 type Metadata struct {
 	MimeType             *string  `protobuf:"bytes,1,opt,name=MimeType" json:"MimeType,omitempty"`
+	Pairs                []string `protobuf:"bytes,2,rep,name=Pairs" json:"Pairs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -182,6 +199,13 @@ func (m *Metadata) GetMimeType() string {
 	return ""
 }
 
+func (m *Metadata) GetPairs() []string {
+	if m != nil {
+		return m.Pairs
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Data)(nil), "unixfs.pb.Data")
 	proto.RegisterType((*Metadata)(nil), "unixfs.pb.Metadata")
@@ -5,6 +5,10 @@ package unixfs
 
 import (
 	"errors"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	proto "github.com/gogo/protobuf/proto"
 
@@ -289,6 +293,34 @@ func (n *FSNode) Type() pb.Data_DataType {
 	return n.format.GetType()
 }
 
+// Mode returns the file mode bits recorded for this node, or 0 if none
+// have been set.
+func (n *FSNode) Mode() os.FileMode {
+	return os.FileMode(n.format.GetMode()) & 07777
+}
+
+// SetMode records the given file mode bits (permissions plus the setuid,
+// setgid and sticky bits) on this node. The mode is advisory; it is not
+// enforced by unixfs itself.
+func (n *FSNode) SetMode(m os.FileMode) {
+	n.format.Mode = proto.Uint32(uint32(m) & 07777)
+}
+
+// Mtime returns the modification time recorded for this node. The zero
+// time means no modification time has been set.
+func (n *FSNode) Mtime() time.Time {
+	if n.format.Mtime == nil {
+		return time.Time{}
+	}
+	return time.Unix(n.format.GetMtime(), 0)
+}
+
+// SetMtime records the given modification time on this node, with second
+// precision.
+func (n *FSNode) SetMtime(t time.Time) {
+	n.format.Mtime = proto.Int64(t.Unix())
+}
+
 // IsDir checks whether the node represents a directory
 func (n *FSNode) IsDir() bool {
 	switch n.Type() {
@@ -303,6 +335,10 @@ func (n *FSNode) IsDir() bool {
 type Metadata struct {
 	MimeType string
 	Size     uint64
+
+	// Pairs holds arbitrary key/value metadata. Keys must not contain
+	// '='; values may.
+	Pairs map[string]string
 }
 
 // MetadataFromBytes Unmarshals a protobuf Data message into Metadata.
@@ -324,6 +360,16 @@ func MetadataFromBytes(b []byte) (*Metadata, error) {
 	}
 	md := new(Metadata)
 	md.MimeType = pbm.GetMimeType()
+	if pairs := pbm.GetPairs(); len(pairs) > 0 {
+		md.Pairs = make(map[string]string, len(pairs))
+		for _, p := range pairs {
+			kv := strings.SplitN(p, "=", 2)
+			if len(kv) != 2 {
+				return nil, errors.New("malformed metadata pair")
+			}
+			md.Pairs[kv[0]] = kv[1]
+		}
+	}
 	return md, nil
 }
 
@@ -331,6 +377,21 @@ func MetadataFromBytes(b []byte) (*Metadata, error) {
 func (m *Metadata) Bytes() ([]byte, error) {
 	pbm := new(pb.Metadata)
 	pbm.MimeType = &m.MimeType
+	if len(m.Pairs) > 0 {
+		// encoded in sorted key order so equal metadata always
+		// serializes to the same bytes (and the same cid)
+		keys := make([]string, 0, len(m.Pairs))
+		for k := range m.Pairs {
+			if strings.Contains(k, "=") {
+				return nil, errors.New("metadata keys must not contain '='")
+			}
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			pbm.Pairs = append(pbm.Pairs, k+"="+m.Pairs[k])
+		}
+	}
 	return proto.Marshal(pbm)
 }
 
@@ -31,6 +31,7 @@ type BasicConnMgr struct {
 	connCount   int
 	gracePeriod time.Duration
 	peers       map[peer.ID]*peerInfo
+	protected   map[peer.ID]struct{}
 
 	// channel-based semaphore that enforces only a single trim is in progress
 	trimRunningCh chan struct{}
@@ -51,10 +52,51 @@ func NewConnManager(low, hi int, grace time.Duration) *BasicConnMgr {
 		lowWater:      low,
 		gracePeriod:   grace,
 		peers:         make(map[peer.ID]*peerInfo),
+		protected:     make(map[peer.ID]struct{}),
 		trimRunningCh: make(chan struct{}, 1),
 	}
 }
 
+// Protect marks a peer as exempt from trimming. Its connections stay open until
+// the peer is unprotected again, no matter how the peer is tagged.
+func (cm *BasicConnMgr) Protect(p peer.ID) {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	cm.protected[p] = struct{}{}
+}
+
+// Unprotect removes the trimming exemption from a peer, making its connections
+// subject to pruning again.
+func (cm *BasicConnMgr) Unprotect(p peer.ID) {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	delete(cm.protected, p)
+}
+
+// ListProtected returns the peers currently exempt from trimming.
+func (cm *BasicConnMgr) ListProtected() []peer.ID {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	out := make([]peer.ID, 0, len(cm.protected))
+	for p := range cm.protected {
+		out = append(out, p)
+	}
+	return out
+}
+
+// SetWatermarks replaces the low and high watermarks at runtime. Setting both
+// to zero disables trimming; the new values take effect on the next trim.
+func (cm *BasicConnMgr) SetWatermarks(low, hi int) {
+	cm.lk.Lock()
+	defer cm.lk.Unlock()
+
+	cm.lowWater = low
+	cm.highWater = hi
+}
+
 // peerInfo stores metadata for a given peer.
 type peerInfo struct {
 	tags  map[string]int // value for each tag
@@ -110,7 +152,11 @@ func (cm *BasicConnMgr) getConnsToClose(ctx context.Context) []inet.Conn {
 
 	var infos []*peerInfo
 
-	for _, inf := range cm.peers {
+	for p, inf := range cm.peers {
+		if _, ok := cm.protected[p]; ok {
+			// never prune protected peers.
+			continue
+		}
 		infos = append(infos, inf)
 	}
 
@@ -120,6 +166,10 @@ func (cm *BasicConnMgr) getConnsToClose(ctx context.Context) []inet.Conn {
 	})
 
 	closeCount := len(infos) - cm.lowWater
+	if closeCount <= 0 {
+		// the remaining peers are all protected.
+		return nil
+	}
 	toclose := infos[:closeCount]
 
 	// 2x number of peers we're disconnecting from because we may have more
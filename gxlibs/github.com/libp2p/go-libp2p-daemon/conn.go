@@ -146,6 +146,16 @@ func (d *Daemon) handleConn(c net.Conn) {
 				return
 			}
 
+		case pb.Request_NOTIFY:
+			err := w.WriteMsg(okResponse())
+			if err != nil {
+				log.Debugf("Error writing response: %s", err.Error())
+				return
+			}
+
+			d.doNotifyPipe(r, w)
+			return
+
 		default:
 			log.Debugf("Unexpected request type: %d", *req.Type)
 			return
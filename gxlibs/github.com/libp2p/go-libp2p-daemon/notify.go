@@ -0,0 +1,89 @@
+package p2pd
+
+import (
+	pb "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-daemon/pb"
+
+	ggio "github.com/gogo/protobuf/io"
+	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+)
+
+// connNotifee forwards network events to a client connection as
+// Notification messages.
+type connNotifee struct {
+	out chan *pb.Notification
+}
+
+func (n *connNotifee) notify(msg *pb.Notification) {
+	select {
+	case n.out <- msg:
+	default:
+		// drop the event rather than stall the network on a slow client
+		log.Debugf("dropping notification (%s); client not keeping up", msg.GetType().String())
+	}
+}
+
+func (n *connNotifee) Connected(net inet.Network, c inet.Conn) {
+	n.notify(&pb.Notification{
+		Type: pb.Notification_PEER_CONNECTED.Enum(),
+		Peer: []byte(c.RemotePeer()),
+	})
+}
+
+func (n *connNotifee) Disconnected(net inet.Network, c inet.Conn) {
+	n.notify(&pb.Notification{
+		Type: pb.Notification_PEER_DISCONNECTED.Enum(),
+		Peer: []byte(c.RemotePeer()),
+	})
+}
+
+func (n *connNotifee) OpenedStream(net inet.Network, s inet.Stream) {
+	n.notify(&pb.Notification{
+		Type:       pb.Notification_STREAM_OPENED.Enum(),
+		Peer:       []byte(s.Conn().RemotePeer()),
+		StreamInfo: makeStreamInfo(s),
+	})
+}
+
+func (n *connNotifee) ClosedStream(net inet.Network, s inet.Stream) {}
+func (n *connNotifee) Listen(net inet.Network, a ma.Multiaddr)      {}
+func (n *connNotifee) ListenClose(net inet.Network, a ma.Multiaddr) {}
+
+func (d *Daemon) doNotifyPipe(r ggio.ReadCloser, w ggio.WriteCloser) {
+	nn := &connNotifee{out: make(chan *pb.Notification, 64)}
+	d.host.Network().Notify(nn)
+	defer d.host.Network().StopNotify(nn)
+
+	done := make(chan struct{})
+	go func() {
+		// read something until the client closes the connection
+		// at which point we deregister the notifee
+		defer close(done)
+		for {
+			var req pb.Request
+			err := r.ReadMsg(&req)
+			if err != nil {
+				return
+			}
+
+			log.Warningf("unexpected message (%s)", req.GetType().String())
+		}
+	}()
+
+	for {
+		select {
+		case msg := <-nn.out:
+			err := w.WriteMsg(msg)
+			if err != nil {
+				log.Debugf("error writing notification: %s", err.Error())
+				return
+			}
+
+		case <-done:
+			return
+
+		case <-d.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package p2pclient
+
+import (
+	"context"
+	"errors"
+
+	ggio "github.com/gogo/protobuf/io"
+	pb "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-daemon/pb"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+)
+
+// Notification is a network event reported by the daemon.
+type Notification struct {
+	// Type says what happened: a peer connected or disconnected, or a
+	// stream was opened.
+	Type pb.Notification_Type
+	// Peer is the remote peer the event concerns.
+	Peer peer.ID
+	// StreamInfo describes the opened stream; it is nil for connection
+	// events.
+	StreamInfo *StreamInfo
+}
+
+func convertNotification(msg *pb.Notification) (Notification, error) {
+	id, err := peer.IDFromBytes(msg.GetPeer())
+	if err != nil {
+		return Notification{}, err
+	}
+
+	n := Notification{
+		Type: msg.GetType(),
+		Peer: id,
+	}
+	if info := msg.GetStreamInfo(); info != nil {
+		n.StreamInfo, err = convertStreamInfo(info)
+		if err != nil {
+			return Notification{}, err
+		}
+	}
+
+	return n, nil
+}
+
+// Notify subscribes to the daemon's network events — peers connecting and
+// disconnecting, and streams being opened — and returns them on a channel,
+// so applications driving the daemon can react to events instead of
+// polling. The channel is closed when ctx is done.
+func (c *Client) Notify(ctx context.Context) (<-chan Notification, error) {
+	control, err := c.newControlConn()
+	if err != nil {
+		return nil, err
+	}
+	r := ggio.NewDelimitedReader(control, MessageSizeMax)
+	w := ggio.NewDelimitedWriter(control)
+
+	req := &pb.Request{Type: pb.Request_NOTIFY.Enum()}
+	if err = w.WriteMsg(req); err != nil {
+		control.Close()
+		return nil, err
+	}
+
+	res := &pb.Response{}
+	if err = r.ReadMsg(res); err != nil {
+		control.Close()
+		return nil, err
+	}
+
+	if reserr := res.GetError(); reserr != nil {
+		control.Close()
+		return nil, errors.New(reserr.GetMsg())
+	}
+
+	out := make(chan Notification)
+	go func() {
+		defer close(out)
+		defer control.Close()
+
+		for {
+			msg := &pb.Notification{}
+			if err := r.ReadMsg(msg); err != nil {
+				if ctx.Err() == nil {
+					log.Errorf("reading notification: %s", err)
+				}
+				return
+			}
+
+			n, err := convertNotification(msg)
+			if err != nil {
+				log.Errorf("error parsing notification: %s", err)
+				continue
+			}
+
+			select {
+			case out <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		// closing the connection deregisters the notifee daemon-side
+		<-ctx.Done()
+		control.Close()
+	}()
+
+	return out, nil
+}
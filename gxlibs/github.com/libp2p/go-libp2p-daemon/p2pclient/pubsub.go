@@ -0,0 +1,149 @@
+package p2pclient
+
+import (
+	"context"
+	"errors"
+
+	ggio "github.com/gogo/protobuf/io"
+	pb "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-daemon/pb"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+)
+
+func newPubsubReq(req *pb.PSRequest) *pb.Request {
+	return &pb.Request{
+		Type:   pb.Request_PUBSUB.Enum(),
+		Pubsub: req,
+	}
+}
+
+// doPubsub issues a one-shot pubsub request to the daemon and returns its
+// PSResponse.
+func (c *Client) doPubsub(psReq *pb.PSRequest) (*pb.PSResponse, error) {
+	control, err := c.newControlConn()
+	if err != nil {
+		return nil, err
+	}
+	defer control.Close()
+	r := ggio.NewDelimitedReader(control, MessageSizeMax)
+	w := ggio.NewDelimitedWriter(control)
+
+	if err = w.WriteMsg(newPubsubReq(psReq)); err != nil {
+		return nil, err
+	}
+
+	res := &pb.Response{}
+	if err = r.ReadMsg(res); err != nil {
+		return nil, err
+	}
+
+	if reserr := res.GetError(); reserr != nil {
+		return nil, errors.New(reserr.GetMsg())
+	}
+
+	return res.GetPubsub(), nil
+}
+
+// GetTopics returns the list of topics the daemon is subscribed to.
+func (c *Client) GetTopics() ([]string, error) {
+	res, err := c.doPubsub(&pb.PSRequest{
+		Type: pb.PSRequest_GET_TOPICS.Enum(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.GetTopics(), nil
+}
+
+// ListTopicPeers returns the list of peers the daemon sees in a topic.
+func (c *Client) ListTopicPeers(topic string) ([]peer.ID, error) {
+	res, err := c.doPubsub(&pb.PSRequest{
+		Type:  pb.PSRequest_LIST_PEERS.Enum(),
+		Topic: &topic,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]peer.ID, 0, len(res.GetPeerIDs()))
+	for i, idbytes := range res.GetPeerIDs() {
+		id, err := peer.IDFromBytes(idbytes)
+		if err != nil {
+			log.Errorf("failed to parse peer ID in position %d in response to list peers request", i)
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Publish publishes data under a topic through the daemon.
+func (c *Client) Publish(topic string, data []byte) error {
+	_, err := c.doPubsub(&pb.PSRequest{
+		Type:  pb.PSRequest_PUBLISH.Enum(),
+		Topic: &topic,
+		Data:  data,
+	})
+	return err
+}
+
+// Subscribe subscribes the daemon to a topic and returns a channel of the
+// messages published to it. The subscription is cancelled, and the channel
+// closed, when ctx is done.
+func (c *Client) Subscribe(ctx context.Context, topic string) (<-chan *pb.PSMessage, error) {
+	control, err := c.newControlConn()
+	if err != nil {
+		return nil, err
+	}
+	r := ggio.NewDelimitedReader(control, MessageSizeMax)
+	w := ggio.NewDelimitedWriter(control)
+
+	req := newPubsubReq(&pb.PSRequest{
+		Type:  pb.PSRequest_SUBSCRIBE.Enum(),
+		Topic: &topic,
+	})
+	if err = w.WriteMsg(req); err != nil {
+		control.Close()
+		return nil, err
+	}
+
+	res := &pb.Response{}
+	if err = r.ReadMsg(res); err != nil {
+		control.Close()
+		return nil, err
+	}
+
+	if reserr := res.GetError(); reserr != nil {
+		control.Close()
+		return nil, errors.New(reserr.GetMsg())
+	}
+
+	out := make(chan *pb.PSMessage)
+	go func() {
+		defer close(out)
+		defer control.Close()
+
+		for {
+			msg := &pb.PSMessage{}
+			if err := r.ReadMsg(msg); err != nil {
+				if ctx.Err() == nil {
+					log.Errorf("reading pubsub message: %s", err)
+				}
+				return
+			}
+
+			select {
+			case out <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		// closing the connection cancels the subscription daemon-side
+		<-ctx.Done()
+		control.Close()
+	}()
+
+	return out, nil
+}
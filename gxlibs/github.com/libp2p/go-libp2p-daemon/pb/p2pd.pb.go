@@ -34,6 +34,7 @@ const (
 	Request_CONNMANAGER    Request_Type = 6
 	Request_DISCONNECT     Request_Type = 7
 	Request_PUBSUB         Request_Type = 8
+	Request_NOTIFY         Request_Type = 9
 )
 
 var Request_Type_name = map[int32]string{
@@ -46,6 +47,7 @@ var Request_Type_name = map[int32]string{
 	6: "CONNMANAGER",
 	7: "DISCONNECT",
 	8: "PUBSUB",
+	9: "NOTIFY",
 }
 var Request_Type_value = map[string]int32{
 	"IDENTIFY":       0,
@@ -57,6 +59,7 @@ var Request_Type_value = map[string]int32{
 	"CONNMANAGER":    6,
 	"DISCONNECT":     7,
 	"PUBSUB":         8,
+	"NOTIFY":         9,
 }
 
 func (x Request_Type) Enum() *Request_Type {
@@ -292,6 +295,42 @@ func (PSRequest_Type) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_p2pd_c0044816e48d3fb2, []int{13, 0}
 }
 
+type Notification_Type int32
+
+const (
+	Notification_PEER_CONNECTED    Notification_Type = 0
+	Notification_PEER_DISCONNECTED Notification_Type = 1
+	Notification_STREAM_OPENED     Notification_Type = 2
+)
+
+var Notification_Type_name = map[int32]string{
+	0: "PEER_CONNECTED",
+	1: "PEER_DISCONNECTED",
+	2: "STREAM_OPENED",
+}
+var Notification_Type_value = map[string]int32{
+	"PEER_CONNECTED":    0,
+	"PEER_DISCONNECTED": 1,
+	"STREAM_OPENED":     2,
+}
+
+func (x Notification_Type) Enum() *Notification_Type {
+	p := new(Notification_Type)
+	*p = x
+	return p
+}
+func (x Notification_Type) String() string {
+	return proto.EnumName(Notification_Type_name, int32(x))
+}
+func (x *Notification_Type) UnmarshalJSON(data []byte) error {
+	value, err := proto.UnmarshalJSONEnum(Notification_Type_value, data, "Notification_Type")
+	if err != nil {
+		return err
+	}
+	*x = Notification_Type(value)
+	return nil
+}
+
 type Request struct {
 	Type                 *Request_Type         `protobuf:"varint,1,req,name=type,enum=p2pd.pb.Request_Type" json:"type,omitempty"`
 	Connect              *ConnectRequest       `protobuf:"bytes,2,opt,name=connect" json:"connect,omitempty"`
@@ -1372,6 +1411,66 @@ func (m *PSResponse) GetPeerIDs() [][]byte {
 	return nil
 }
 
+type Notification struct {
+	Type                 *Notification_Type `protobuf:"varint,1,req,name=type,enum=p2pd.pb.Notification_Type" json:"type,omitempty"`
+	Peer                 []byte             `protobuf:"bytes,2,opt,name=peer" json:"peer,omitempty"`
+	StreamInfo           *StreamInfo        `protobuf:"bytes,3,opt,name=streamInfo" json:"streamInfo,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *Notification) Reset()         { *m = Notification{} }
+func (m *Notification) String() string { return proto.CompactTextString(m) }
+func (*Notification) ProtoMessage()    {}
+func (m *Notification) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Notification) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Notification.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalTo(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (dst *Notification) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Notification.Merge(dst, src)
+}
+func (m *Notification) XXX_Size() int {
+	return m.Size()
+}
+func (m *Notification) XXX_DiscardUnknown() {
+	xxx_messageInfo_Notification.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Notification proto.InternalMessageInfo
+
+func (m *Notification) GetType() Notification_Type {
+	if m != nil && m.Type != nil {
+		return *m.Type
+	}
+	return Notification_PEER_CONNECTED
+}
+
+func (m *Notification) GetPeer() []byte {
+	if m != nil {
+		return m.Peer
+	}
+	return nil
+}
+
+func (m *Notification) GetStreamInfo() *StreamInfo {
+	if m != nil {
+		return m.StreamInfo
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Request)(nil), "p2pd.pb.Request")
 	proto.RegisterType((*Response)(nil), "p2pd.pb.Response")
@@ -1389,12 +1488,14 @@ func init() {
 	proto.RegisterType((*PSRequest)(nil), "p2pd.pb.PSRequest")
 	proto.RegisterType((*PSMessage)(nil), "p2pd.pb.PSMessage")
 	proto.RegisterType((*PSResponse)(nil), "p2pd.pb.PSResponse")
+	proto.RegisterType((*Notification)(nil), "p2pd.pb.Notification")
 	proto.RegisterEnum("p2pd.pb.Request_Type", Request_Type_name, Request_Type_value)
 	proto.RegisterEnum("p2pd.pb.Response_Type", Response_Type_name, Response_Type_value)
 	proto.RegisterEnum("p2pd.pb.DHTRequest_Type", DHTRequest_Type_name, DHTRequest_Type_value)
 	proto.RegisterEnum("p2pd.pb.DHTResponse_Type", DHTResponse_Type_name, DHTResponse_Type_value)
 	proto.RegisterEnum("p2pd.pb.ConnManagerRequest_Type", ConnManagerRequest_Type_name, ConnManagerRequest_Type_value)
 	proto.RegisterEnum("p2pd.pb.PSRequest_Type", PSRequest_Type_name, PSRequest_Type_value)
+	proto.RegisterEnum("p2pd.pb.Notification_Type", Notification_Type_name, Notification_Type_value)
 }
 func (m *Request) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
@@ -2197,6 +2298,50 @@ func (m *PSResponse) MarshalTo(dAtA []byte) (int, error) {
 	return i, nil
 }
 
+func (m *Notification) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Notification) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Type == nil {
+		return 0, github_com_gogo_protobuf_proto.NewRequiredNotSetError("type")
+	} else {
+		dAtA[i] = 0x8
+		i++
+		i = encodeVarintP2Pd(dAtA, i, uint64(*m.Type))
+	}
+	if m.Peer != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintP2Pd(dAtA, i, uint64(len(m.Peer)))
+		i += copy(dAtA[i:], m.Peer)
+	}
+	if m.StreamInfo != nil {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintP2Pd(dAtA, i, uint64(m.StreamInfo.Size()))
+		n, err := m.StreamInfo.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n
+	}
+	if m.XXX_unrecognized != nil {
+		i += copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return i, nil
+}
+
 func encodeVarintP2Pd(dAtA []byte, offset int, v uint64) int {
 	for v >= 1<<7 {
 		dAtA[offset] = uint8(v&0x7f | 0x80)
@@ -2633,6 +2778,29 @@ func (m *PSResponse) Size() (n int) {
 	return n
 }
 
+func (m *Notification) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Type != nil {
+		n += 1 + sovP2Pd(uint64(*m.Type))
+	}
+	if m.Peer != nil {
+		l = len(m.Peer)
+		n += 1 + l + sovP2Pd(uint64(l))
+	}
+	if m.StreamInfo != nil {
+		l = m.StreamInfo.Size()
+		n += 1 + l + sovP2Pd(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func sovP2Pd(x uint64) (n int) {
 	for {
 		n++
@@ -5191,6 +5359,146 @@ func (m *PSResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *Notification) Unmarshal(dAtA []byte) error {
+	var hasFields [1]uint64
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowP2Pd
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Notification: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Notification: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			var v Notification_Type
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowP2Pd
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (Notification_Type(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Type = &v
+			hasFields[0] |= uint64(0x00000001)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Peer", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowP2Pd
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthP2Pd
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Peer = append(m.Peer[:0], dAtA[iNdEx:postIndex]...)
+			if m.Peer == nil {
+				m.Peer = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StreamInfo", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowP2Pd
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthP2Pd
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.StreamInfo == nil {
+				m.StreamInfo = &StreamInfo{}
+			}
+			if err := m.StreamInfo.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipP2Pd(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthP2Pd
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+	if hasFields[0]&uint64(0x00000001) == 0 {
+		return github_com_gogo_protobuf_proto.NewRequiredNotSetError("type")
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipP2Pd(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -0,0 +1,31 @@
+package ifconnmgr
+
+import (
+	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+)
+
+// ConnGater makes admission decisions about connections. The swarm
+// consults it before dialing out, and the transport upgrader consults
+// it on both sides of the security handshake, so a denied connection
+// is torn down before any application data flows.
+//
+// Implementations must be safe for concurrent use.
+type ConnGater interface {
+	// InterceptPeerDial is consulted before dialing the given peer.
+	// Returning false aborts the dial before any address is tried.
+	InterceptPeerDial(p peer.ID) (allow bool)
+
+	// InterceptAddrDial is consulted for each address about to be
+	// dialed for a peer. Returning false skips that address.
+	InterceptAddrDial(p peer.ID, addr ma.Multiaddr) (allow bool)
+
+	// InterceptAccept is consulted for inbound connections right
+	// after accept, before the security handshake starts.
+	InterceptAccept(raddr ma.Multiaddr) (allow bool)
+
+	// InterceptSecured is consulted once the security handshake has
+	// established the remote peer's identity, in both directions.
+	InterceptSecured(dir inet.Direction, p peer.ID, raddr ma.Multiaddr) (allow bool)
+}
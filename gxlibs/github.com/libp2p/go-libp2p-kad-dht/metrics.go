@@ -0,0 +1,94 @@
+package dht
+
+import (
+	"time"
+
+	pset "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer/peerset"
+	prometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	queriesTotalMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ipfs",
+		Subsystem: "dht",
+		Name:      "queries_total",
+		Help:      "Total number of DHT queries by type.",
+	}, []string{"type"})
+
+	queryDurationMetric = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Namespace:  "ipfs",
+		Subsystem:  "dht",
+		Name:       "query_duration_seconds",
+		Help:       "DHT query latencies in seconds by type.",
+		Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+	}, []string{"type"})
+
+	lookupDurationMetric = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "ipfs",
+		Subsystem: "dht",
+		Name:      "lookup_duration_seconds",
+		Help:      "Individual DHT lookup latencies in seconds.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	})
+
+	lookupHopsMetric = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "ipfs",
+		Subsystem: "dht",
+		Name:      "lookup_hops",
+		Help:      "Number of peers queried during a DHT lookup.",
+		Buckets:   []float64{1, 2, 4, 8, 16, 32, 64, 128},
+	})
+
+	lookupsTotalMetric = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "ipfs",
+		Subsystem: "dht",
+		Name:      "lookups_total",
+		Help:      "Total number of DHT lookups by outcome.",
+	}, []string{"outcome"})
+)
+
+// SlowQueryThreshold is the duration above which a completed lookup is
+// written to the log together with the peers involved, so routing
+// regressions show up without waiting for user reports. Zero disables
+// the slow-query log.
+var SlowQueryThreshold = time.Minute
+
+func init() {
+	prometheus.MustRegister(
+		queriesTotalMetric,
+		queryDurationMetric,
+		lookupDurationMetric,
+		lookupHopsMetric,
+		lookupsTotalMetric,
+	)
+}
+
+// recordQuery observes a completed DHT query for metrics reporting
+func recordQuery(qtype string, start time.Time) {
+	queriesTotalMetric.WithLabelValues(qtype).Inc()
+	queryDurationMetric.WithLabelValues(qtype).Observe(time.Since(start).Seconds())
+}
+
+// recordLookup observes a single finished lookup run: its duration, the
+// number of peers queried (hops) and whether it produced a result. Runs
+// slower than SlowQueryThreshold are logged with the peers involved.
+func recordLookup(key string, start time.Time, queried *pset.PeerSet, success bool) {
+	took := time.Since(start)
+	hops := queried.Size()
+
+	lookupDurationMetric.Observe(took.Seconds())
+	lookupHopsMetric.Observe(float64(hops))
+	outcome := "failure"
+	if success {
+		outcome = "success"
+	}
+	lookupsTotalMetric.WithLabelValues(outcome).Inc()
+
+	if SlowQueryThreshold > 0 && took > SlowQueryThreshold {
+		if printable, err := tryFormatLoggableKey(key); err == nil {
+			key = printable
+		}
+		log.Warningf("slow dht query: key=%s took=%s hops=%d success=%t peers=%v",
+			key, took, hops, success, queried.Peers())
+	}
+}
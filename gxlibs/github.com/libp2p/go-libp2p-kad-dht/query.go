@@ -8,6 +8,7 @@ package dht
 import (
 	"context"
 	"sync"
+	"time"
 
 	u "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-util"
 	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
@@ -117,6 +118,8 @@ func (r *dhtQueryRunner) Run(ctx context.Context, peers []peer.ID) (*dhtQueryRes
 		return nil, nil
 	}
 
+	start := time.Now()
+
 	// setup concurrency rate limiting
 	for i := 0; i < r.query.concurrency; i++ {
 		r.rateLimit <- struct{}{}
@@ -163,9 +166,11 @@ func (r *dhtQueryRunner) Run(ctx context.Context, peers []peer.ID) (*dhtQueryRes
 	}
 
 	if r.result != nil && r.result.success {
+		recordLookup(r.query.key, start, r.peersQueried, true)
 		return r.result, nil
 	}
 
+	recordLookup(r.query.key, start, r.peersQueried, false)
 	return &dhtQueryResult{
 		finalSet:   r.peersSeen,
 		queriedSet: r.peersQueried,
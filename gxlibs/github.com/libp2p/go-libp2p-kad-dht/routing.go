@@ -21,6 +21,7 @@ import (
 	routing "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-routing"
 	notif "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-routing/notifications"
 	ropts "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-routing/options"
+	opentracing "github.com/opentracing/opentracing-go"
 )
 
 // asyncQueryBuffer is the size of buffered channels in async queries. This
@@ -36,6 +37,10 @@ var asyncQueryBuffer = 10
 // PutValue adds value corresponding to given Key.
 // This is the top level "Store" operation of the DHT
 func (dht *IpfsDHT) PutValue(ctx context.Context, key string, value []byte, opts ...ropts.Option) (err error) {
+	defer recordQuery("put_value", time.Now())
+	span, ctx := opentracing.StartSpanFromContext(ctx, "DHT.PutValue")
+	defer span.Finish()
+
 	eip := log.EventBegin(ctx, "PutValue")
 	defer func() {
 		eip.Append(loggableKey(key))
@@ -111,6 +116,10 @@ type RecvdVal struct {
 
 // GetValue searches for the value corresponding to given Key.
 func (dht *IpfsDHT) GetValue(ctx context.Context, key string, opts ...ropts.Option) (_ []byte, err error) {
+	defer recordQuery("get_value", time.Now())
+	span, ctx := opentracing.StartSpanFromContext(ctx, "DHT.GetValue")
+	defer span.Finish()
+
 	eip := log.EventBegin(ctx, "GetValue")
 	defer func() {
 		eip.Append(loggableKey(key))
@@ -396,6 +405,10 @@ func (dht *IpfsDHT) getValues(ctx context.Context, key string, nvals int) (<-cha
 
 // Provide makes this node announce that it can provide a value for the given key
 func (dht *IpfsDHT) Provide(ctx context.Context, key cid.Cid, brdcst bool) (err error) {
+	defer recordQuery("provide", time.Now())
+	span, ctx := opentracing.StartSpanFromContext(ctx, "DHT.Provide")
+	defer span.Finish()
+
 	eip := log.EventBegin(ctx, "Provide", key, logging.LoggableMap{"broadcast": brdcst})
 	defer func() {
 		if err != nil {
@@ -472,6 +485,9 @@ func (dht *IpfsDHT) FindProvidersAsync(ctx context.Context, key cid.Cid, count i
 }
 
 func (dht *IpfsDHT) findProvidersAsyncRoutine(ctx context.Context, key cid.Cid, count int, peerOut chan pstore.PeerInfo) {
+	defer recordQuery("find_providers", time.Now())
+	span, ctx := opentracing.StartSpanFromContext(ctx, "DHT.FindProviders")
+	defer span.Finish()
 	defer log.EventBegin(ctx, "findProvidersAsync", key).Done()
 	defer close(peerOut)
 
@@ -568,6 +584,10 @@ func (dht *IpfsDHT) findProvidersAsyncRoutine(ctx context.Context, key cid.Cid,
 
 // FindPeer searches for a peer with given ID.
 func (dht *IpfsDHT) FindPeer(ctx context.Context, id peer.ID) (_ pstore.PeerInfo, err error) {
+	defer recordQuery("find_peer", time.Now())
+	span, ctx := opentracing.StartSpanFromContext(ctx, "DHT.FindPeer")
+	defer span.Finish()
+
 	eip := log.EventBegin(ctx, "FindPeer", id)
 	defer func() {
 		if err != nil {
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
 	ic "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-crypto"
 	b58 "github.com/mr-tron/base58/base58"
+	mbase "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multibase"
 	mh "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multihash"
 )
 
@@ -90,12 +92,16 @@ func (id ID) Validate() error {
 }
 
 // IDFromString cast a string to ID type, and validate
-// the id to make sure it is a multihash.
+// the id to make sure it is a multihash. The textual forms — base58
+// and the base32 libp2p-key CID — are accepted as well.
 func IDFromString(s string) (ID, error) {
-	if _, err := mh.Cast([]byte(s)); err != nil {
-		return ID(""), err
+	if _, err := mh.Cast([]byte(s)); err == nil {
+		return ID(s), nil
 	}
-	return ID(s), nil
+	if id, err := IDB58Decode(s); err == nil {
+		return id, nil
+	}
+	return IDB32Decode(s)
 }
 
 // IDFromBytes cast a string to ID type, and validate
@@ -121,6 +127,24 @@ func IDB58Encode(id ID) string {
 	return b58.Encode([]byte(id))
 }
 
+// IDB32Decode decodes the base32 textual form of a Peer: a CIDv1 with
+// the libp2p-key codec.
+func IDB32Decode(s string) (ID, error) {
+	c, err := cid.Decode(s)
+	if err != nil {
+		return "", err
+	}
+	return FromCid(c)
+}
+
+// IDB32Encode returns the case-insensitive base32 textual form of the
+// ID, a CIDv1 with the libp2p-key codec, for use in subdomains and
+// URLs where base58 case-sensitivity is a problem.
+func IDB32Encode(id ID) string {
+	s, _ := ToCid(id).StringOfBase(mbase.Base32)
+	return s
+}
+
 // IDHexDecode returns a hex-decoded Peer
 func IDHexDecode(s string) (ID, error) {
 	m, err := mh.FromHexString(s)
@@ -135,6 +159,20 @@ func IDHexEncode(id ID) string {
 	return hex.EncodeToString([]byte(id))
 }
 
+// ToCid encodes a peer ID as a CID of the public key under the
+// libp2p-key codec.
+func ToCid(id ID) cid.Cid {
+	return cid.NewCidV1(cid.Libp2pKey, mh.Multihash(id))
+}
+
+// FromCid converts a libp2p-key CID back to a peer ID.
+func FromCid(c cid.Cid) (ID, error) {
+	if c.Type() != cid.Libp2pKey {
+		return "", fmt.Errorf("can't convert CID of type %x to a peer ID", c.Type())
+	}
+	return ID(c.Hash()), nil
+}
+
 // IDFromPublicKey returns the Peer ID corresponding to pk
 func IDFromPublicKey(pk ic.PubKey) (ID, error) {
 	b, err := pk.Bytes()
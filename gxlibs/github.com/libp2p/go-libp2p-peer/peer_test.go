@@ -226,6 +226,53 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestCidRoundTrip(t *testing.T) {
+	p, err := tu.RandPeerID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p2, err := FromCid(ToCid(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != p2 {
+		t.Error("peer ID changed when round-tripping through a CID")
+	}
+}
+
+func TestBase32(t *testing.T) {
+	p, err := tu.RandPeerID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc := IDB32Encode(p)
+	if strings.ToLower(enc) != enc {
+		t.Error("expected a lowercase base32 encoding")
+	}
+
+	p2, err := IDB32Decode(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != p2 {
+		t.Error("peer ID changed when round-tripping through base32")
+	}
+
+	// IDFromString should accept the base58 and base32 forms as well
+	// as the raw bytes
+	for _, s := range []string{string(p), IDB58Encode(p), enc} {
+		p2, err := IDFromString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p != p2 {
+			t.Errorf("IDFromString(%q) returned the wrong ID", s)
+		}
+	}
+}
+
 var hpkpMan = `QmRK3JgmVEGiewxWbhpXLJyjWuGuLeSTMTndA1coMHEy5o`
 var skManBytes = `
 CAAS4AQwggJcAgEAAoGBAL7w+Wc4VhZhCdM/+Hccg5Nrf4q9NXWwJylbSrXz/unFS24wyk6pEk0zi3W
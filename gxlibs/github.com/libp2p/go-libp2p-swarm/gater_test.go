@@ -0,0 +1,67 @@
+package swarm_test
+
+import (
+	"context"
+	"testing"
+
+	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+	pstore "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peerstore"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+)
+
+// testGater denies exactly the configured checks and allows the rest.
+type testGater struct {
+	denyPeerDial peer.ID
+	denySecured  peer.ID
+}
+
+func (g *testGater) InterceptPeerDial(p peer.ID) bool {
+	return p != g.denyPeerDial
+}
+
+func (g *testGater) InterceptAddrDial(p peer.ID, addr ma.Multiaddr) bool {
+	return true
+}
+
+func (g *testGater) InterceptAccept(raddr ma.Multiaddr) bool {
+	return true
+}
+
+func (g *testGater) InterceptSecured(dir inet.Direction, p peer.ID, raddr ma.Multiaddr) bool {
+	return p != g.denySecured
+}
+
+func TestGaterBlocksPeerDial(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	swarms := makeSwarms(ctx, t, 2)
+	defer closeSwarms(swarms)
+	s1, s2 := swarms[0], swarms[1]
+
+	s1.Gater = &testGater{denyPeerDial: s2.LocalPeer()}
+	s1.Peerstore().AddAddrs(s2.LocalPeer(), s2.ListenAddresses(), pstore.PermanentAddrTTL)
+
+	if _, err := s1.DialPeer(ctx, s2.LocalPeer()); err == nil {
+		t.Fatal("expected gater to block the dial")
+	}
+}
+
+func TestGaterBlocksSecuredConn(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	swarms := makeSwarms(ctx, t, 2)
+	defer closeSwarms(swarms)
+	s1, s2 := swarms[0], swarms[1]
+
+	// denySecured only takes effect after the handshake, once the
+	// remote identity is confirmed rather than merely claimed
+	s1.Gater = &testGater{denySecured: s2.LocalPeer()}
+	s1.Peerstore().AddAddrs(s2.LocalPeer(), s2.ListenAddresses(), pstore.PermanentAddrTTL)
+
+	if _, err := s1.DialPeer(ctx, s2.LocalPeer()); err == nil {
+		t.Fatal("expected the gater to kill the secured connection")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/jbenet/goprocess"
 	goprocessctx "github.com/ipsn/go-ipfs/gxlibs/github.com/jbenet/goprocess/context"
+	ifconnmgr "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-interface-connmgr"
 	metrics "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-metrics"
 	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
 	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
@@ -37,6 +38,10 @@ var ErrSwarmClosed = errors.New("swarm closed")
 // transport is misbehaving.
 var ErrAddrFiltered = errors.New("address filtered")
 
+// ErrGaterDisallowed is returned when the connection gater refuses a
+// connection or a dial.
+var ErrGaterDisallowed = errors.New("gater disallows connection")
+
 // Swarm is a connection muxer, allowing connections to other peers to
 // be opened and closed, while still using the same Chan for all
 // communication. The Chan sends/receives Messages, which note the
@@ -81,6 +86,10 @@ type Swarm struct {
 	// filters for addresses that shouldnt be dialed (or accepted)
 	Filters *filter.Filters
 
+	// gater, when set, makes admission decisions about every dial and
+	// every secured connection. Set it before the swarm is used.
+	Gater ifconnmgr.ConnGater
+
 	proc goprocess.Process
 	ctx  context.Context
 	bwc  metrics.Reporter
@@ -176,6 +185,14 @@ func (s *Swarm) addConn(tc transport.Conn, dir inet.Direction) (*Conn, error) {
 
 	p := tc.RemotePeer()
 
+	// The upgrader consults the gater too, but not every transport
+	// goes through the upgrader; this is the chokepoint all secured
+	// connections pass on their way into the swarm.
+	if s.Gater != nil && !s.Gater.InterceptSecured(dir, p, raddr) {
+		tc.Close()
+		return nil, ErrGaterDisallowed
+	}
+
 	// Add the public key.
 	if pk := tc.RemotePublicKey(); pk != nil {
 		s.peers.AddPubKey(p, pk)
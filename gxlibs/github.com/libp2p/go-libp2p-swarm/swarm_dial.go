@@ -193,6 +193,11 @@ func (s *Swarm) dialPeer(ctx context.Context, p peer.ID) (*Conn, error) {
 		return nil, ErrDialToSelf
 	}
 
+	if s.Gater != nil && !s.Gater.InterceptPeerDial(p) {
+		log.Debugf("gater disallowed dial to peer %s", p)
+		return nil, ErrGaterDisallowed
+	}
+
 	defer log.EventBegin(ctx, "swarmDialAttemptSync", p).Done()
 
 	// check if we already have an open connection first
@@ -412,6 +417,10 @@ func (s *Swarm) dialAddr(ctx context.Context, p peer.ID, addr ma.Multiaddr) (tra
 	}
 	log.Debugf("%s swarm dialing %s %s", s.local, p, addr)
 
+	if s.Gater != nil && !s.Gater.InterceptAddrDial(p, addr) {
+		return nil, ErrGaterDisallowed
+	}
+
 	tpt := s.TransportForDialing(addr)
 	if tpt == nil {
 		return nil, ErrNoTransport
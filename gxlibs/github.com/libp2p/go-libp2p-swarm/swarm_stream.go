@@ -62,6 +62,7 @@ func (s *Stream) Conn() inet.Conn {
 // Read reads bytes from a stream.
 func (s *Stream) Read(p []byte) (int, error) {
 	n, err := s.stream.Read(p)
+	recvThrottle.wait(n)
 	// TODO: push this down to a lower level for better accuracy.
 	if s.conn.swarm.bwc != nil {
 		s.conn.swarm.bwc.LogRecvMessage(int64(n))
@@ -85,6 +86,7 @@ func (s *Stream) Read(p []byte) (int, error) {
 
 // Write writes bytes to a stream, flushing for each call.
 func (s *Stream) Write(p []byte) (int, error) {
+	sendThrottle.wait(len(p))
 	n, err := s.stream.Write(p)
 	// TODO: push this down to a lower level for better accuracy.
 	if s.conn.swarm.bwc != nil {
@@ -0,0 +1,88 @@
+package swarm
+
+import (
+	"sync"
+	"time"
+)
+
+// Node-wide bandwidth throttles, shared by every stream of every swarm
+// in the process. They are package level (rather than per-swarm) so the
+// limits cover all traffic regardless of how many swarms are running.
+var (
+	sendThrottle = new(throttle)
+	recvThrottle = new(throttle)
+)
+
+// SetBandwidthLimits caps the total upload and download rates of all
+// swarm streams, in bytes per second. A zero (or negative) rate leaves
+// the corresponding direction unlimited. It is safe to call at runtime.
+func SetBandwidthLimits(up, down int64) {
+	sendThrottle.setRate(up)
+	recvThrottle.setRate(down)
+}
+
+// BandwidthLimits returns the current upload and download rate limits
+// in bytes per second; zero means unlimited.
+func BandwidthLimits() (up, down int64) {
+	return sendThrottle.getRate(), recvThrottle.getRate()
+}
+
+// throttle is a token bucket allowing a burst of one second's worth of
+// traffic. Callers that overdraw the bucket sleep off the debt, which
+// keeps the implementation simple and fair enough between streams.
+type throttle struct {
+	mu     sync.Mutex
+	rate   int64 // bytes per second; 0 means unlimited
+	tokens float64
+	last   time.Time
+}
+
+func (t *throttle) setRate(rate int64) {
+	t.mu.Lock()
+	t.rate = rate
+	t.tokens = 0
+	t.last = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *throttle) getRate() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rate < 0 {
+		return 0
+	}
+	return t.rate
+}
+
+// wait accounts for n bytes of traffic and blocks until the configured
+// rate allows them through. With no rate set it returns immediately.
+func (t *throttle) wait(n int) {
+	if n <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	rate := t.rate
+	if rate <= 0 {
+		t.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	t.tokens += now.Sub(t.last).Seconds() * float64(rate)
+	t.last = now
+	if t.tokens > float64(rate) {
+		t.tokens = float64(rate)
+	}
+	t.tokens -= float64(n)
+
+	var sleep time.Duration
+	if t.tokens < 0 {
+		sleep = time.Duration(-t.tokens / float64(rate) * float64(time.Second))
+	}
+	t.mu.Unlock()
+
+	if sleep > 0 {
+		time.Sleep(sleep)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"net"
 
 	ss "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-conn-security"
+	ifconnmgr "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-interface-connmgr"
 	pnet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-interface-pnet"
+	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
 	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
 	transport "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-transport"
 	filter "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-maddr-filter"
@@ -29,6 +31,7 @@ type Upgrader struct {
 	Secure    ss.Transport
 	Muxer     smux.Transport
 	Filters   *filter.Filters
+	Gater     ifconnmgr.ConnGater
 }
 
 // UpgradeListener upgrades the passed multiaddr-net listener into a full libp2p-transport listener.
@@ -69,6 +72,12 @@ func (u *Upgrader) upgrade(ctx context.Context, t transport.Transport, maconn ma
 		return nil, fmt.Errorf("blocked connection from %s", maconn.RemoteMultiaddr())
 	}
 
+	if u.Gater != nil && p == "" && !u.Gater.InterceptAccept(maconn.RemoteMultiaddr()) {
+		log.Debugf("gater refused inbound connection from %s", maconn.RemoteMultiaddr())
+		maconn.Close()
+		return nil, fmt.Errorf("gater refused connection from %s", maconn.RemoteMultiaddr())
+	}
+
 	var conn net.Conn = maconn
 	if u.Protector != nil {
 		pconn, err := u.Protector.Protect(conn)
@@ -87,6 +96,16 @@ func (u *Upgrader) upgrade(ctx context.Context, t transport.Transport, maconn ma
 		conn.Close()
 		return nil, err
 	}
+	if u.Gater != nil {
+		dir := inet.DirOutbound
+		if p == "" {
+			dir = inet.DirInbound
+		}
+		if !u.Gater.InterceptSecured(dir, sconn.RemotePeer(), maconn.RemoteMultiaddr()) {
+			sconn.Close()
+			return nil, fmt.Errorf("gater refused connection with peer %s", sconn.RemotePeer())
+		}
+	}
 	smconn, err := u.setupMuxer(ctx, sconn, p)
 	if err != nil {
 		conn.Close()
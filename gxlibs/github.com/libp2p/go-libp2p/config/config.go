@@ -59,6 +59,7 @@ type Config struct {
 	Filters      *filter.Filters
 
 	ConnManager ifconnmgr.ConnManager
+	ConnGater   ifconnmgr.ConnGater
 	NATManager  NATManagerC
 	Peerstore   pstore.Peerstore
 	Reporter    metrics.Reporter
@@ -108,6 +109,9 @@ func (cfg *Config) NewNode(ctx context.Context) (host.Host, error) {
 	if cfg.Filters != nil {
 		swrm.Filters = cfg.Filters
 	}
+	if cfg.ConnGater != nil {
+		swrm.Gater = cfg.ConnGater
+	}
 
 	var h host.Host
 	h, err = bhost.NewHost(ctx, swrm, &bhost.HostOpts{
@@ -124,6 +128,7 @@ func (cfg *Config) NewNode(ctx context.Context) (host.Host, error) {
 	upgrader := new(tptu.Upgrader)
 	upgrader.Protector = cfg.Protector
 	upgrader.Filters = swrm.Filters
+	upgrader.Gater = cfg.ConnGater
 	if cfg.Insecure {
 		upgrader.Secure = makeInsecureTransport(pid)
 	} else {
@@ -190,6 +190,18 @@ func ConnectionManager(connman ifconnmgr.ConnManager) Option {
 	}
 }
 
+// ConnectionGater configures libp2p to use the given connection gater
+// for admission control of dials and inbound connections.
+func ConnectionGater(g ifconnmgr.ConnGater) Option {
+	return func(cfg *Config) error {
+		if cfg.ConnGater != nil {
+			return fmt.Errorf("cannot specify multiple connection gaters")
+		}
+		cfg.ConnGater = g
+		return nil
+	}
+}
+
 // AddrsFactory configures libp2p to use the given address factory.
 func AddrsFactory(factory config.AddrsFactory) Option {
 	return func(cfg *Config) error {
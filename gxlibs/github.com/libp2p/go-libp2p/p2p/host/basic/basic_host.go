@@ -260,6 +260,11 @@ func (h *BasicHost) newStreamHandler(s inet.Stream) {
 // PushIdentify pushes an identify update through the identify push protocol
 // Warning: this interface is unstable and may disappear in the future.
 func (h *BasicHost) PushIdentify() {
+	if h.ids == nil {
+		// still constructing the host; identify registers its own
+		// handlers before h.ids is set
+		return
+	}
 	h.ids.Push()
 }
 
@@ -299,6 +304,7 @@ func (h *BasicHost) SetStreamHandler(pid protocol.ID, handler inet.StreamHandler
 		handler(is)
 		return nil
 	})
+	h.PushIdentify()
 }
 
 // SetStreamHandlerMatch sets the protocol handler on the Host's Mux
@@ -310,11 +316,13 @@ func (h *BasicHost) SetStreamHandlerMatch(pid protocol.ID, m func(string) bool,
 		handler(is)
 		return nil
 	})
+	h.PushIdentify()
 }
 
 // RemoveStreamHandler returns ..
 func (h *BasicHost) RemoveStreamHandler(pid protocol.ID) {
 	h.Mux().RemoveHandler(string(pid))
+	h.PushIdentify()
 }
 
 // NewStream opens a new stream to given peer p, and writes a p2p/protocol
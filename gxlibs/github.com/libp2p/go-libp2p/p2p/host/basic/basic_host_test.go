@@ -12,6 +12,7 @@ import (
 
 	host "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-host"
 	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
 	pstore "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peerstore"
 	protocol "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-protocol"
 	swarmt "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-swarm/testing"
@@ -111,6 +112,18 @@ func assertWait(t *testing.T, c chan protocol.ID, exp protocol.ID) {
 	}
 }
 
+func waitForProto(t *testing.T, h host.Host, p peer.ID, proto protocol.ID) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		sup, err := h.Peerstore().SupportsProtocols(p, string(proto))
+		if err == nil && len(sup) > 0 {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s to learn about %s", p.Pretty(), proto)
+}
+
 func TestHostProtoPreference(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -131,12 +144,20 @@ func TestHostProtoPreference(t *testing.T) {
 	}
 
 	h1.SetStreamHandler(protoOld, handler)
+	waitForProto(t, h2, h1.ID(), protoOld)
 
 	s, err := h2.NewStream(ctx, h1.ID(), protoMinor, protoNew, protoOld)
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	// force the 'lazy' handshake; h2 knows the protocol via identify
+	// push, so negotiation is deferred until data flows
+	_, err = s.Write([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	assertWait(t, connectedOn, protoOld)
 	s.Close()
 
@@ -147,19 +168,22 @@ func TestHostProtoPreference(t *testing.T) {
 
 	h1.SetStreamHandlerMatch(protoMinor, mfunc, handler)
 
-	// remembered preference will be chosen first, even when the other side newly supports it
+	// registering the handler triggers an identify push, so h2 learns
+	// about the new protocol without reconnecting and prefers it
+	waitForProto(t, h2, h1.ID(), protoMinor)
+
 	s2, err := h2.NewStream(ctx, h1.ID(), protoMinor, protoNew, protoOld)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	// required to force 'lazy' handshake
+	// required to force the lazy handshake
 	_, err = s2.Write([]byte("hello"))
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	assertWait(t, connectedOn, protoOld)
+	assertWait(t, connectedOn, protoMinor)
 
 	s2.Close()
 
@@ -168,6 +192,12 @@ func TestHostProtoPreference(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// required to force the lazy handshake
+	_, err = s3.Write([]byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	assertWait(t, connectedOn, protoMinor)
 	s3.Close()
 }
@@ -76,6 +76,13 @@ func (ps *PingService) Ping(ctx context.Context, p peer.ID) (<-chan time.Duratio
 }
 
 func Ping(ctx context.Context, h host.Host, p peer.ID) (<-chan time.Duration, error) {
+	return PingWithPayload(ctx, h, p, PingSize)
+}
+
+// PingWithPayload is like Ping but sends size-byte payloads instead of
+// the default PingSize. The remote echoes whatever it receives, so any
+// payload size works against existing handlers.
+func PingWithPayload(ctx context.Context, h host.Host, p peer.ID, size int) (<-chan time.Duration, error) {
 	s, err := h.NewStream(ctx, p, ID)
 	if err != nil {
 		return nil, err
@@ -90,7 +97,7 @@ func Ping(ctx context.Context, h host.Host, p peer.ID) (<-chan time.Duration, er
 			case <-ctx.Done():
 				return
 			default:
-				t, err := ping(s)
+				t, err := ping(s, size)
 				if err != nil {
 					log.Debugf("ping error: %s", err)
 					return
@@ -109,8 +116,8 @@ func Ping(ctx context.Context, h host.Host, p peer.ID) (<-chan time.Duration, er
 	return out, nil
 }
 
-func ping(s inet.Stream) (time.Duration, error) {
-	buf := make([]byte, PingSize)
+func ping(s inet.Stream, size int) (time.Duration, error) {
+	buf := make([]byte, size)
 	u.NewTimeSeededRand().Read(buf)
 
 	before := time.Now()
@@ -119,7 +126,7 @@ func ping(s inet.Stream) (time.Duration, error) {
 		return 0, err
 	}
 
-	rbuf := make([]byte, PingSize)
+	rbuf := make([]byte, size)
 	_, err = io.ReadFull(s, rbuf)
 	if err != nil {
 		return 0, err
@@ -0,0 +1,76 @@
+package tcp
+
+import (
+	"context"
+	"net"
+
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+	manet "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr-net"
+	proxy "golang.org/x/net/proxy"
+)
+
+// ProxyDialer, when set, routes every outbound TCP dial through a
+// proxy (e.g. SOCKS5) instead of connecting directly. Name resolution
+// for proxied dials is left to the proxy. Listening is unaffected.
+// Set it before the transport dials.
+var ProxyDialer proxy.Dialer
+
+func (t *TcpTransport) proxyDial(ctx context.Context, raddr ma.Multiaddr) (manet.Conn, error) {
+	network, addr, err := manet.DialArgs(raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	// proxy.Dialer has no context support; dial in the background and
+	// abandon the connection if the context fires first.
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := ProxyDialer.Dial(network, addr)
+		done <- result{conn, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return newProxyConn(r.conn, raddr), nil
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.conn != nil {
+				r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// proxyConn presents a proxied connection under the multiaddr that
+// was dialed; the raw connection's remote address would point at the
+// proxy itself.
+type proxyConn struct {
+	net.Conn
+	local  ma.Multiaddr
+	remote ma.Multiaddr
+}
+
+func newProxyConn(c net.Conn, raddr ma.Multiaddr) manet.Conn {
+	local, err := manet.FromNetAddr(c.LocalAddr())
+	if err != nil {
+		// the proxy protocol may not expose a usable local address
+		local = ma.StringCast("/ip4/0.0.0.0/tcp/0")
+	}
+	return &proxyConn{Conn: c, local: local, remote: raddr}
+}
+
+func (c *proxyConn) LocalMultiaddr() ma.Multiaddr {
+	return c.local
+}
+
+func (c *proxyConn) RemoteMultiaddr() ma.Multiaddr {
+	return c.remote
+}
@@ -60,6 +60,10 @@ func (t *TcpTransport) maDial(ctx context.Context, raddr ma.Multiaddr) (manet.Co
 		}
 	}
 
+	if ProxyDialer != nil {
+		return t.proxyDial(ctx, raddr)
+	}
+
 	if t.UseReuseport() {
 		return t.reuse.DialContext(ctx, raddr)
 	}
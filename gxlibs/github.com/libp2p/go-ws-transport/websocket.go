@@ -15,6 +15,7 @@ import (
 	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
 	manet "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr-net"
 	mafmt "github.com/ipsn/go-ipfs/gxlibs/github.com/whyrusleeping/mafmt"
+	proxy "golang.org/x/net/proxy"
 )
 
 // WsProtocol is the multiaddr protocol definition for this transport.
@@ -35,6 +36,11 @@ var WsCodec = &manet.NetCodec{
 	ParseNetAddr:     ParseWebsocketNetAddr,
 }
 
+// ProxyDialer, when set, routes every outbound websocket dial through
+// a proxy (e.g. SOCKS5) instead of connecting directly. Set it before
+// the transport dials.
+var ProxyDialer proxy.Dialer
+
 // Default gorilla upgrader
 var upgrader = ws.Upgrader{
 	// Allow requests from *all* origins.
@@ -81,7 +87,12 @@ func (t *WebsocketTransport) maDial(ctx context.Context, raddr ma.Multiaddr) (ma
 		return nil, err
 	}
 
-	wscon, _, err := ws.DefaultDialer.Dial(wsurl, nil)
+	dialer := ws.DefaultDialer
+	if ProxyDialer != nil {
+		dialer = &ws.Dialer{NetDial: ProxyDialer.Dial}
+	}
+
+	wscon, _, err := dialer.Dial(wsurl, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,201 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	ci "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-crypto"
+	scrypt "golang.org/x/crypto/scrypt"
+)
+
+// encryptedKeyMagic marks key files written by EncryptedFSKeystore.
+var encryptedKeyMagic = []byte("/ipfs-keystore-encrypted-v1/")
+
+// scrypt parameters used to derive the file encryption key from the
+// keystore password.
+const (
+	scryptN  = 1 << 15
+	scryptR  = 8
+	scryptP  = 1
+	saltSize = 16
+)
+
+// PasswordEnv is the environment variable consulted for the encrypted
+// keystore password before Keystore.PasswordFile is read.
+const PasswordEnv = "IPFS_KEYSTORE_PASSWORD"
+
+// ReadPassword returns the keystore password from the environment, or
+// from the given file when the environment variable is not set.
+func ReadPassword(file string) ([]byte, error) {
+	if pw := os.Getenv(PasswordEnv); pw != "" {
+		return []byte(pw), nil
+	}
+	if file == "" {
+		return nil, fmt.Errorf("keystore password not set: export %s or configure Keystore.PasswordFile", PasswordEnv)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(data), nil
+}
+
+// EncryptedFSKeystore is a keystore backed by password protected files
+// in a given directory. Keys are sealed with AES-256-GCM under a key
+// derived from the password with scrypt; every file carries its own
+// random salt and nonce.
+type EncryptedFSKeystore struct {
+	*FSKeystore
+	password []byte
+}
+
+// NewEncryptedFSKeystore returns a keystore storing encrypted key
+// files in the given directory.
+func NewEncryptedFSKeystore(dir string, password []byte) (*EncryptedFSKeystore, error) {
+	if len(password) == 0 {
+		return nil, fmt.Errorf("encrypted keystore requires a non-empty password")
+	}
+	fsks, err := NewFSKeystore(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedFSKeystore{FSKeystore: fsks, password: password}, nil
+}
+
+// Put stores a key in the Keystore, if a key with the same name already exists, returns ErrKeyExists
+func (ks *EncryptedFSKeystore) Put(name string, k ci.PrivKey) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+
+	b, err := k.Bytes()
+	if err != nil {
+		return err
+	}
+
+	sealed, err := ks.seal(b)
+	if err != nil {
+		return err
+	}
+
+	kp := filepath.Join(ks.dir, name)
+
+	_, err = os.Stat(kp)
+	if err == nil {
+		return ErrKeyExists
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	return ioutil.WriteFile(kp, sealed, 0600)
+}
+
+// Get retrieves a key from the Keystore if it exists, and returns ErrNoSuchKey
+// otherwise.
+func (ks *EncryptedFSKeystore) Get(name string) (ci.PrivKey, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	kp := filepath.Join(ks.dir, name)
+
+	data, err := ioutil.ReadFile(kp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNoSuchKey
+		}
+		return nil, err
+	}
+
+	b, err := ks.open(data)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting key '%s': %s", name, err)
+	}
+
+	return ci.UnmarshalPrivateKey(b)
+}
+
+// seal encrypts key data into the on-disk file format:
+// magic || salt || nonce || ciphertext.
+func (ks *EncryptedFSKeystore) seal(data []byte) ([]byte, error) {
+	return Seal(ks.password, data)
+}
+
+// open decrypts the on-disk file format produced by seal.
+func (ks *EncryptedFSKeystore) open(data []byte) ([]byte, error) {
+	return Open(ks.password, data)
+}
+
+// IsEncrypted reports whether data carries the encrypted key format
+// produced by Seal.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedKeyMagic)
+}
+
+// Seal encrypts key data under the given password into the format used
+// by EncryptedFSKeystore: magic || salt || nonce || ciphertext.
+func Seal(password, data []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := passwordAEAD(password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(encryptedKeyMagic)+len(salt)+len(nonce)+len(data)+aead.Overhead())
+	out = append(out, encryptedKeyMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, data, nil), nil
+}
+
+// Open decrypts the format produced by Seal.
+func Open(password, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, fmt.Errorf("not an encrypted key file; migrate it with 'ipfs key migrate-store'")
+	}
+	data = data[len(encryptedKeyMagic):]
+
+	if len(data) < saltSize {
+		return nil, fmt.Errorf("truncated key file")
+	}
+	salt, data := data[:saltSize], data[saltSize:]
+
+	aead, err := passwordAEAD(password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("truncated key file")
+	}
+	nonce, data := data[:aead.NonceSize()], data[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, data, nil)
+}
+
+func passwordAEAD(password, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(password, salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
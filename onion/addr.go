@@ -0,0 +1,87 @@
+package onion
+
+import (
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+)
+
+// P_ONION3 is the multiaddr protocol code of version 3 onion services.
+// The vendored multiaddr package only knows the deprecated version 2
+// "onion" protocol, so the transport registers this one itself.
+const P_ONION3 = 445
+
+var onion3Protocol = ma.Protocol{
+	Name:       "onion3",
+	Code:       P_ONION3,
+	VCode:      ma.CodeToVarint(P_ONION3),
+	Size:       296, // 35 byte address plus a 16 bit port
+	Transcoder: ma.NewTranscoderFromFunctions(onion3StB, onion3BtS, nil),
+}
+
+func init() {
+	if err := ma.AddProtocol(onion3Protocol); err != nil {
+		panic(fmt.Errorf("error registering onion3 protocol: %s", err))
+	}
+}
+
+// onion3StB parses "<56 base32 chars>:<port>" into its binary form.
+func onion3StB(s string) ([]byte, error) {
+	addr := strings.SplitN(s, ":", 2)
+	if len(addr) != 2 {
+		return nil, fmt.Errorf("failed to parse onion3 addr: %s does not contain a port number", s)
+	}
+
+	// onion3 address without the ".onion" suffix
+	if len(addr[0]) != 56 {
+		return nil, fmt.Errorf("failed to parse onion3 addr: %s not a version 3 onion address", s)
+	}
+	onionHostBytes, err := base32.StdEncoding.DecodeString(strings.ToUpper(addr[0]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base32 onion3 addr: %s %s", s, err)
+	}
+
+	i, err := strconv.Atoi(addr[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse onion3 addr: %s", err)
+	}
+	if i < 1 || i >= 65536 {
+		return nil, fmt.Errorf("failed to parse onion3 addr: port %d out of range", i)
+	}
+
+	onionPortBytes := make([]byte, 2)
+	binary.BigEndian.PutUint16(onionPortBytes, uint16(i))
+	return append(onionHostBytes, onionPortBytes...), nil
+}
+
+// onion3BtS formats the binary form back into "<base32>:<port>".
+func onion3BtS(b []byte) (string, error) {
+	if len(b) != 37 {
+		return "", fmt.Errorf("invalid onion3 address length: %d", len(b))
+	}
+	addr := strings.ToLower(base32.StdEncoding.EncodeToString(b[:35]))
+	port := binary.BigEndian.Uint16(b[35:])
+	return fmt.Sprintf("%s:%d", addr, port), nil
+}
+
+// onionHostPort converts an onion multiaddr into the "<host>.onion:<port>"
+// form the tor socks proxy dials.
+func onionHostPort(a ma.Multiaddr) (string, error) {
+	v, err := a.ValueForProtocol(P_ONION3)
+	if err != nil {
+		v, err = a.ValueForProtocol(ma.P_ONION)
+	}
+	if err != nil {
+		return "", fmt.Errorf("not an onion address: %s", a)
+	}
+
+	parts := strings.SplitN(v, ":", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("malformed onion address: %s", a)
+	}
+	return parts[0] + ".onion:" + parts[1], nil
+}
@@ -0,0 +1,53 @@
+package onion
+
+import (
+	"strings"
+	"testing"
+
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+)
+
+func TestOnion3AddrRoundTrip(t *testing.T) {
+	addr := "/onion3/" + strings.Repeat("a", 56) + ":4003"
+	maddr, err := ma.NewMultiaddr(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maddr.String() != addr {
+		t.Fatalf("round trip changed the address: %s != %s", maddr.String(), addr)
+	}
+
+	hostPort, err := onionHostPort(maddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hostPort != strings.Repeat("a", 56)+".onion:4003" {
+		t.Fatalf("unexpected dial target: %s", hostPort)
+	}
+}
+
+func TestOnion3AddrRejectsMalformed(t *testing.T) {
+	for _, addr := range []string{
+		"/onion3/" + strings.Repeat("a", 55) + ":80", // too short
+		"/onion3/" + strings.Repeat("a", 56) + ":0",  // bad port
+		"/onion3/" + strings.Repeat("a", 56),         // no port
+	} {
+		if _, err := ma.NewMultiaddr(addr); err == nil {
+			t.Fatalf("expected %s to be rejected", addr)
+		}
+	}
+}
+
+func TestOnionListenPort(t *testing.T) {
+	maddr, err := ma.NewMultiaddr("/onion3/" + strings.Repeat("a", 56) + ":4003")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := onionListenPort(maddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port != 4003 {
+		t.Fatalf("unexpected port: %d", port)
+	}
+}
@@ -0,0 +1,102 @@
+package onion
+
+import (
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+)
+
+// controlConn is a minimal client for the parts of the tor control
+// protocol the transport needs: authentication and hidden service
+// publication. See tor's control-spec.txt for the wire format.
+type controlConn struct {
+	conn net.Conn
+	text *textproto.Conn
+}
+
+// dialControl connects and authenticates to a tor control endpoint.
+// An empty password uses null authentication.
+func dialControl(addr, password string) (*controlConn, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to tor control port %s: %s", addr, err)
+	}
+
+	c := &controlConn{conn: conn, text: textproto.NewConn(conn)}
+
+	auth := "AUTHENTICATE"
+	if password != "" {
+		auth = fmt.Sprintf("AUTHENTICATE %q", password)
+	}
+	if _, err := c.cmd(auth); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("tor control authentication failed: %s", err)
+	}
+	return c, nil
+}
+
+// cmd sends one command and collects the reply lines, erroring on any
+// reply code other than 250.
+func (c *controlConn) cmd(line string) ([]string, error) {
+	if err := c.text.PrintfLine("%s", line); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for {
+		reply, err := c.text.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(reply) < 4 {
+			return nil, fmt.Errorf("malformed control reply: %q", reply)
+		}
+		code, sep, rest := reply[:3], reply[3], reply[4:]
+		if code != "250" {
+			return nil, fmt.Errorf("tor control error: %s", reply)
+		}
+		lines = append(lines, rest)
+		if sep == ' ' {
+			return lines, nil
+		}
+	}
+}
+
+// addOnion publishes a hidden service forwarding virtPort to target.
+// keySpec is a tor key blob ("ED25519-V3:..."); when empty a new
+// ed25519 service is generated and its key returned alongside the
+// service id.
+func (c *controlConn) addOnion(keySpec string, virtPort int, target string) (serviceID, newKey string, err error) {
+	if keySpec == "" {
+		keySpec = "NEW:ED25519-V3"
+	}
+
+	lines, err := c.cmd(fmt.Sprintf("ADD_ONION %s Port=%d,%s", keySpec, virtPort, target))
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, l := range lines {
+		if strings.HasPrefix(l, "ServiceID=") {
+			serviceID = strings.TrimPrefix(l, "ServiceID=")
+		}
+		if strings.HasPrefix(l, "PrivateKey=") {
+			newKey = strings.TrimPrefix(l, "PrivateKey=")
+		}
+	}
+	if serviceID == "" {
+		return "", "", fmt.Errorf("tor control reply carried no ServiceID")
+	}
+	return serviceID, newKey, nil
+}
+
+// delOnion takes a hidden service published on this connection down.
+func (c *controlConn) delOnion(serviceID string) error {
+	_, err := c.cmd("DEL_ONION " + serviceID)
+	return err
+}
+
+func (c *controlConn) Close() error {
+	return c.conn.Close()
+}
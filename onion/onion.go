@@ -0,0 +1,258 @@
+// Package onion implements a libp2p transport that speaks to the tor
+// network through a local tor daemon: outbound dials of /onion3/ (and
+// legacy /onion/) multiaddrs go through tor's socks endpoint, and
+// listeners are published as hidden services over the control
+// endpoint. The remote side of a proxied connection only ever sees
+// the onion address, never the node's IP.
+package onion
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+	tpt "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-transport"
+	tptu "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-transport-upgrader"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+	manet "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr-net"
+	proxy "golang.org/x/net/proxy"
+)
+
+// Options configures the tor endpoints the transport talks to.
+type Options struct {
+	// SocksAddress is tor's socks endpoint, used for outbound dials,
+	// e.g. "127.0.0.1:9050".
+	SocksAddress string
+
+	// ControlAddress is tor's control endpoint, used to publish
+	// hidden service listeners, e.g. "127.0.0.1:9051". Leaving it
+	// empty makes the transport dial-only.
+	ControlAddress string
+
+	// ControlPassword authenticates to the control endpoint; empty
+	// uses null authentication.
+	ControlPassword string
+
+	// ServiceKey is the hidden service's private key blob as handed
+	// out by tor ("ED25519-V3:..."). Empty generates a fresh service.
+	ServiceKey string
+
+	// OnNewServiceKey is called with the key of a newly generated
+	// hidden service so the caller can persist it; without that the
+	// onion address changes on every start.
+	OnNewServiceKey func(key string)
+}
+
+// Transport is the onion transport.
+type Transport struct {
+	// Connection upgrader for upgrading insecure stream connections to
+	// secure multiplex connections.
+	Upgrader *tptu.Upgrader
+
+	opts Options
+}
+
+var _ tpt.Transport = (*Transport)(nil)
+
+// New creates an onion transport talking to the given tor endpoints.
+func New(u *tptu.Upgrader, opts Options) *Transport {
+	return &Transport{Upgrader: u, opts: opts}
+}
+
+// CanDial returns true for onion addresses.
+func (t *Transport) CanDial(a ma.Multiaddr) bool {
+	if _, err := a.ValueForProtocol(P_ONION3); err == nil {
+		return true
+	}
+	_, err := a.ValueForProtocol(ma.P_ONION)
+	return err == nil
+}
+
+// Protocols returns the list of terminal protocols this transport can dial.
+func (t *Transport) Protocols() []int {
+	return []int{P_ONION3, ma.P_ONION}
+}
+
+// Proxy always returns false for the onion transport.
+func (t *Transport) Proxy() bool {
+	return false
+}
+
+// Dial dials the peer at the remote onion address through tor.
+func (t *Transport) Dial(ctx context.Context, raddr ma.Multiaddr, p peer.ID) (tpt.Conn, error) {
+	hostPort, err := onionHostPort(raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer, err := proxy.SOCKS5("tcp", t.opts.SocksAddress, nil, proxy.Direct)
+	if err != nil {
+		return nil, fmt.Errorf("configuring tor socks dialer: %s", err)
+	}
+
+	// proxy.Dialer has no context support; dial in the background and
+	// abandon the connection if the context fires first.
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		conn, err := dialer.Dial("tcp", hostPort)
+		done <- result{conn, err}
+	}()
+
+	var conn net.Conn
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, r.err
+		}
+		conn = r.conn
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.conn != nil {
+				r.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+
+	maconn := &onionConn{
+		Conn:   conn,
+		local:  ma.StringCast("/ip4/0.0.0.0/tcp/0"),
+		remote: raddr,
+	}
+	return t.Upgrader.UpgradeOutbound(ctx, t, maconn, p)
+}
+
+// Listen publishes a hidden service forwarding the virtual port of
+// the given onion multiaddr to a local listener. The service id part
+// of laddr is ignored: tor assigns the real onion address, which the
+// returned listener reports.
+func (t *Transport) Listen(laddr ma.Multiaddr) (tpt.Listener, error) {
+	if t.opts.ControlAddress == "" {
+		return nil, fmt.Errorf("cannot listen on %s: no tor control endpoint configured", laddr)
+	}
+
+	virtPort, err := onionListenPort(laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	ctrl, err := dialControl(t.opts.ControlAddress, t.opts.ControlPassword)
+	if err != nil {
+		local.Close()
+		return nil, err
+	}
+
+	serviceID, newKey, err := ctrl.addOnion(t.opts.ServiceKey, virtPort, local.Addr().String())
+	if err != nil {
+		ctrl.Close()
+		local.Close()
+		return nil, fmt.Errorf("publishing hidden service: %s", err)
+	}
+	if newKey != "" && t.opts.OnNewServiceKey != nil {
+		t.opts.OnNewServiceKey(newKey)
+	}
+
+	maddr, err := ma.NewMultiaddr(fmt.Sprintf("/onion3/%s:%d", serviceID, virtPort))
+	if err != nil {
+		ctrl.delOnion(serviceID)
+		ctrl.Close()
+		local.Close()
+		return nil, err
+	}
+
+	list := &onionListener{
+		local:     local,
+		ctrl:      ctrl,
+		serviceID: serviceID,
+		maddr:     maddr,
+	}
+	return t.Upgrader.UpgradeListener(t, list), nil
+}
+
+func (t *Transport) String() string {
+	return "Onion"
+}
+
+// onionListenPort extracts the virtual port from an onion listen addr.
+func onionListenPort(laddr ma.Multiaddr) (int, error) {
+	v, err := laddr.ValueForProtocol(P_ONION3)
+	if err != nil {
+		v, err = laddr.ValueForProtocol(ma.P_ONION)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("not an onion listen address: %s", laddr)
+	}
+
+	parts := strings.SplitN(v, ":", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("malformed onion listen address: %s", laddr)
+	}
+	return strconv.Atoi(parts[1])
+}
+
+// onionConn presents a torified connection under its onion multiaddrs;
+// the raw connection's addresses would point at the tor daemon.
+type onionConn struct {
+	net.Conn
+	local  ma.Multiaddr
+	remote ma.Multiaddr
+}
+
+func (c *onionConn) LocalMultiaddr() ma.Multiaddr {
+	return c.local
+}
+
+func (c *onionConn) RemoteMultiaddr() ma.Multiaddr {
+	return c.remote
+}
+
+// onionListener accepts tor-forwarded connections for a hidden
+// service and tears the service down again on close.
+type onionListener struct {
+	local     net.Listener
+	ctrl      *controlConn
+	serviceID string
+	maddr     ma.Multiaddr
+}
+
+var _ manet.Listener = (*onionListener)(nil)
+
+func (l *onionListener) Accept() (manet.Conn, error) {
+	conn, err := l.local.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	// tor forwards over loopback; the true origin is unknown by design
+	remote, err := manet.FromNetAddr(conn.RemoteAddr())
+	if err != nil {
+		remote = ma.StringCast("/ip4/127.0.0.1/tcp/0")
+	}
+	return &onionConn{Conn: conn, local: l.maddr, remote: remote}, nil
+}
+
+func (l *onionListener) Close() error {
+	l.ctrl.delOnion(l.serviceID)
+	l.ctrl.Close()
+	return l.local.Close()
+}
+
+func (l *onionListener) Multiaddr() ma.Multiaddr {
+	return l.maddr
+}
+
+func (l *onionListener) Addr() net.Addr {
+	return l.local.Addr()
+}
@@ -0,0 +1,89 @@
+package p2p
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+)
+
+// aclTokenMaxLen bounds how many bytes are read when waiting for a
+// token challenge response
+const aclTokenMaxLen = 1024
+
+// ACL controls which remote peers may use a p2p listener. An empty ACL
+// allows everyone.
+type ACL struct {
+	// Allow, when non-empty, only admits the listed peers
+	Allow map[peer.ID]struct{}
+
+	// Deny rejects the listed peers. Ignored for peers in Allow.
+	Deny map[peer.ID]struct{}
+
+	// Token, when set, requires inbound streams to send '<token>\n'
+	// before any payload data
+	Token string
+}
+
+// NewACL creates an ACL from allowed/denied peer lists and an optional
+// challenge token
+func NewACL(allow, deny []peer.ID, token string) *ACL {
+	acl := &ACL{
+		Allow: map[peer.ID]struct{}{},
+		Deny:  map[peer.ID]struct{}{},
+		Token: token,
+	}
+	for _, p := range allow {
+		acl.Allow[p] = struct{}{}
+	}
+	for _, p := range deny {
+		acl.Deny[p] = struct{}{}
+	}
+	return acl
+}
+
+// Allows checks whether peer p passes the allow/deny lists
+func (a *ACL) Allows(p peer.ID) bool {
+	if a == nil {
+		return true
+	}
+
+	if _, ok := a.Allow[p]; ok {
+		return true
+	}
+	if len(a.Allow) > 0 {
+		return false
+	}
+
+	_, denied := a.Deny[p]
+	return !denied
+}
+
+// checkToken reads a newline-terminated token from r and compares it
+// against the expected challenge token
+func (a *ACL) checkToken(r io.Reader) error {
+	if a == nil || a.Token == "" {
+		return nil
+	}
+
+	line := make([]byte, 0, len(a.Token))
+	var b [1]byte
+	for {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return err
+		}
+		if b[0] == '\n' {
+			break
+		}
+		if len(line) >= aclTokenMaxLen {
+			return errors.New("acl token too long")
+		}
+		line = append(line, b[0])
+	}
+
+	if subtle.ConstantTimeCompare(line, []byte(a.Token)) != 1 {
+		return errors.New("acl token mismatch")
+	}
+	return nil
+}
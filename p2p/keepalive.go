@@ -0,0 +1,148 @@
+package p2p
+
+import (
+	"context"
+	"time"
+
+	net "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+	protocol "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-protocol"
+)
+
+const (
+	// forwardBackoffBase is the initial delay between re-dial attempts
+	forwardBackoffBase = time.Second
+
+	// forwardBackoffMax caps the delay between re-dial attempts
+	forwardBackoffMax = time.Minute
+
+	// forwardDialRetries is how many times a failed dial is retried
+	// before the connection attempt is given up
+	forwardDialRetries = 5
+
+	// healthCheckInterval is how often keepalive probes are sent
+	healthCheckInterval = 30 * time.Second
+
+	// forwardEventBacklog is how many unread events are buffered before
+	// new ones are dropped
+	forwardEventBacklog = 64
+)
+
+// ForwardState describes the connection state of a forward target
+type ForwardState string
+
+const (
+	// ForwardConnected is emitted when a stream to the target was opened
+	ForwardConnected ForwardState = "connected"
+	// ForwardRetrying is emitted when a dial failed and will be retried
+	ForwardRetrying ForwardState = "retrying"
+	// ForwardFailed is emitted when a dial was given up on
+	ForwardFailed ForwardState = "failed"
+	// ForwardHealthy is emitted when a keepalive probe succeeds after
+	// the target was unhealthy
+	ForwardHealthy ForwardState = "healthy"
+	// ForwardUnhealthy is emitted when a keepalive probe fails after
+	// the target was healthy
+	ForwardUnhealthy ForwardState = "unhealthy"
+)
+
+// ForwardEvent describes a state change of a forward target
+type ForwardEvent struct {
+	Protocol protocol.ID
+	Peer     peer.ID
+	State    ForwardState
+	Error    string
+	Time     time.Time
+}
+
+// ForwardEvents returns the channel forward state change events are
+// emitted on. Events are dropped when the channel is not drained.
+func (p2p *P2P) ForwardEvents() <-chan ForwardEvent {
+	return p2p.forwardEvents
+}
+
+// emitForwardEvent logs a forward state change and makes it available
+// on the event channel
+func (p2p *P2P) emitForwardEvent(proto protocol.ID, p peer.ID, state ForwardState, err error) {
+	ev := ForwardEvent{
+		Protocol: proto,
+		Peer:     p,
+		State:    state,
+		Time:     time.Now(),
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+
+	log.Infof("p2p forward %s/%s: %s %s", p.Pretty(), proto, state, ev.Error)
+
+	select {
+	case p2p.forwardEvents <- ev:
+	default:
+		// no one is draining events, drop it
+	}
+}
+
+// dialWithBackoff dials the forward target, retrying failed attempts
+// with exponential backoff
+func (l *localListener) dialWithBackoff(ctx context.Context) (net.Stream, error) {
+	backoff := forwardBackoffBase
+	for retries := 0; ; retries++ {
+		remote, err := l.dial(ctx)
+		if err == nil {
+			l.p2p.emitForwardEvent(l.proto, l.peer, ForwardConnected, nil)
+			return remote, nil
+		}
+
+		if retries >= forwardDialRetries {
+			l.p2p.emitForwardEvent(l.proto, l.peer, ForwardFailed, err)
+			return nil, err
+		}
+
+		l.p2p.emitForwardEvent(l.proto, l.peer, ForwardRetrying, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-l.done:
+			return nil, errListenerClosed
+		}
+
+		backoff *= 2
+		if backoff > forwardBackoffMax {
+			backoff = forwardBackoffMax
+		}
+	}
+}
+
+// healthCheck periodically probes the forward target by opening and
+// discarding a stream, emitting events on state transitions
+func (l *localListener) healthCheck() {
+	healthy := true
+
+	for {
+		select {
+		case <-time.After(healthCheckInterval):
+		case <-l.ctx.Done():
+			return
+		case <-l.done:
+			return
+		}
+
+		remote, err := l.dial(l.ctx)
+		if err != nil {
+			if healthy {
+				healthy = false
+				l.p2p.emitForwardEvent(l.proto, l.peer, ForwardUnhealthy, err)
+			}
+			continue
+		}
+		remote.Reset()
+
+		if !healthy {
+			healthy = true
+			l.p2p.emitForwardEvent(l.proto, l.peer, ForwardHealthy, nil)
+		}
+	}
+}
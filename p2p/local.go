@@ -2,6 +2,7 @@ package p2p
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
@@ -12,6 +13,10 @@ import (
 	"github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-protocol"
 )
 
+// errListenerClosed is returned from dial attempts aborted because the
+// listener was closed
+var errListenerClosed = errors.New("listener closed")
+
 // localListener manet streams and proxies them to libp2p services
 type localListener struct {
 	ctx context.Context
@@ -23,15 +28,21 @@ type localListener struct {
 	peer  peer.ID
 
 	listener manet.Listener
+
+	done chan struct{}
 }
 
-// ForwardLocal creates new P2P stream to a remote listener
-func (p2p *P2P) ForwardLocal(ctx context.Context, peer peer.ID, proto protocol.ID, bindAddr ma.Multiaddr) (Listener, error) {
+// ForwardLocal creates new P2P stream to a remote listener. With
+// keepalive set, the target is additionally probed periodically and
+// state changes are emitted as forward events.
+func (p2p *P2P) ForwardLocal(ctx context.Context, peer peer.ID, proto protocol.ID, bindAddr ma.Multiaddr, keepalive bool) (Listener, error) {
 	listener := &localListener{
 		ctx:   ctx,
 		p2p:   p2p,
 		proto: proto,
 		peer:  peer,
+
+		done: make(chan struct{}),
 	}
 
 	maListener, err := manet.Listen(bindAddr)
@@ -48,6 +59,10 @@ func (p2p *P2P) ForwardLocal(ctx context.Context, peer peer.ID, proto protocol.I
 
 	go listener.acceptConns()
 
+	if keepalive {
+		go listener.healthCheck()
+	}
+
 	return listener, nil
 }
 
@@ -73,7 +88,7 @@ func (l *localListener) acceptConns() {
 }
 
 func (l *localListener) setupStream(local manet.Conn) {
-	remote, err := l.dial(l.ctx)
+	remote, err := l.dialWithBackoff(l.ctx)
 	if err != nil {
 		local.Close()
 		log.Warningf("failed to dial to remote %s/%s", l.peer.Pretty(), l.proto)
@@ -97,6 +112,7 @@ func (l *localListener) setupStream(local manet.Conn) {
 }
 
 func (l *localListener) close() {
+	close(l.done)
 	l.listener.Close()
 }
 
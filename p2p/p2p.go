@@ -18,6 +18,8 @@ type P2P struct {
 	identity  peer.ID
 	peerHost  p2phost.Host
 	peerstore pstore.Peerstore
+
+	forwardEvents chan ForwardEvent
 }
 
 // NewP2P creates new P2P struct
@@ -30,6 +32,8 @@ func NewP2P(identity peer.ID, peerHost p2phost.Host, peerstore pstore.Peerstore)
 		ListenersLocal: newListenersLocal(),
 		ListenersP2P:   newListenersP2P(peerHost),
 
+		forwardEvents: make(chan ForwardEvent, forwardEventBacklog),
+
 		Streams: &StreamRegistry{
 			Streams:     map[uint64]*Stream{},
 			ConnManager: peerHost.ConnManager(),
@@ -0,0 +1,85 @@
+package p2p
+
+import (
+	"context"
+
+	ipfsaddr "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-addr"
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+	pstore "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peerstore"
+	protocol "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-protocol"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+)
+
+// SetupPersisted re-establishes the forwards and listeners persisted in
+// cfg. Entries which fail to set up are logged and skipped so a single
+// bad entry does not prevent the rest from coming up.
+func (p2p *P2P) SetupPersisted(ctx context.Context, cfg *config.P2PConfig) {
+	for _, f := range cfg.Forwards {
+		if err := p2p.setupPersistedForward(ctx, f); err != nil {
+			log.Errorf("failed to set up persisted forward %s -> %s: %s", f.ListenAddress, f.TargetAddress, err)
+		}
+	}
+
+	for _, l := range cfg.Listeners {
+		if err := p2p.setupPersistedListener(ctx, l); err != nil {
+			log.Errorf("failed to set up persisted listener %s -> %s: %s", l.Protocol, l.TargetAddress, err)
+		}
+	}
+}
+
+func (p2p *P2P) setupPersistedForward(ctx context.Context, f config.P2PForward) error {
+	listen, err := ma.NewMultiaddr(f.ListenAddress)
+	if err != nil {
+		return err
+	}
+
+	target, err := ipfsaddr.ParseString(f.TargetAddress)
+	if err != nil {
+		return err
+	}
+
+	p2p.peerstore.AddAddr(target.ID(), target.Multiaddr(), pstore.TempAddrTTL)
+
+	if f.UDP {
+		_, err = p2p.ForwardLocalUDP(ctx, target.ID(), protocol.ID(f.Protocol), listen)
+	} else {
+		_, err = p2p.ForwardLocal(ctx, target.ID(), protocol.ID(f.Protocol), listen, f.Keepalive)
+	}
+	return err
+}
+
+func (p2p *P2P) setupPersistedListener(ctx context.Context, l config.P2PListener) error {
+	target, err := ma.NewMultiaddr(l.TargetAddress)
+	if err != nil {
+		return err
+	}
+
+	var acl *ACL
+	if len(l.AllowPeers) > 0 || len(l.DenyPeers) > 0 || l.Token != "" {
+		allow, err := decodePeerList(l.AllowPeers)
+		if err != nil {
+			return err
+		}
+		deny, err := decodePeerList(l.DenyPeers)
+		if err != nil {
+			return err
+		}
+		acl = NewACL(allow, deny, l.Token)
+	}
+
+	_, err = p2p.ForwardRemote(ctx, protocol.ID(l.Protocol), target, l.ReportPeerID, acl)
+	return err
+}
+
+func decodePeerList(list []string) ([]peer.ID, error) {
+	ids := make([]peer.ID, 0, len(list))
+	for _, s := range list {
+		id, err := peer.IDB58Decode(s)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -25,10 +25,13 @@ type remoteListener struct {
 	// reportRemote if set to true makes the handler send '<base58 remote peerid>\n'
 	// to target before any data is forwarded
 	reportRemote bool
+
+	// acl, when set, restricts which remote peers may use this listener
+	acl *ACL
 }
 
 // ForwardRemote creates new p2p listener
-func (p2p *P2P) ForwardRemote(ctx context.Context, proto protocol.ID, addr ma.Multiaddr, reportRemote bool) (Listener, error) {
+func (p2p *P2P) ForwardRemote(ctx context.Context, proto protocol.ID, addr ma.Multiaddr, reportRemote bool, acl *ACL) (Listener, error) {
 	listener := &remoteListener{
 		p2p: p2p,
 
@@ -36,6 +39,8 @@ func (p2p *P2P) ForwardRemote(ctx context.Context, proto protocol.ID, addr ma.Mu
 		addr:  addr,
 
 		reportRemote: reportRemote,
+
+		acl: acl,
 	}
 
 	if err := p2p.ListenersP2P.Register(listener); err != nil {
@@ -46,14 +51,26 @@ func (p2p *P2P) ForwardRemote(ctx context.Context, proto protocol.ID, addr ma.Mu
 }
 
 func (l *remoteListener) handleStream(remote net.Stream) {
+	peer := remote.Conn().RemotePeer()
+
+	if !l.acl.Allows(peer) {
+		log.Warningf("rejected stream from %s on %s: peer not allowed", peer.Pretty(), l.proto)
+		remote.Reset()
+		return
+	}
+
+	if err := l.acl.checkToken(remote); err != nil {
+		log.Warningf("rejected stream from %s on %s: %s", peer.Pretty(), l.proto, err)
+		remote.Reset()
+		return
+	}
+
 	local, err := manet.Dial(l.addr)
 	if err != nil {
 		remote.Reset()
 		return
 	}
 
-	peer := remote.Conn().RemotePeer()
-
 	if l.reportRemote {
 		if _, err := fmt.Fprintf(local, "%s\n", peer.Pretty()); err != nil {
 			remote.Reset()
@@ -77,6 +94,8 @@ func (l *remoteListener) handleStream(remote net.Stream) {
 		Local:  local,
 		Remote: remote,
 
+		udp: isUDPMultiaddr(l.addr),
+
 		Registry: l.p2p.Streams,
 	}
 
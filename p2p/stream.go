@@ -3,6 +3,8 @@ package p2p
 import (
 	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	net "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
 	manet "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr-net"
@@ -27,9 +29,46 @@ type Stream struct {
 	Local  manet.Conn
 	Remote net.Stream
 
+	// udp streams carry length-prefixed datagrams instead of a raw
+	// byte stream
+	udp bool
+
+	// OpenedAt is when the stream was registered
+	OpenedAt time.Time
+
+	bytesIn  uint64
+	bytesOut uint64
+
 	Registry *StreamRegistry
 }
 
+// Peer returns the remote peer on the other end of the stream
+func (s *Stream) Peer() peer.ID {
+	return s.peer
+}
+
+// BytesIn returns how many bytes were received from the remote peer
+func (s *Stream) BytesIn() uint64 {
+	return atomic.LoadUint64(&s.bytesIn)
+}
+
+// BytesOut returns how many bytes were sent to the remote peer
+func (s *Stream) BytesOut() uint64 {
+	return atomic.LoadUint64(&s.bytesOut)
+}
+
+// countingWriter counts bytes passing through it into n
+type countingWriter struct {
+	w io.Writer
+	n *uint64
+}
+
+func (c countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	atomic.AddUint64(c.n, uint64(n))
+	return n, err
+}
+
 // close stream endpoints and deregister it
 func (s *Stream) close() error {
 	s.Registry.Close(s)
@@ -43,8 +82,13 @@ func (s *Stream) reset() error {
 }
 
 func (s *Stream) startStreaming() {
+	if s.udp {
+		s.startStreamingUDP()
+		return
+	}
+
 	go func() {
-		_, err := io.Copy(s.Local, s.Remote)
+		_, err := io.Copy(countingWriter{s.Local, &s.bytesIn}, s.Remote)
 		if err != nil {
 			s.reset()
 		} else {
@@ -53,7 +97,7 @@ func (s *Stream) startStreaming() {
 	}()
 
 	go func() {
-		_, err := io.Copy(s.Remote, s.Local)
+		_, err := io.Copy(countingWriter{s.Remote, &s.bytesOut}, s.Local)
 		if err != nil {
 			s.reset()
 		} else {
@@ -82,6 +126,7 @@ func (r *StreamRegistry) Register(streamInfo *Stream) {
 	r.conns[streamInfo.peer]++
 
 	streamInfo.id = r.nextID
+	streamInfo.OpenedAt = time.Now()
 	r.Streams[r.nextID] = streamInfo
 	r.nextID++
 
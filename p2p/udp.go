@@ -0,0 +1,356 @@
+package p2p
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+	protocol "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-protocol"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+	manet "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr-net"
+	tec "github.com/jbenet/go-temp-err-catcher"
+)
+
+const (
+	// udpMaxDatagramSize is the largest datagram payload carried in a
+	// single frame over a libp2p stream
+	udpMaxDatagramSize = 65507
+
+	// udpSessionTimeout is how long a per-source forwarding stream is
+	// kept open without seeing any traffic
+	udpSessionTimeout = 2 * time.Minute
+
+	// udpSessionBacklog is how many inbound datagrams are buffered per
+	// source before new ones are dropped
+	udpSessionBacklog = 32
+)
+
+// writeUDPFrame writes a single datagram to w, prefixed with its length
+// as a big-endian uint16
+func writeUDPFrame(w io.Writer, b []byte) error {
+	if len(b) > udpMaxDatagramSize {
+		return errors.New("datagram too large")
+	}
+
+	var l [2]byte
+	binary.BigEndian.PutUint16(l[:], uint16(len(b)))
+	if _, err := w.Write(l[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// readUDPFrame reads a single length-prefixed datagram from r into b,
+// returning the datagram length
+func readUDPFrame(r io.Reader, b []byte) (int, error) {
+	var l [2]byte
+	if _, err := io.ReadFull(r, l[:]); err != nil {
+		return 0, err
+	}
+
+	n := int(binary.BigEndian.Uint16(l[:]))
+	if n > len(b) {
+		return 0, errors.New("frame exceeds max datagram size")
+	}
+
+	if _, err := io.ReadFull(r, b[:n]); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// startStreamingUDP relays length-prefixed datagrams between the stream
+// endpoints, preserving datagram boundaries on the UDP side
+func (s *Stream) startStreamingUDP() {
+	go func() {
+		buf := make([]byte, udpMaxDatagramSize)
+		for {
+			n, err := readUDPFrame(s.Remote, buf)
+			if err != nil {
+				if err == io.EOF {
+					s.close()
+				} else {
+					s.reset()
+				}
+				return
+			}
+
+			atomic.AddUint64(&s.bytesIn, uint64(n))
+
+			if _, err := s.Local.Write(buf[:n]); err != nil {
+				s.reset()
+				return
+			}
+		}
+	}()
+
+	go func() {
+		buf := make([]byte, udpMaxDatagramSize)
+		for {
+			n, err := s.Local.Read(buf)
+			if err != nil {
+				if err == io.EOF {
+					s.close()
+				} else {
+					s.reset()
+				}
+				return
+			}
+
+			if err := writeUDPFrame(s.Remote, buf[:n]); err != nil {
+				s.reset()
+				return
+			}
+
+			atomic.AddUint64(&s.bytesOut, uint64(n))
+		}
+	}()
+}
+
+// isUDPMultiaddr checks whether addr describes a UDP endpoint
+func isUDPMultiaddr(addr ma.Multiaddr) bool {
+	_, err := addr.ValueForProtocol(ma.P_UDP)
+	return err == nil
+}
+
+// localUDPListener accepts UDP datagrams and maps them onto per-source
+// libp2p streams to a remote listener
+type localUDPListener struct {
+	ctx context.Context
+
+	p2p *P2P
+
+	proto protocol.ID
+	laddr ma.Multiaddr
+	peer  peer.ID
+
+	conn manet.PacketConn
+
+	mu       sync.Mutex
+	sessions map[string]*udpSession
+}
+
+// ForwardLocalUDP creates a new UDP listener which forwards datagrams to
+// a remote libp2p service, framing them onto one stream per source address
+func (p2p *P2P) ForwardLocalUDP(ctx context.Context, peer peer.ID, proto protocol.ID, bindAddr ma.Multiaddr) (Listener, error) {
+	listener := &localUDPListener{
+		ctx:   ctx,
+		p2p:   p2p,
+		proto: proto,
+		peer:  peer,
+
+		sessions: map[string]*udpSession{},
+	}
+
+	conn, err := manet.ListenPacket(bindAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	listener.conn = conn
+	listener.laddr = conn.Multiaddr()
+
+	if err := p2p.ListenersLocal.Register(listener); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	go listener.acceptDatagrams()
+
+	return listener, nil
+}
+
+func (l *localUDPListener) acceptDatagrams() {
+	buf := make([]byte, udpMaxDatagramSize)
+	for {
+		n, src, err := l.conn.ReadFrom(buf)
+		if err != nil {
+			if tec.ErrIsTemporary(err) {
+				continue
+			}
+			return
+		}
+		if src == nil {
+			continue
+		}
+
+		pkt := make([]byte, n)
+		copy(pkt, buf[:n])
+
+		l.deliver(src, pkt)
+	}
+}
+
+// deliver hands a datagram to the session for its source address,
+// creating the session (and its stream) when seeing the source for
+// the first time
+func (l *localUDPListener) deliver(src ma.Multiaddr, pkt []byte) {
+	key := src.String()
+
+	l.mu.Lock()
+	s, ok := l.sessions[key]
+	if !ok {
+		s = &udpSession{
+			listener: l,
+			raddr:    src,
+
+			incoming: make(chan []byte, udpSessionBacklog),
+			done:     make(chan struct{}),
+		}
+		l.sessions[key] = s
+
+		go l.startSession(s)
+	}
+	l.mu.Unlock()
+
+	select {
+	case s.incoming <- pkt:
+	default:
+		// backlog full, drop the datagram
+	}
+}
+
+func (l *localUDPListener) startSession(s *udpSession) {
+	cctx, cancel := context.WithTimeout(l.ctx, time.Second*30)
+	defer cancel()
+
+	remote, err := l.p2p.peerHost.NewStream(cctx, l.peer, l.proto)
+	if err != nil {
+		log.Warningf("failed to dial to remote %s/%s", l.peer.Pretty(), l.proto)
+		s.Close()
+		return
+	}
+
+	stream := &Stream{
+		Protocol: l.proto,
+
+		OriginAddr: s.raddr,
+		TargetAddr: l.TargetAddress(),
+		peer:       l.peer,
+
+		Local:  s,
+		Remote: remote,
+
+		udp: true,
+
+		Registry: l.p2p.Streams,
+	}
+
+	l.p2p.Streams.Register(stream)
+}
+
+func (l *localUDPListener) removeSession(s *udpSession) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.sessions[s.raddr.String()] == s {
+		delete(l.sessions, s.raddr.String())
+	}
+}
+
+func (l *localUDPListener) close() {
+	l.conn.Close()
+}
+
+func (l *localUDPListener) Protocol() protocol.ID {
+	return l.proto
+}
+
+func (l *localUDPListener) ListenAddress() ma.Multiaddr {
+	return l.laddr
+}
+
+func (l *localUDPListener) TargetAddress() ma.Multiaddr {
+	addr, err := ma.NewMultiaddr(maPrefix + l.peer.Pretty())
+	if err != nil {
+		panic(err)
+	}
+	return addr
+}
+
+func (l *localUDPListener) key() string {
+	return l.ListenAddress().String()
+}
+
+// udpSession exposes the datagrams of a single source address as a
+// manet.Conn, one datagram per Read/Write call
+type udpSession struct {
+	listener *localUDPListener
+	raddr    ma.Multiaddr
+
+	incoming chan []byte
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *udpSession) Read(b []byte) (int, error) {
+	timeout := time.NewTimer(udpSessionTimeout)
+	defer timeout.Stop()
+
+	select {
+	case pkt := <-s.incoming:
+		if len(pkt) > len(b) {
+			return 0, errors.New("read buffer too small for datagram")
+		}
+		return copy(b, pkt), nil
+	case <-timeout.C:
+		return 0, io.EOF
+	case <-s.done:
+		return 0, io.EOF
+	}
+}
+
+func (s *udpSession) Write(b []byte) (int, error) {
+	select {
+	case <-s.done:
+		return 0, errors.New("session closed")
+	default:
+	}
+
+	return s.listener.conn.WriteTo(b, s.raddr)
+}
+
+func (s *udpSession) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.listener.removeSession(s)
+	})
+	return nil
+}
+
+func (s *udpSession) LocalMultiaddr() ma.Multiaddr {
+	return s.listener.laddr
+}
+
+func (s *udpSession) RemoteMultiaddr() ma.Multiaddr {
+	return s.raddr
+}
+
+func (s *udpSession) LocalAddr() net.Addr {
+	return s.listener.conn.Connection().LocalAddr()
+}
+
+func (s *udpSession) RemoteAddr() net.Addr {
+	addr, _ := manet.ToNetAddr(s.raddr)
+	return addr
+}
+
+func (s *udpSession) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (s *udpSession) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (s *udpSession) SetWriteDeadline(t time.Time) error {
+	return nil
+}
@@ -0,0 +1,288 @@
+// Package peering keeps connections to a configured set of peers alive,
+// reconnecting with backoff whenever they drop. Peered nodes are also
+// protected from the connection manager, so they are never trimmed.
+package peering
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	host "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-host"
+	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+	pstore "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peerstore"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+)
+
+var log = logging.Logger("peering")
+
+const (
+	// initialDelay is the wait before the first reconnect attempt after a
+	// drop; each failed attempt then backs off by half until maxDelay.
+	initialDelay = 2 * time.Second
+	maxDelay     = 10 * time.Minute
+)
+
+// peerProtector is the optional connection manager hook used to exempt
+// peered nodes from trimming.
+type peerProtector interface {
+	Protect(peer.ID)
+	Unprotect(peer.ID)
+}
+
+// PeeringService maintains connections to a set of peers, reconnecting
+// whenever they drop.
+type PeeringService struct {
+	host host.Host
+
+	mu      sync.Mutex
+	peers   map[peer.ID]*peerHandler
+	started bool
+}
+
+// NewPeeringService constructs a peering service for the given host. Peers
+// can be added before the service is started, but no connections are made
+// until then.
+func NewPeeringService(host host.Host) *PeeringService {
+	return &PeeringService{
+		host:  host,
+		peers: make(map[peer.ID]*peerHandler),
+	}
+}
+
+// Start begins maintaining connections to the configured peers.
+func (ps *PeeringService) Start() error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ps.started {
+		return errors.New("peering service already started")
+	}
+	ps.started = true
+
+	ps.host.Network().Notify((*netNotifee)(ps))
+	for _, ph := range ps.peers {
+		ph.startIfDisconnected()
+	}
+	return nil
+}
+
+// Stop stops all reconnect attempts. Open connections are left alone but are
+// no longer re-established when they drop.
+func (ps *PeeringService) Stop() error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if !ps.started {
+		return nil
+	}
+	ps.started = false
+
+	ps.host.Network().StopNotify((*netNotifee)(ps))
+	for _, ph := range ps.peers {
+		ph.stop()
+	}
+	return nil
+}
+
+// AddPeer adds a peer to the peering list. If the peer is already on the
+// list, its addresses are replaced with the given ones.
+func (ps *PeeringService) AddPeer(pi pstore.PeerInfo) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ph, ok := ps.peers[pi.ID]; ok {
+		ph.setAddrs(pi.Addrs)
+		return
+	}
+
+	log.Infof("peering with %s", pi.ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ph := &peerHandler{
+		peer:      pi.ID,
+		host:      ps.host,
+		addrs:     pi.Addrs,
+		ctx:       ctx,
+		cancel:    cancel,
+		nextDelay: initialDelay,
+	}
+	ps.peers[pi.ID] = ph
+
+	if pr, ok := ps.host.ConnManager().(peerProtector); ok {
+		pr.Protect(pi.ID)
+	}
+
+	if ps.started {
+		ph.startIfDisconnected()
+	}
+}
+
+// RemovePeer removes a peer from the peering list and lifts its trimming
+// protection. An open connection to the peer is not closed.
+func (ps *PeeringService) RemovePeer(p peer.ID) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ph, ok := ps.peers[p]
+	if !ok {
+		return
+	}
+
+	log.Infof("no longer peering with %s", p)
+	ph.stop()
+	delete(ps.peers, p)
+
+	if pr, ok := ps.host.ConnManager().(peerProtector); ok {
+		pr.Unprotect(p)
+	}
+}
+
+// ListPeers returns the current peering list, sorted by peer ID.
+func (ps *PeeringService) ListPeers() []pstore.PeerInfo {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	out := make([]pstore.PeerInfo, 0, len(ps.peers))
+	for p, ph := range ps.peers {
+		ph.mu.Lock()
+		addrs := append([]ma.Multiaddr(nil), ph.addrs...)
+		ph.mu.Unlock()
+		out = append(out, pstore.PeerInfo{ID: p, Addrs: addrs})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// peerHandler runs the reconnect loop for a single peer.
+type peerHandler struct {
+	peer   peer.ID
+	host   host.Host
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	addrs     []ma.Multiaddr
+	timer     *time.Timer // non-nil while a reconnect loop is running
+	nextDelay time.Duration
+}
+
+func (ph *peerHandler) setAddrs(addrs []ma.Multiaddr) {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	ph.addrs = addrs
+}
+
+// stop shuts the reconnect loop down for good.
+func (ph *peerHandler) stop() {
+	ph.cancel()
+
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+	if ph.timer != nil {
+		ph.timer.Stop()
+		ph.timer = nil
+	}
+}
+
+// startIfDisconnected kicks off the reconnect loop, unless one is already
+// running or we are still connected.
+func (ph *peerHandler) startIfDisconnected() {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	if ph.timer != nil || ph.ctx.Err() != nil {
+		return
+	}
+	if ph.host.Network().Connectedness(ph.peer) == inet.Connected {
+		return
+	}
+
+	ph.nextDelay = initialDelay
+	ph.timer = time.AfterFunc(0, ph.reconnect)
+}
+
+// stopIfConnected ends the reconnect loop once a connection is back.
+func (ph *peerHandler) stopIfConnected() {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	if ph.timer != nil && ph.host.Network().Connectedness(ph.peer) == inet.Connected {
+		ph.timer.Stop()
+		ph.timer = nil
+		ph.nextDelay = initialDelay
+	}
+}
+
+// reconnect makes one connection attempt, rescheduling itself with backoff
+// while the attempts keep failing.
+func (ph *peerHandler) reconnect() {
+	ph.mu.Lock()
+	if ph.timer == nil || ph.ctx.Err() != nil {
+		ph.mu.Unlock()
+		return
+	}
+	addrs := append([]ma.Multiaddr(nil), ph.addrs...)
+	ph.mu.Unlock()
+
+	log.Debugf("reconnecting to %s", ph.peer)
+
+	err := ph.host.Connect(ph.ctx, pstore.PeerInfo{ID: ph.peer, Addrs: addrs})
+	if err == nil {
+		ph.stopIfConnected()
+		return
+	}
+	log.Debugf("failed to reconnect to %s: %s", ph.peer, err)
+
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+	if ph.timer == nil || ph.ctx.Err() != nil {
+		return
+	}
+	if ph.nextDelay < maxDelay {
+		ph.nextDelay += ph.nextDelay / 2
+	}
+	ph.timer = time.AfterFunc(ph.nextDelay, ph.reconnect)
+}
+
+// netNotifee watches connection events so reconnect loops only run while a
+// peer is actually disconnected.
+type netNotifee PeeringService
+
+func (nn *netNotifee) ps() *PeeringService {
+	return (*PeeringService)(nn)
+}
+
+func (nn *netNotifee) Connected(_ inet.Network, c inet.Conn) {
+	ps := nn.ps()
+
+	ps.mu.Lock()
+	ph, ok := ps.peers[c.RemotePeer()]
+	ps.mu.Unlock()
+	if ok {
+		ph.stopIfConnected()
+	}
+}
+
+func (nn *netNotifee) Disconnected(_ inet.Network, c inet.Conn) {
+	ps := nn.ps()
+
+	ps.mu.Lock()
+	ph, ok := ps.peers[c.RemotePeer()]
+	ps.mu.Unlock()
+	if ok {
+		ph.startIfDisconnected()
+	}
+}
+
+func (nn *netNotifee) OpenedStream(inet.Network, inet.Stream) {}
+func (nn *netNotifee) ClosedStream(inet.Network, inet.Stream) {}
+func (nn *netNotifee) Listen(inet.Network, ma.Multiaddr)      {}
+func (nn *netNotifee) ListenClose(inet.Network, ma.Multiaddr) {}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	pin "github.com/ipsn/go-ipfs/pin"
 	bserv "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-blockservice"
@@ -29,16 +30,39 @@ type Result struct {
 	Error      error
 }
 
-// GC performs a mark and sweep garbage collection of the blocks in the blockstore
-// first, it creates a 'marked' set and adds to it the following:
+// Opts bounds an incremental garbage collection run. The zero value
+// imposes no limits and collects everything unreferenced.
+type Opts struct {
+	// MaxDuration ends the sweep once the run has taken this long,
+	// leaving the remaining garbage for a later run. Zero means no
+	// time limit.
+	MaxDuration time.Duration
+
+	// TargetFreeBytes ends the sweep once at least this many bytes
+	// worth of blocks have been removed. Zero means sweep everything.
+	TargetFreeBytes uint64
+}
+
+// GC performs a mark and sweep garbage collection of the blocks in the
+// blockstore, removing everything unreferenced. It is equivalent to
+// GCWithOpts with the zero Opts.
+func GC(ctx context.Context, bs bstore.GCBlockstore, dstor dstore.Datastore, pn pin.Pinner, bestEffortRoots []cid.Cid) <-chan Result {
+	return GCWithOpts(ctx, bs, dstor, pn, bestEffortRoots, Opts{})
+}
+
+// GCWithOpts performs a mark and sweep garbage collection of the blocks in
+// the blockstore. First, it creates a 'marked' set and adds to it the following:
 // - all recursively pinned blocks, plus all of their descendants (recursively)
 // - bestEffortRoots, plus all of its descendants (recursively)
 // - all directly pinned blocks
 // - all blocks utilized internally by the pinner
 //
 // The routine then iterates over every block in the blockstore and
-// deletes any block that is not found in the marked set.
-func GC(ctx context.Context, bs bstore.GCBlockstore, dstor dstore.Datastore, pn pin.Pinner, bestEffortRoots []cid.Cid) <-chan Result {
+// deletes any block that is not found in the marked set, until one of the
+// budgets in opts is exhausted. A budgeted sweep that ends early is not an
+// error; the remaining garbage is picked up by a later run.
+func GCWithOpts(ctx context.Context, bs bstore.GCBlockstore, dstor dstore.Datastore, pn pin.Pinner, bestEffortRoots []cid.Cid, opts Opts) <-chan Result {
+	start := time.Now()
 
 	elock := log.EventBegin(ctx, "GC.lockWait")
 	unlocker := bs.GCLock()
@@ -81,15 +105,27 @@ func GC(ctx context.Context, bs bstore.GCBlockstore, dstor dstore.Datastore, pn
 
 		errors := false
 		var removed uint64
+		var removedBytes uint64
 
 	loop:
 		for {
+			if opts.MaxDuration > 0 && time.Since(start) > opts.MaxDuration {
+				log.Infof("GC time budget exhausted after removing %d blocks, stopping sweep", removed)
+				break loop
+			}
+
 			select {
 			case k, ok := <-keychan:
 				if !ok {
 					break loop
 				}
 				if !gcs.Has(k) {
+					var size int
+					if opts.TargetFreeBytes > 0 {
+						// best effort; an unsized block just doesn't
+						// count towards the target
+						size, _ = bs.GetSize(k)
+					}
 					err := bs.DeleteBlock(k)
 					removed++
 					if err != nil {
@@ -99,11 +135,16 @@ func GC(ctx context.Context, bs bstore.GCBlockstore, dstor dstore.Datastore, pn
 						// continue as error is non-fatal
 						continue loop
 					}
+					removedBytes += uint64(size)
 					select {
 					case output <- Result{KeyRemoved: k}:
 					case <-ctx.Done():
 						break loop
 					}
+					if opts.TargetFreeBytes > 0 && removedBytes >= opts.TargetFreeBytes {
+						log.Infof("GC reached its target of %d free bytes, stopping sweep", opts.TargetFreeBytes)
+						break loop
+					}
 				}
 			case <-ctx.Done():
 				break loop
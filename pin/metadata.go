@@ -0,0 +1,67 @@
+package pin
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	ds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore"
+)
+
+// pinMetaPrefix namespaces the per-pin metadata records in the datastore.
+var pinMetaPrefix = ds.NewKey("/local/pins/meta")
+
+// Meta holds the optional user-supplied metadata of a pin: a short name,
+// arbitrary key/value labels and the time the pin was created. It is stored
+// in the datastore next to the pin sets, keyed by the pinned cid, and is
+// removed together with the pin.
+type Meta struct {
+	Name      string            `json:",omitempty"`
+	Labels    map[string]string `json:",omitempty"`
+	CreatedAt time.Time
+}
+
+func pinMetaKey(c cid.Cid) ds.Key {
+	return pinMetaPrefix.ChildString(c.String())
+}
+
+// SetMeta attaches metadata to the pin of the given cid, replacing any
+// previous record. A zero CreatedAt is filled in with the current time.
+func (p *pinner) SetMeta(c cid.Cid, meta *Meta) error {
+	if meta.CreatedAt.IsZero() {
+		meta.CreatedAt = time.Now()
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := p.dstore.Put(pinMetaKey(c), data); err != nil {
+		return fmt.Errorf("cannot store pin metadata: %v", err)
+	}
+	return nil
+}
+
+// GetMeta returns the metadata attached to the pin of the given cid, or
+// nil if the pin has none.
+func (p *pinner) GetMeta(c cid.Cid) (*Meta, error) {
+	data, err := p.dstore.Get(pinMetaKey(c))
+	if err == ds.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	meta := new(Meta)
+	if err := json.Unmarshal(data, meta); err != nil {
+		return nil, fmt.Errorf("cannot decode pin metadata: %v", err)
+	}
+	return meta, nil
+}
+
+// removeMeta drops the metadata record of the given cid, if any.
+func (p *pinner) removeMeta(c cid.Cid) {
+	if err := p.dstore.Delete(pinMetaKey(c)); err != nil && err != ds.ErrNotFound {
+		log.Warningf("cannot remove pin metadata for %s: %s", c, err)
+	}
+}
@@ -137,6 +137,14 @@ type Pinner interface {
 	// be successful.
 	RemovePinWithMode(cid.Cid, Mode)
 
+	// SetMeta attaches a name, labels and a creation time to the pin of
+	// the given cid, replacing any previous metadata.
+	SetMeta(cid.Cid, *Meta) error
+
+	// GetMeta returns the metadata attached to the pin of the given cid,
+	// or nil if the pin has none.
+	GetMeta(cid.Cid) (*Meta, error)
+
 	// Flush writes the pin state to the backing datastore
 	Flush() error
 
@@ -280,11 +288,13 @@ func (p *pinner) Unpin(ctx context.Context, c cid.Cid, recursive bool) error {
 	case "recursive":
 		if recursive {
 			p.recursePin.Remove(c)
+			p.removeMeta(c)
 			return nil
 		}
 		return fmt.Errorf("%s is pinned recursively", c)
 	case "direct":
 		p.directPin.Remove(c)
+		p.removeMeta(c)
 		return nil
 	default:
 		return fmt.Errorf("%s is pinned indirectly under %s", c, reason)
@@ -0,0 +1,178 @@
+package pin
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	bstore "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-blockstore"
+	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
+	mdag "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-merkledag"
+)
+
+// States a queued pin request moves through.
+const (
+	StateQueued  = "queued"
+	StatePinning = "pinning"
+	StatePinned  = "pinned"
+	StateFailed  = "failed"
+)
+
+// pinQueueSize bounds how many requests may wait for the pin worker.
+const pinQueueSize = 128
+
+// Request describes one asynchronous pin request tracked by the Queue.
+// Progress is the number of blocks fetched so far; the total is unknown
+// until the graph has been walked.
+type Request struct {
+	ID        string
+	Cid       cid.Cid
+	Recursive bool
+	State     string
+	Progress  int
+	Error     string `json:",omitempty"`
+	Created   time.Time
+
+	tracker *mdag.ProgressTracker
+}
+
+// Queue pins cids in the background. Requests are processed one at a
+// time in submission order and tracked in memory only: the queue does
+// not survive a daemon restart.
+type Queue struct {
+	ctx    context.Context
+	pinner Pinner
+	dserv  ipld.DAGService
+	locker bstore.GCLocker
+
+	lock    sync.Mutex
+	reqs    map[string]*Request
+	order   []string
+	pending chan *Request
+}
+
+// NewQueue creates a pin queue and starts its worker. The worker stops
+// when the given context is cancelled.
+func NewQueue(ctx context.Context, pinner Pinner, dserv ipld.DAGService, locker bstore.GCLocker) *Queue {
+	q := &Queue{
+		ctx:     ctx,
+		pinner:  pinner,
+		dserv:   dserv,
+		locker:  locker,
+		reqs:    make(map[string]*Request),
+		pending: make(chan *Request, pinQueueSize),
+	}
+	go q.worker()
+	return q
+}
+
+// Add schedules the given cid to be pinned in the background and
+// returns the id under which the request can be tracked.
+func (q *Queue) Add(c cid.Cid, recursive bool) (Request, error) {
+	idb := make([]byte, 8)
+	if _, err := crand.Read(idb); err != nil {
+		return Request{}, err
+	}
+
+	r := &Request{
+		ID:        hex.EncodeToString(idb),
+		Cid:       c,
+		Recursive: recursive,
+		State:     StateQueued,
+		Created:   time.Now(),
+		tracker:   new(mdag.ProgressTracker),
+	}
+
+	select {
+	case q.pending <- r:
+	default:
+		return Request{}, fmt.Errorf("pin queue is full (%d requests pending)", pinQueueSize)
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.reqs[r.ID] = r
+	q.order = append(q.order, r.ID)
+
+	return q.statusLocked(r), nil
+}
+
+// Get returns the current status of the request with the given id.
+func (q *Queue) Get(id string) (Request, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	r, ok := q.reqs[id]
+	if !ok {
+		return Request{}, false
+	}
+	return q.statusLocked(r), true
+}
+
+// List returns the status of all known requests in submission order.
+func (q *Queue) List() []Request {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	out := make([]Request, 0, len(q.order))
+	for _, id := range q.order {
+		out = append(out, q.statusLocked(q.reqs[id]))
+	}
+	return out
+}
+
+// statusLocked snapshots a request; the queue lock must be held.
+func (q *Queue) statusLocked(r *Request) Request {
+	cp := *r
+	cp.Progress = r.tracker.Value()
+	cp.tracker = nil
+	return cp
+}
+
+func (q *Queue) setState(r *Request, state string, err error) {
+	q.lock.Lock()
+	r.State = state
+	if err != nil {
+		r.Error = err.Error()
+	}
+	q.lock.Unlock()
+}
+
+func (q *Queue) worker() {
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case r := <-q.pending:
+			q.process(r)
+		}
+	}
+}
+
+func (q *Queue) process(r *Request) {
+	q.setState(r, StatePinning, nil)
+
+	ctx := r.tracker.DeriveContext(q.ctx)
+	err := func() error {
+		nd, err := q.dserv.Get(ctx, r.Cid)
+		if err != nil {
+			return err
+		}
+
+		unlocker := q.locker.PinLock()
+		defer unlocker.Unlock()
+
+		if err := q.pinner.Pin(ctx, nd, r.Recursive); err != nil {
+			return err
+		}
+		return q.pinner.Flush()
+	}()
+
+	if err != nil {
+		q.setState(r, StateFailed, err)
+		return
+	}
+	q.setState(r, StatePinned, nil)
+}
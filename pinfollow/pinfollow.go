@@ -0,0 +1,319 @@
+// Package pinfollow mirrors another peer's published pinset. A
+// followed peer publishes a pinset document under its IPNS name; the
+// IPNS record carries the peer's signature, so the document is
+// authenticated without any extra key exchange. The follower
+// periodically resolves each followed name and pins or unpins to
+// match, giving small replication setups cluster-like behavior
+// without a cluster deployment.
+package pinfollow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	pin "github.com/ipsn/go-ipfs/pin"
+
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	ds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore"
+	dsquery "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-datastore/query"
+	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
+	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	uio "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-unixfs/io"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+)
+
+var log = logging.Logger("pinfollow")
+
+const (
+	// syncInterval is how often followed pinsets are re-resolved.
+	syncInterval = 5 * time.Minute
+
+	// maxPinsetSize bounds the pinset document size.
+	maxPinsetSize = 16 << 20
+
+	// followPrefix is the datastore namespace holding follow records.
+	followPrefix = "/pinfollow"
+)
+
+// ResolveFunc resolves an IPNS path to the node it points at.
+type ResolveFunc func(ctx context.Context, p string) (ipld.Node, error)
+
+// PinsetDoc is the document a followed peer publishes under its IPNS
+// name: a JSON object listing the cids to mirror.
+type PinsetDoc struct {
+	Pins []string
+}
+
+// Follow is the persisted state of one followed peer.
+type Follow struct {
+	Peer      string
+	Added     time.Time
+	LastSync  time.Time
+	LastError string
+	Pins      []string // cids currently mirrored for this peer
+}
+
+// Service mirrors the pinsets of followed peers.
+type Service struct {
+	resolve ResolveFunc
+	pinner  pin.Pinner
+	dag     ipld.DAGService
+	ds      ds.Datastore
+
+	mu      sync.Mutex
+	follows map[string]*Follow
+}
+
+// New creates a follower service, restoring persisted follows.
+func New(resolve ResolveFunc, pinner pin.Pinner, dag ipld.DAGService, dstore ds.Datastore) (*Service, error) {
+	s := &Service{
+		resolve: resolve,
+		pinner:  pinner,
+		dag:     dag,
+		ds:      dstore,
+		follows: make(map[string]*Follow),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Run syncs followed pinsets until the context is cancelled. The
+// first sync happens immediately.
+func (s *Service) Run(ctx context.Context) {
+	for {
+		s.syncAll(ctx)
+
+		select {
+		case <-time.After(syncInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Add starts following a peer's pinset and syncs it right away.
+func (s *Service) Add(ctx context.Context, p peer.ID) (*Follow, error) {
+	key := p.Pretty()
+
+	s.mu.Lock()
+	if _, ok := s.follows[key]; ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("already following pinset of %s", key)
+	}
+	f := &Follow{Peer: key, Added: time.Now()}
+	s.follows[key] = f
+	s.mu.Unlock()
+
+	if err := s.persist(f); err != nil {
+		return nil, err
+	}
+
+	if err := s.syncPeer(ctx, f); err != nil {
+		// keep the follow; the peer may simply not have published yet
+		log.Warningf("initial pinset sync of %s failed: %s", key, err)
+	}
+
+	s.mu.Lock()
+	out := *f
+	s.mu.Unlock()
+	return &out, nil
+}
+
+// List returns the followed peers, sorted by peer ID.
+func (s *Service) List() []Follow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Follow, 0, len(s.follows))
+	for _, f := range s.follows {
+		out = append(out, *f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Peer < out[j].Peer })
+	return out
+}
+
+// Remove stops following a peer and unpins everything mirrored on its
+// behalf.
+func (s *Service) Remove(ctx context.Context, p peer.ID) error {
+	key := p.Pretty()
+
+	s.mu.Lock()
+	f, ok := s.follows[key]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("not following pinset of %s", key)
+	}
+	delete(s.follows, key)
+	s.mu.Unlock()
+
+	for _, cs := range f.Pins {
+		c, err := cid.Decode(cs)
+		if err != nil {
+			continue
+		}
+		if err := s.pinner.Unpin(ctx, c, true); err != nil && err != pin.ErrNotPinned {
+			log.Errorf("unpinning %s of removed follow %s: %s", c, key, err)
+		}
+	}
+	if err := s.pinner.Flush(); err != nil {
+		return err
+	}
+
+	return s.ds.Delete(followKey(key))
+}
+
+func (s *Service) syncAll(ctx context.Context) {
+	s.mu.Lock()
+	follows := make([]*Follow, 0, len(s.follows))
+	for _, f := range s.follows {
+		follows = append(follows, f)
+	}
+	s.mu.Unlock()
+
+	for _, f := range follows {
+		if err := s.syncPeer(ctx, f); err != nil {
+			log.Errorf("syncing pinset of %s: %s", f.Peer, err)
+		}
+	}
+}
+
+// syncPeer resolves a followed peer's pinset document and pins or
+// unpins to match it.
+func (s *Service) syncPeer(ctx context.Context, f *Follow) error {
+	doc, err := s.fetchPinset(ctx, f.Peer)
+
+	s.mu.Lock()
+	if err != nil {
+		f.LastError = err.Error()
+		s.mu.Unlock()
+		s.persist(f)
+		return err
+	}
+	f.LastError = ""
+	f.LastSync = time.Now()
+	have := make(map[string]bool, len(f.Pins))
+	for _, cs := range f.Pins {
+		have[cs] = true
+	}
+	s.mu.Unlock()
+
+	want := make(map[string]cid.Cid, len(doc.Pins))
+	for _, cs := range doc.Pins {
+		c, err := cid.Decode(cs)
+		if err != nil {
+			log.Warningf("pinset of %s lists invalid cid %q: %s", f.Peer, cs, err)
+			continue
+		}
+		want[c.String()] = c
+	}
+
+	var mirrored []string
+	for key, c := range want {
+		if have[key] {
+			mirrored = append(mirrored, key)
+			continue
+		}
+		nd, err := s.dag.Get(ctx, c)
+		if err != nil {
+			log.Errorf("fetching %s from pinset of %s: %s", c, f.Peer, err)
+			continue
+		}
+		if err := s.pinner.Pin(ctx, nd, true); err != nil {
+			log.Errorf("pinning %s from pinset of %s: %s", c, f.Peer, err)
+			continue
+		}
+		mirrored = append(mirrored, key)
+	}
+
+	for key := range have {
+		if _, ok := want[key]; ok {
+			continue
+		}
+		c, err := cid.Decode(key)
+		if err != nil {
+			continue
+		}
+		if err := s.pinner.Unpin(ctx, c, true); err != nil && err != pin.ErrNotPinned {
+			log.Errorf("unpinning %s dropped from pinset of %s: %s", c, f.Peer, err)
+			mirrored = append(mirrored, key)
+		}
+	}
+
+	if err := s.pinner.Flush(); err != nil {
+		return err
+	}
+
+	sort.Strings(mirrored)
+	s.mu.Lock()
+	f.Pins = mirrored
+	s.mu.Unlock()
+
+	return s.persist(f)
+}
+
+// fetchPinset resolves the peer's IPNS name and decodes the pinset
+// document it points at.
+func (s *Service) fetchPinset(ctx context.Context, p string) (*PinsetDoc, error) {
+	nd, err := s.resolve(ctx, "/ipns/"+p)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := uio.NewDagReader(ctx, nd, s.dag)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxPinsetSize))
+	if err != nil {
+		return nil, err
+	}
+
+	var doc PinsetDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid pinset document: %s", err)
+	}
+	return &doc, nil
+}
+
+func (s *Service) persist(f *Follow) error {
+	s.mu.Lock()
+	data, err := json.Marshal(f)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.ds.Put(followKey(f.Peer), data)
+}
+
+func (s *Service) load() error {
+	res, err := s.ds.Query(dsquery.Query{Prefix: followPrefix})
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	for e := range res.Next() {
+		if e.Error != nil {
+			return e.Error
+		}
+		var f Follow
+		if err := json.Unmarshal(e.Value, &f); err != nil {
+			log.Warningf("dropping corrupt follow record %s", e.Key)
+			continue
+		}
+		s.follows[f.Peer] = &f
+	}
+	return nil
+}
+
+func followKey(p string) ds.Key {
+	return ds.NewKey(followPrefix + "/" + p)
+}
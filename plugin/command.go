@@ -0,0 +1,14 @@
+package plugin
+
+import (
+	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+)
+
+// PluginCommands is an interface for plugins that mount additional
+// command trees under the daemon's command root. Names clashing with
+// built-in commands are rejected at daemon startup.
+type PluginCommands interface {
+	Plugin
+
+	Commands() map[string]*cmds.Command
+}
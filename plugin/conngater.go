@@ -0,0 +1,17 @@
+package plugin
+
+import (
+	ifconnmgr "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-interface-connmgr"
+)
+
+// PluginConnGater is an interface for plugins that gate libp2p
+// connections, allowing or denying them by peer id, multiaddr and
+// direction before the handshake completes. When several gater
+// plugins are loaded a connection is admitted only if every one of
+// them allows it.
+type PluginConnGater interface {
+	Plugin
+
+	// ConnGater returns the gater consulted for every connection.
+	ConnGater() ifconnmgr.ConnGater
+}
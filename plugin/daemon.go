@@ -0,0 +1,17 @@
+package plugin
+
+import (
+	"github.com/ipsn/go-ipfs/core"
+)
+
+// PluginDaemon is an interface for plugins that run as part of the
+// daemon. They are started in load order once the node is up, receiving
+// the node so they can register libp2p protocol handlers or spawn
+// background services on it, and are closed in reverse order on
+// shutdown.
+type PluginDaemon interface {
+	Plugin
+
+	Start(node *core.IpfsNode) error
+	Close() error
+}
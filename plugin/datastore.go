@@ -4,11 +4,19 @@ import (
 	"github.com/ipsn/go-ipfs/repo/fsrepo"
 )
 
-// PluginDatastore is an interface that can be implemented to add handlers for
-// for different datastores
+// PluginDatastore is an interface that can be implemented to add support
+// for different datastore backends. The loader registers the parser with
+// fsrepo, which resolves it from the "type" field of the repo datastore
+// spec when the repo is opened, so third-party backends need no changes
+// to the repo package itself.
 type PluginDatastore interface {
 	Plugin
 
+	// DatastoreTypeName returns the "type" value in datastore specs
+	// this plugin handles.
 	DatastoreTypeName() string
+
+	// DatastoreConfigParser returns the function turning a datastore
+	// spec into a datastore configuration.
 	DatastoreConfigParser() fsrepo.ConfigFromMap
 }
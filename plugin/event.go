@@ -0,0 +1,18 @@
+package plugin
+
+// PluginEvent is an interface that can be implemented to observe daemon
+// events, so external observability or accounting plugins can consume
+// them without core changes. Events currently emitted:
+//
+//	node/start       - the daemon started serving
+//	node/shutdown    - the daemon is shutting down
+//	bitswap/stats    - periodic bitswap counters (blocks and bytes moved)
+//	gateway/request  - a gateway request finished (method, path, status)
+//
+// Handlers are called synchronously on the emitting goroutine and must
+// not block.
+type PluginEvent interface {
+	Plugin
+
+	HandleEvent(name string, data map[string]interface{})
+}
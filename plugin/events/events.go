@@ -0,0 +1,42 @@
+// Package events dispatches daemon events to event plugins. It lives in
+// its own package so core packages can emit events without importing
+// the plugin loader.
+package events
+
+import (
+	"sync"
+)
+
+// Handler consumes a single daemon event. Handlers must not block; slow
+// consumers should buffer internally.
+type Handler func(name string, data map[string]interface{})
+
+var (
+	mu       sync.RWMutex
+	handlers []Handler
+)
+
+// Register adds a handler for all subsequently emitted events.
+func Register(h Handler) {
+	mu.Lock()
+	handlers = append(handlers, h)
+	mu.Unlock()
+}
+
+// Emit delivers an event to every registered handler. It is a no-op
+// when no event plugins are loaded.
+func Emit(name string, data map[string]interface{}) {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, h := range handlers {
+		h(name, data)
+	}
+}
+
+// Active reports whether any handler is registered, letting emitters
+// skip building event data nobody will consume.
+func Active() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(handlers) > 0
+}
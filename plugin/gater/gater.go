@@ -0,0 +1,75 @@
+// Package gater collects connection gaters contributed by plugins. It
+// lives in its own package so the node constructor can pick them up
+// without importing the plugin loader.
+package gater
+
+import (
+	"sync"
+
+	ifconnmgr "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-interface-connmgr"
+	inet "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-net"
+	peer "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-peer"
+	ma "github.com/ipsn/go-ipfs/gxlibs/github.com/multiformats/go-multiaddr"
+)
+
+var (
+	mu     sync.RWMutex
+	gaters []ifconnmgr.ConnGater
+)
+
+// Register adds a gater consulted for all subsequent connections.
+func Register(g ifconnmgr.ConnGater) {
+	mu.Lock()
+	gaters = append(gaters, g)
+	mu.Unlock()
+}
+
+// Gater returns the combined registered gaters, or nil when none are
+// registered. A connection is admitted only if every gater allows it.
+func Gater() ifconnmgr.ConnGater {
+	mu.RLock()
+	defer mu.RUnlock()
+	if len(gaters) == 0 {
+		return nil
+	}
+	return multiGater(append([]ifconnmgr.ConnGater(nil), gaters...))
+}
+
+// multiGater combines gaters with and-semantics: the first deny wins.
+type multiGater []ifconnmgr.ConnGater
+
+func (mg multiGater) InterceptPeerDial(p peer.ID) bool {
+	for _, g := range mg {
+		if !g.InterceptPeerDial(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func (mg multiGater) InterceptAddrDial(p peer.ID, addr ma.Multiaddr) bool {
+	for _, g := range mg {
+		if !g.InterceptAddrDial(p, addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (mg multiGater) InterceptAccept(raddr ma.Multiaddr) bool {
+	for _, g := range mg {
+		if !g.InterceptAccept(raddr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (mg multiGater) InterceptSecured(dir inet.Direction, p peer.ID, raddr ma.Multiaddr) bool {
+	for _, g := range mg {
+		if !g.InterceptSecured(dir, p, raddr) {
+			return false
+		}
+	}
+	return true
+}
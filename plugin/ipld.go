@@ -14,3 +14,13 @@ type PluginIPLD interface {
 	RegisterBlockDecoders(dec ipld.BlockDecoder) error
 	RegisterInputEncParsers(iec coredag.InputEncParsers) error
 }
+
+// PluginIPLDCodec is an optional extension of PluginIPLD for plugins
+// whose format introduces new codecs. Returned codecs are added to the
+// cid codec tables, making their names usable wherever codecs are
+// looked up by name (e.g. 'dag put --format', 'cid format --codec').
+type PluginIPLDCodec interface {
+	PluginIPLD
+
+	Codecs() map[string]uint64
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"plugin"
+	"strings"
 
 	iplugin "github.com/ipsn/go-ipfs/plugin"
 )
@@ -30,6 +31,11 @@ func linuxLoadFunc(pluginDir string) ([]iplugin.Plugin, error) {
 			return nil
 		}
 
+		if strings.HasSuffix(fi, ".sig") {
+			// detached plugin signature, checked alongside its plugin
+			return nil
+		}
+
 		if info.Mode().Perm()&0111 == 0 {
 			// file is not executable let's not load it
 			// this is to prevent loading plugins from for example non-executable
@@ -38,6 +44,10 @@ func linuxLoadFunc(pluginDir string) ([]iplugin.Plugin, error) {
 			return nil
 		}
 
+		if err := verifyPlugin(fi); err != nil {
+			return err
+		}
+
 		if newPlugins, err := loadPlugin(fi); err == nil {
 			plugins = append(plugins, newPlugins...)
 		} else {
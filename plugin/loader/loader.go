@@ -2,12 +2,23 @@ package loader
 
 import (
 	"fmt"
+
+	"github.com/ipsn/go-ipfs/core"
 	"github.com/ipsn/go-ipfs/core/coredag"
 	"github.com/ipsn/go-ipfs/plugin"
+	"github.com/ipsn/go-ipfs/plugin/events"
+	"github.com/ipsn/go-ipfs/plugin/gater"
 	"github.com/ipsn/go-ipfs/repo/fsrepo"
+	"encoding/base64"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	opentracing "github.com/opentracing/opentracing-go"
+	cid "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-cid"
+	cmds "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-cmds"
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+	ic "github.com/ipsn/go-ipfs/gxlibs/github.com/libp2p/go-libp2p-crypto"
 	ipld "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipld-format"
 	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
 )
@@ -21,10 +32,16 @@ var loadPluginsFunc = func(string) ([]plugin.Plugin, error) {
 // PluginLoader keeps track of loaded plugins
 type PluginLoader struct {
 	plugins []plugin.Plugin
+	started []plugin.PluginDaemon
 }
 
-// NewPluginLoader creates new plugin loader
-func NewPluginLoader(pluginDir string) (*PluginLoader, error) {
+// NewPluginLoader creates new plugin loader. pcfg controls signature
+// verification of plugins loaded from pluginDir.
+func NewPluginLoader(pluginDir string, pcfg config.Plugins) (*PluginLoader, error) {
+	if err := setupVerification(pcfg); err != nil {
+		return nil, err
+	}
+
 	plMap := make(map[string]plugin.Plugin)
 	for _, v := range preloadPlugins {
 		plMap[v.Name()] = v
@@ -95,13 +112,21 @@ func (loader *PluginLoader) Run() error {
 			if err != nil {
 				return err
 			}
+		case plugin.PluginEvent:
+			events.Register(pl.HandleEvent)
+		case plugin.PluginConnGater:
+			gater.Register(pl.ConnGater())
+		case plugin.PluginDaemon, plugin.PluginCommands:
+			// started by the daemon once the node is constructed
 		case plugin.PluginDatastore:
 			err := fsrepo.AddDatastoreConfigHandler(pl.DatastoreTypeName(), pl.DatastoreConfigParser())
 			if err != nil {
 				return err
 			}
 		default:
-			panic(pl)
+			// dynamically loaded plugins may be built against a newer
+			// plugin interface; don't take the daemon down with them
+			return fmt.Errorf("unknown plugin type: %T", pl)
 		}
 	}
 	return nil
@@ -112,7 +137,32 @@ func runIPLDPlugin(pl plugin.PluginIPLD) error {
 	if err != nil {
 		return err
 	}
-	return pl.RegisterInputEncParsers(coredag.DefaultInputEncParsers)
+	if err := pl.RegisterInputEncParsers(coredag.DefaultInputEncParsers); err != nil {
+		return err
+	}
+
+	if cpl, ok := pl.(plugin.PluginIPLDCodec); ok {
+		if err := registerCodecs(pl.Name(), cpl.Codecs()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerCodecs adds a plugin's codec names to the cid codec tables,
+// refusing to silently remap names or codes that are already taken.
+func registerCodecs(name string, codecs map[string]uint64) error {
+	for codecName, code := range codecs {
+		if existing, ok := cid.Codecs[codecName]; ok && existing != code {
+			return fmt.Errorf("plugin %s: codec %q already registered as 0x%x", name, codecName, existing)
+		}
+		if existing, ok := cid.CodecToStr[code]; ok && existing != codecName {
+			return fmt.Errorf("plugin %s: codec 0x%x already registered as %q", name, code, existing)
+		}
+		cid.Codecs[codecName] = code
+		cid.CodecToStr[code] = codecName
+	}
+	return nil
 }
 
 func runTracerPlugin(pl plugin.PluginTracer) error {
@@ -123,3 +173,115 @@ func runTracerPlugin(pl plugin.PluginTracer) error {
 	opentracing.SetGlobalTracer(tracer)
 	return nil
 }
+
+// Commands collects the command trees provided by PluginCommands
+// plugins, erroring on duplicate names between plugins.
+func (loader *PluginLoader) Commands() (map[string]*cmds.Command, error) {
+	out := make(map[string]*cmds.Command)
+	for _, pl := range loader.plugins {
+		cpl, ok := pl.(plugin.PluginCommands)
+		if !ok {
+			continue
+		}
+		for name, cmd := range cpl.Commands() {
+			if _, dup := out[name]; dup {
+				return nil, fmt.Errorf("plugin %s: command %q provided by multiple plugins", pl.Name(), name)
+			}
+			out[name] = cmd
+		}
+	}
+	return out, nil
+}
+
+// Start runs the daemon plugins in load order. If one fails, plugins
+// started so far are closed again in reverse order.
+func (loader *PluginLoader) Start(node *core.IpfsNode) error {
+	for _, pl := range loader.plugins {
+		dpl, ok := pl.(plugin.PluginDaemon)
+		if !ok {
+			continue
+		}
+		if err := dpl.Start(node); err != nil {
+			loader.Close()
+			return fmt.Errorf("starting plugin %s: %s", pl.Name(), err)
+		}
+		loader.started = append(loader.started, dpl)
+	}
+	return nil
+}
+
+// Close stops started daemon plugins in reverse startup order.
+func (loader *PluginLoader) Close() error {
+	var errs []string
+	for i := len(loader.started) - 1; i >= 0; i-- {
+		if err := loader.started[i].Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	loader.started = nil
+	if len(errs) > 0 {
+		return fmt.Errorf("closing plugins: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// signature verification state for dynamically loaded plugins, set up
+// before any plugin file is opened
+var (
+	verifySignatures bool
+	trustedKeys      []ic.PubKey
+)
+
+func setupVerification(pcfg config.Plugins) error {
+	verifySignatures = pcfg.RequireSignatures
+	trustedKeys = nil
+	for _, enc := range pcfg.TrustedPublishers {
+		raw, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return fmt.Errorf("invalid trusted publisher key: %s", err)
+		}
+		pk, err := ic.UnmarshalPublicKey(raw)
+		if err != nil {
+			return fmt.Errorf("invalid trusted publisher key: %s", err)
+		}
+		trustedKeys = append(trustedKeys, pk)
+	}
+	if verifySignatures && len(trustedKeys) == 0 {
+		return fmt.Errorf("plugin signatures required but no trusted publishers configured")
+	}
+	return nil
+}
+
+// verifyPlugin checks the plugin file's detached signature (<path>.sig)
+// against the trusted publisher keys. Unsigned plugins are refused
+// unless verification is disabled.
+func verifyPlugin(path string) error {
+	if !verifySignatures {
+		return nil
+	}
+
+	sigEnc, err := ioutil.ReadFile(path + ".sig")
+	if os.IsNotExist(err) {
+		return fmt.Errorf("plugin %s is unsigned; refusing to load it", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigEnc)))
+	if err != nil {
+		return fmt.Errorf("plugin %s: malformed signature: %s", path, err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, pk := range trustedKeys {
+		if ok, err := pk.Verify(data, sig); err == nil && ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("plugin %s is not signed by a trusted publisher", path)
+}
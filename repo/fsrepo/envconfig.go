@@ -0,0 +1,110 @@
+package fsrepo
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+)
+
+// EnvConfigPrefix is the prefix of environment variables that override
+// config values for a daemon run, e.g. IPFS_CONFIG_ADDRESSES_API.
+// The rest of the variable name is the config path with '_' standing
+// in for '.'; values are parsed as JSON, with plain strings accepted
+// unquoted. Overrides are never written back to the config file.
+const EnvConfigPrefix = "IPFS_CONFIG_"
+
+// EnvConfigOverrides returns the config overrides present in the
+// environment, keyed by config path as spelled in the variable name
+// (upper-case, dot separated).
+func EnvConfigOverrides() map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, EnvConfigPrefix) {
+			continue
+		}
+		eq := strings.Index(kv, "=")
+		if eq < 0 {
+			continue
+		}
+		path := strings.Replace(kv[len(EnvConfigPrefix):eq], "_", ".", -1)
+		if path == "" {
+			continue
+		}
+
+		raw := kv[eq+1:]
+		var val interface{}
+		if err := json.Unmarshal([]byte(raw), &val); err != nil {
+			// not valid JSON, accept it as a bare string
+			val = raw
+		}
+		out[path] = val
+	}
+	return out
+}
+
+// MergeEnvConfig merges the environment overrides into a config map,
+// matching path segments case-insensitively so the upper-case
+// variable names line up with the camel-case config keys. It returns
+// the applied overrides keyed by their canonical config path.
+func MergeEnvConfig(conf map[string]interface{}) map[string]interface{} {
+	applied := map[string]interface{}{}
+	for path, val := range EnvConfigOverrides() {
+		canonical, ok := mergeConfigValue(conf, strings.Split(path, "."), val)
+		if !ok {
+			log.Warningf("ignoring environment override %s%s: path does not match the config layout",
+				EnvConfigPrefix, strings.Replace(path, ".", "_", -1))
+			continue
+		}
+		applied[canonical] = val
+	}
+	return applied
+}
+
+func mergeConfigValue(m map[string]interface{}, segs []string, val interface{}) (string, bool) {
+	name := segs[0]
+	for k := range m {
+		if strings.EqualFold(k, segs[0]) {
+			name = k
+			break
+		}
+	}
+
+	if len(segs) == 1 {
+		m[name] = val
+		return name, true
+	}
+
+	child, ok := m[name].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	rest, ok := mergeConfigValue(child, segs[1:], val)
+	if !ok {
+		return "", false
+	}
+	return name + "." + rest, true
+}
+
+// ApplyEnvConfigOverrides merges IPFS_CONFIG_* environment variables
+// over the given config in place, returning the applied overrides
+// keyed by canonical config path.
+func ApplyEnvConfigOverrides(cfg *config.Config) (map[string]interface{}, error) {
+	confMap, err := config.ToMap(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := MergeEnvConfig(confMap)
+	if len(applied) == 0 {
+		return nil, nil
+	}
+
+	merged, err := config.FromMap(confMap)
+	if err != nil {
+		return nil, err
+	}
+	*cfg = *merged
+	return applied, nil
+}
@@ -0,0 +1,52 @@
+package fsrepo
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMergeEnvConfig(t *testing.T) {
+	os.Setenv("IPFS_CONFIG_ADDRESSES_API", "/ip4/127.0.0.1/tcp/15001")
+	os.Setenv("IPFS_CONFIG_DATASTORE_STORAGEGCWATERMARK", "95")
+	os.Setenv("IPFS_CONFIG_NOSUCHSECTION_KEY", "x")
+	defer os.Unsetenv("IPFS_CONFIG_ADDRESSES_API")
+	defer os.Unsetenv("IPFS_CONFIG_DATASTORE_STORAGEGCWATERMARK")
+	defer os.Unsetenv("IPFS_CONFIG_NOSUCHSECTION_KEY")
+
+	conf := map[string]interface{}{
+		"Addresses": map[string]interface{}{
+			"API":     "/ip4/127.0.0.1/tcp/5001",
+			"Gateway": "/ip4/127.0.0.1/tcp/8080",
+		},
+		"Datastore": map[string]interface{}{
+			"StorageGCWatermark": 90,
+		},
+	}
+
+	applied := MergeEnvConfig(conf)
+
+	if len(applied) != 2 {
+		t.Fatalf("expected 2 applied overrides, got %d: %v", len(applied), applied)
+	}
+	if _, ok := applied["Addresses.API"]; !ok {
+		t.Errorf("override not reported under its canonical path: %v", applied)
+	}
+
+	addrs := conf["Addresses"].(map[string]interface{})
+	if addrs["API"] != "/ip4/127.0.0.1/tcp/15001" {
+		t.Errorf("Addresses.API not overridden, got %v", addrs["API"])
+	}
+	if addrs["Gateway"] != "/ip4/127.0.0.1/tcp/8080" {
+		t.Errorf("Addresses.Gateway should be untouched, got %v", addrs["Gateway"])
+	}
+
+	// numeric values come in as JSON
+	ds := conf["Datastore"].(map[string]interface{})
+	if ds["StorageGCWatermark"] != float64(95) {
+		t.Errorf("Datastore.StorageGCWatermark not overridden, got %v", ds["StorageGCWatermark"])
+	}
+
+	if _, ok := conf["NoSuchSection"]; ok {
+		t.Error("override with unknown section should be ignored")
+	}
+}
@@ -132,7 +132,18 @@ func open(repoPath string) (repo.Repo, error) {
 
 	r.lockfile, err = lockfile.Lock(r.path, LockFile)
 	if err != nil {
-		return nil, err
+		// a crashed process can leave the lock held by a dead PID;
+		// take it over instead of refusing to open
+		if lockfile.Stale(r.path, LockFile) {
+			log.Warningf("removing stale repo lock left by a dead process")
+			if cerr := lockfile.Clear(r.path, LockFile); cerr != nil {
+				return nil, err
+			}
+			r.lockfile, err = lockfile.Lock(r.path, LockFile)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 	keepLocked := false
 	defer func() {
@@ -384,13 +395,36 @@ func (r *FSRepo) openConfig() error {
 }
 
 func (r *FSRepo) openKeystore() error {
-	ksp := filepath.Join(r.path, "keystore")
-	ks, err := keystore.NewFSKeystore(ksp)
-	if err != nil {
-		return err
+	kcfg := r.config.Keystore
+
+	ksp := kcfg.Path
+	if ksp == "" {
+		ksp = "keystore"
+	}
+	if !filepath.IsAbs(ksp) {
+		ksp = filepath.Join(r.path, ksp)
 	}
 
-	r.keystore = ks
+	switch kcfg.Type {
+	case "", "fs":
+		ks, err := keystore.NewFSKeystore(ksp)
+		if err != nil {
+			return err
+		}
+		r.keystore = ks
+	case "encrypted-fs":
+		password, err := keystore.ReadPassword(kcfg.PasswordFile)
+		if err != nil {
+			return err
+		}
+		ks, err := keystore.NewEncryptedFSKeystore(ksp, password)
+		if err != nil {
+			return err
+		}
+		r.keystore = ks
+	default:
+		return fmt.Errorf("unknown keystore type: %s", kcfg.Type)
+	}
 
 	return nil
 }
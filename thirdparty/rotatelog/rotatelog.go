@@ -0,0 +1,102 @@
+// Package rotatelog provides a size-based rotating file writer for log
+// output. When the file exceeds the configured size it is renamed to
+// <path>.1 (shifting older backups up) and a fresh file is opened.
+package rotatelog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Writer is an io.Writer writing to a log file rotated by size.
+type Writer struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// New creates a rotating writer at path. The file is rotated once it
+// exceeds maxSizeMB megabytes, keeping up to maxBackups rotated files.
+func New(path string, maxSizeMB, maxBackups int) (*Writer, error) {
+	w := &Writer{
+		path:       path,
+		maxSize:    int64(maxSizeMB) << 20,
+		maxBackups: maxBackups,
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Writer) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.f = f
+	w.size = st.Size()
+	return nil
+}
+
+func (w *Writer) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSize > 0 && w.size+int64(len(b)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.f.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.f.Close()
+}
+
+// rotate shifts existing backups up by one and reopens a fresh file.
+// Callers must hold w.mu.
+func (w *Writer) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+
+	for i := w.maxBackups; i > 1; i-- {
+		os.Rename(w.backupPath(i-1), w.backupPath(i))
+	}
+	if w.maxBackups > 0 {
+		if err := os.Rename(w.path, w.backupPath(1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else {
+		if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return w.open()
+}
+
+func (w *Writer) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", w.path, n)
+}
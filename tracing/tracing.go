@@ -0,0 +1,147 @@
+// Package tracing wires the node's global opentracing tracer to an
+// external collector, exporting finished spans as JSON batches over
+// HTTP. Trace context from API requests is propagated into command
+// execution via the standard opentracing HTTP header format.
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	config "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-ipfs-config"
+	logging "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log"
+	loggabletracer "github.com/ipsn/go-ipfs/gxlibs/github.com/ipfs/go-log/tracer"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var log = logging.Logger("tracing")
+
+const (
+	// exportInterval is how often buffered spans are flushed to the
+	// collector
+	exportInterval = 5 * time.Second
+
+	// exportBatchSize flushes the buffer early once this many spans
+	// are waiting
+	exportBatchSize = 64
+
+	// spanBacklog bounds the number of unexported spans held in memory
+	spanBacklog = 1024
+)
+
+// span is the wire representation of a finished span
+type span struct {
+	TraceID           uint64                 `json:"traceId"`
+	SpanID            uint64                 `json:"spanId"`
+	ParentSpanID      uint64                 `json:"parentSpanId,omitempty"`
+	Name              string                 `json:"name"`
+	ServiceName       string                 `json:"serviceName"`
+	StartTimeUnixNano int64                  `json:"startTimeUnixNano"`
+	EndTimeUnixNano   int64                  `json:"endTimeUnixNano"`
+	Attributes        map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Exporter buffers finished spans and ships them to a collector
+// endpoint in batches
+type Exporter struct {
+	endpoint string
+	service  string
+
+	spans chan span
+}
+
+// Setup installs a global tracer exporting spans to the collector
+// configured in cfg. It is a no-op when tracing is disabled.
+func Setup(cfg config.Tracing) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Endpoint == "" {
+		return fmt.Errorf("tracing enabled but no endpoint configured")
+	}
+
+	service := cfg.ServiceName
+	if service == "" {
+		service = "go-ipfs"
+	}
+
+	exporter := &Exporter{
+		endpoint: cfg.Endpoint,
+		service:  service,
+
+		spans: make(chan span, spanBacklog),
+	}
+	go exporter.run()
+
+	opentracing.SetGlobalTracer(loggabletracer.New(exporter))
+	return nil
+}
+
+// RecordSpan implements loggabletracer.SpanRecorder
+func (e *Exporter) RecordSpan(rs loggabletracer.RawSpan) {
+	attrs := make(map[string]interface{}, len(rs.Tags))
+	for k, v := range rs.Tags {
+		attrs[k] = fmt.Sprint(v)
+	}
+
+	s := span{
+		TraceID:           rs.Context.TraceID,
+		SpanID:            rs.Context.SpanID,
+		ParentSpanID:      rs.ParentSpanID,
+		Name:              rs.Operation,
+		ServiceName:       e.service,
+		StartTimeUnixNano: rs.Start.UnixNano(),
+		EndTimeUnixNano:   rs.Start.Add(rs.Duration).UnixNano(),
+		Attributes:        attrs,
+	}
+
+	select {
+	case e.spans <- s:
+	default:
+		// exporter is falling behind, drop the span
+	}
+}
+
+func (e *Exporter) run() {
+	tick := time.NewTicker(exportInterval)
+	defer tick.Stop()
+
+	batch := make([]span, 0, exportBatchSize)
+	for {
+		select {
+		case s := <-e.spans:
+			batch = append(batch, s)
+			if len(batch) >= exportBatchSize {
+				e.export(batch)
+				batch = batch[:0]
+			}
+		case <-tick.C:
+			if len(batch) > 0 {
+				e.export(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (e *Exporter) export(batch []span) {
+	body, err := json.Marshal(map[string]interface{}{"spans": batch})
+	if err != nil {
+		log.Errorf("failed to encode span batch: %s", err)
+		return
+	}
+
+	resp, err := http.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Debugf("failed to export %d span(s): %s", len(batch), err)
+		return
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		log.Debugf("trace collector returned status %d", resp.StatusCode)
+	}
+}